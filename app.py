@@ -8,10 +8,18 @@ import sys
 import time
 import json
 import threading
+import uuid
 from datetime import datetime
-from flask import Flask, render_template, request, jsonify
+from flask import Flask, render_template, request, jsonify, send_from_directory
 from flask_socketio import SocketIO, emit, disconnect
+from flask_sock import Sock
+from simple_websocket import ConnectionClosed
+from werkzeug.utils import secure_filename
 import socket
+import struct
+import base64
+from collections import deque
+from urllib.parse import quote
 
 app = Flask(__name__)
 app.config['SECRET_KEY'] = os.environ.get('SECRET_KEY', 'screen-recorder-secret-key-2024')
@@ -26,14 +34,30 @@ socketio = SocketIO(
     ping_interval=25
 )
 
+# Go client'ların ham WebSocket bağlantıları (transport.mode = websocket)
+sock = Sock(app)
+
 # Global değişkenler
 connected_clients = {}  # clientId -> client_info
 latest_screens = {}     # clientId -> latest_screen_data
 viewers = {}           # socketId -> viewer_info
+pending_commands = {}  # clientId -> [command]
+commands_cond = threading.Condition()
+POLICY_DIR = os.environ.get('POLICY_DIR', 'policies')
+POLICY_KINDS = ('apps', 'websites')
+policy_lock = threading.Lock()
+SPOOLED_DIR = os.environ.get('SPOOLED_DIR', 'spooled_frames')
+SPOOLED_MAX_PER_CLIENT = int(os.environ.get('SPOOLED_MAX_PER_CLIENT', '2000'))
+spooled_lock = threading.Lock()
+recent_events = deque(maxlen=1000)  # son engelleme olayları
+seen_event_ids = set()
+events_lock = threading.Lock()
 stats = {
     'server_start_time': datetime.now(),
     'total_frames': 0,
-    'total_data_mb': 0.0
+    'total_data_mb': 0.0,
+    'spooled_frames': 0,
+    'blocker_events': 0
 }
 
 @app.route('/')
@@ -88,11 +112,53 @@ def api_stats():
         }
     })
 
+FRAME_CONTENT_TYPE = 'application/x-screenrecord-frame'
+
+@app.route('/api/capabilities')
+def api_capabilities():
+    """Client'ların Connect sırasında sorduğu desteklenen formatlar"""
+    return jsonify({'frame_formats': ['binary', 'json'], 'delta_frames': True})
+
+def parse_binary_frame(body):
+    """SRF1 binary karesini JSON formuna (data URL'ler ile) çevir"""
+    if body[:4] != b'SRF1':
+        raise ValueError('geçersiz kare başlığı')
+    offset = 4
+    
+    def read_chunk():
+        nonlocal offset
+        if offset + 4 > len(body):
+            raise ValueError('kare eksik')
+        (length,) = struct.unpack('>I', body[offset:offset + 4])
+        offset += 4
+        if offset + length > len(body):
+            raise ValueError('kare eksik')
+        chunk = body[offset:offset + length]
+        offset += length
+        return chunk
+    
+    def data_url(raw):
+        return 'data:image/jpeg;base64,' + base64.b64encode(raw).decode('ascii')
+    
+    data = json.loads(read_chunk())
+    if data.get('type') == 'screen_delta':
+        for tile in data.get('tiles') or []:
+            tile['image'] = data_url(read_chunk())
+    else:
+        data['image'] = data_url(read_chunk())
+    return data
+
 @app.route('/api/screen-update', methods=['POST'])
 def api_screen_update():
     """HTTP POST ile ekran güncellemesi (Go client uyumluluğu için)"""
     try:
-        data = request.get_json()
+        if request.mimetype == FRAME_CONTENT_TYPE:
+            try:
+                data = parse_binary_frame(request.get_data())
+            except ValueError as e:
+                return jsonify({'error': f'invalid frame: {e}'}), 400
+        else:
+            data = request.get_json()
         if not data:
             return jsonify({'error': 'JSON data required'}), 400
             
@@ -102,48 +168,365 @@ def api_screen_update():
         
         # Client bilgilerini güncelle/oluştur
         if client_id not in connected_clients:
-            connected_clients[client_id] = {
-                'id': client_id,
-                'socket_id': None,  # HTTP client, socket yok
-                'connected_at': datetime.now(),
-                'last_seen': datetime.now(),
-                'frames_sent': 0,
-                'user_agent': request.headers.get('User-Agent', 'Go HTTP Client'),
-                'connection_type': 'http'
-            }
-            print(f"📱 HTTP Client kaydedildi: {client_id}")
-        else:
-            connected_clients[client_id]['last_seen'] = datetime.now()
-            connected_clients[client_id]['frames_sent'] += 1
-        
-        # Ekran verisini sakla
-        latest_screens[client_id] = {
-            'clientId': client_id,
-            'image': data.get('image'),
-            'timestamp': data.get('timestamp', int(time.time())),
-            'type': 'screen_update'
-        }
-        
-        # İstatistikleri güncelle
-        stats['total_frames'] += 1
-        if data.get('image'):
-            # Base64 image size estimate
-            image_size_mb = len(data.get('image', '')) * 0.75 / (1024 * 1024)
-            stats['total_data_mb'] += image_size_mb
+            register_client(client_id, None, 'http', request.headers.get('User-Agent', 'Go HTTP Client'))
         
-        # Tüm web viewer'lara SocketIO ile gönder
-        socketio.emit('screen_update', latest_screens[client_id])
-        
-        # Her 50 frame'de log
-        if stats['total_frames'] % 50 == 0:
-            print(f"📺 HTTP Frame #{stats['total_frames']} işlendi. Client: {client_id}")
-        
-        return jsonify({'status': 'success', 'message': 'Screen update received'})
+        message = handle_client_frame(client_id, data)
+        return jsonify({'status': 'success', 'message': message})
         
     except Exception as e:
         print(f"⚠️ HTTP screen update hatası: {e}")
         return jsonify({'error': str(e)}), 500
 
+def register_client(client_id, socket_id, connection_type, user_agent):
+    """Client kaydını oluştur (HTTP'de ilk karede, WebSocket'te client_register ile)"""
+    connected_clients[client_id] = {
+        'id': client_id,
+        'socket_id': socket_id,  # HTTP ve ham WebSocket client'larında None
+        'connected_at': datetime.now(),
+        'last_seen': datetime.now(),
+        'frames_sent': 0,
+        'user_agent': user_agent,
+        'connection_type': connection_type
+    }
+    print(f"📱 {connection_type} client kaydedildi: {client_id}")
+
+def handle_client_frame(client_id, data):
+    """HTTP veya ham WebSocket üzerinden gelen kareyi işle ve viewer'lara ilet"""
+    if client_id in connected_clients:
+        connected_clients[client_id]['last_seen'] = datetime.now()
+        connected_clients[client_id]['frames_sent'] += 1
+    
+    # Delta karesi: sadece değişen karolar viewer'lara iletilir
+    if data.get('type') == 'screen_delta':
+        relay_screen_delta(client_id, data)
+        return 'Screen delta received'
+    
+    # Bağlantı kopukken diske biriktirilmiş eski kare: canlı görüntüyü ezmez,
+    # orijinal zaman damgasıyla arşive yazılır
+    if data.get('spooled'):
+        name = save_spooled_frame(client_id, data)
+        if name is None:
+            return 'Spooled frame ignored'
+        stats['spooled_frames'] += 1
+        socketio.emit('spooled_frame', {
+            'clientId': client_id,
+            'timestamp': data.get('timestamp', 0),
+            'name': name
+        })
+        return 'Spooled frame received'
+    
+    # Ekran verisini sakla
+    latest_screens[client_id] = {
+        'clientId': client_id,
+        'image': data.get('image'),
+        'timestamp': data.get('timestamp', int(time.time())),
+        'type': 'screen_update',
+        'seq': data.get('seq', 0),
+        'width': data.get('width'),
+        'height': data.get('height')
+    }
+    
+    # İstatistikleri güncelle
+    stats['total_frames'] += 1
+    if data.get('image'):
+        # Base64 image size estimate
+        image_size_mb = len(data.get('image', '')) * 0.75 / (1024 * 1024)
+        stats['total_data_mb'] += image_size_mb
+    
+    # Tüm web viewer'lara SocketIO ile gönder
+    socketio.emit('screen_update', latest_screens[client_id])
+    
+    # Her 50 frame'de log
+    if stats['total_frames'] % 50 == 0:
+        print(f"📺 Frame #{stats['total_frames']} işlendi. Client: {client_id}")
+    
+    return 'Screen update received'
+
+def spooled_client_dir(client_id):
+    return os.path.join(SPOOLED_DIR, secure_filename(client_id) or 'unknown')
+
+def save_spooled_frame(client_id, data):
+    """Biriktirilmiş kareyi orijinal zaman damgasıyla diske yaz, dosya adını döner"""
+    image = data.get('image') or ''
+    if ',' in image:
+        image = image.split(',', 1)[1]  # data URL öneki
+    try:
+        raw = base64.b64decode(image, validate=True)
+    except ValueError:
+        raw = b''
+    if not raw:
+        print(f"⚠️ Biriktirilmiş kare görüntüsüz: {client_id}")
+        return None
+    
+    timestamp = int(data.get('timestamp') or 0)
+    taken = datetime.fromtimestamp(timestamp)
+    name = f"{taken.strftime('%Y%m%d-%H%M%S')}-{int(data.get('seq') or 0)}.jpg"
+    directory = spooled_client_dir(client_id)
+    
+    with spooled_lock:
+        os.makedirs(directory, exist_ok=True)
+        path = os.path.join(directory, name)
+        with open(path, 'wb') as f:
+            f.write(raw)
+        os.utime(path, (timestamp, timestamp))
+        
+        # Client başına limit: en eski kareler silinir
+        files = sorted(os.listdir(directory))
+        for old in files[:max(len(files) - SPOOLED_MAX_PER_CLIENT, 0)]:
+            os.remove(os.path.join(directory, old))
+    
+    print(f"📼 Biriktirilmiş kare kaydedildi: {client_id} ({taken.strftime('%Y-%m-%d %H:%M:%S')})")
+    return name
+
+@app.route('/api/spooled/<client_id>')
+def api_spooled_frames(client_id):
+    """Client'ın çevrimdışıyken biriktirip sonradan gönderdiği kareler"""
+    directory = spooled_client_dir(client_id)
+    with spooled_lock:
+        try:
+            names = sorted(os.listdir(directory))
+        except FileNotFoundError:
+            names = []
+        frames = [{
+            'name': name,
+            'timestamp': int(os.path.getmtime(os.path.join(directory, name))),
+            'url': f'/api/spooled/{quote(client_id, safe="")}/{name}'
+        } for name in names]
+    return jsonify({'clientId': client_id, 'frames': frames})
+
+@app.route('/api/spooled/<client_id>/<name>')
+def api_spooled_frame(client_id, name):
+    """Biriktirilmiş tek bir kare (JPEG)"""
+    return send_from_directory(spooled_client_dir(client_id), name, mimetype='image/jpeg')
+
+@app.route('/api/commands', methods=['POST'])
+def api_enqueue_command():
+    """Client'a komut gönder (pause_capture, set_fps, snapshot, ...)"""
+    data = request.get_json() or {}
+    client_id = data.get('clientId')
+    command = data.get('command')
+    if not client_id or not command:
+        return jsonify({'error': 'clientId and command required'}), 400
+    
+    cmd = {
+        'id': uuid.uuid4().hex,
+        'command': command,
+        'params': data.get('params') or {}
+    }
+    with commands_cond:
+        pending_commands.setdefault(client_id, []).append(cmd)
+        commands_cond.notify_all()
+    
+    print(f"📤 Komut kuyruğa eklendi: {command} -> {client_id}")
+    return jsonify(cmd)
+
+@app.route('/api/commands', methods=['GET'])
+def api_poll_commands():
+    """Go client'ın long-poll ile komut çekmesi"""
+    client_id = request.args.get('clientId')
+    if not client_id:
+        return jsonify({'error': 'clientId required'}), 400
+    wait = min(max(request.args.get('wait', 0, type=int), 0), 30)
+    
+    deadline = time.time() + wait
+    with commands_cond:
+        while not pending_commands.get(client_id) and time.time() < deadline:
+            commands_cond.wait(timeout=deadline - time.time())
+        commands = pending_commands.pop(client_id, [])
+    
+    if not commands:
+        return '', 204
+    return jsonify({'commands': commands})
+
+@app.route('/api/command-result', methods=['POST'])
+def api_command_result():
+    """Client'tan gelen komut sonucunu viewer'lara ilet"""
+    data = request.get_json() or {}
+    if not data.get('id') or not data.get('clientId'):
+        return jsonify({'error': 'id and clientId required'}), 400
+    
+    relay_command_result(data)
+    return jsonify({'status': 'success'})
+
+def relay_command_result(data):
+    """Komut sonucunu viewer'lara ilet (HTTP veya WebSocket client'tan)"""
+    status = '✅' if data.get('success') else '❌'
+    print(f"{status} Komut sonucu: {data.get('command')} ({data.get('clientId')}) {data.get('error', '')}")
+    socketio.emit('command_result', data)
+
+def push_commands(ws, client_id, stop):
+    """Kuyruğa eklenen komutları WebSocket client'a gönder (long-poll karşılığı)"""
+    while not stop.is_set():
+        with commands_cond:
+            if not pending_commands.get(client_id):
+                commands_cond.wait(timeout=5)
+            commands = pending_commands.pop(client_id, [])
+        for cmd in commands:
+            try:
+                ws.send(json.dumps(dict(cmd, type='command')))
+            except ConnectionClosed:
+                # Gönderilemeyen komutlar sonraki bağlantıya kalır
+                with commands_cond:
+                    pending_commands.setdefault(client_id, []).insert(0, cmd)
+                return
+
+@sock.route('/ws')
+def client_websocket(ws):
+    """Go client'ın ham WebSocket bağlantısı: client_register, kareler (JSON veya SRF1) ve komutlar"""
+    client_id = None
+    stop = threading.Event()
+    try:
+        while True:
+            message = ws.receive()
+            if isinstance(message, bytes):
+                try:
+                    data = parse_binary_frame(message)
+                except ValueError as e:
+                    print(f"⚠️ Geçersiz WebSocket karesi ({client_id}): {e}")
+                    continue
+            else:
+                try:
+                    data = json.loads(message)
+                except (TypeError, ValueError):
+                    continue
+            
+            msg_type = data.get('type')
+            if msg_type == 'client_register':
+                client_id = data.get('client_id') or f'client_{int(time.time())}'
+                register_client(client_id, None, 'websocket', request.headers.get('User-Agent', 'Go WebSocket Client'))
+                formats = data.get('frame_formats') or []
+                ws.send(json.dumps({
+                    'type': 'register_ack',
+                    'frame_format': 'binary' if 'binary' in formats else 'json',
+                    'delta_frames': True
+                }))
+                threading.Thread(target=push_commands, args=(ws, client_id, stop), daemon=True).start()
+                emit_client_list()
+            elif msg_type in ('screen_update', 'screen_delta'):
+                frame_client = data.get('clientId') or client_id
+                if frame_client:
+                    handle_client_frame(frame_client, data)
+            elif msg_type == 'command_result':
+                relay_command_result(data)
+    except ConnectionClosed:
+        pass
+    finally:
+        stop.set()
+        if client_id:
+            print(f"📱 WebSocket client ayrıldı: {client_id}")
+            connected_clients.pop(client_id, None)
+            latest_screens.pop(client_id, None)
+            emit_client_list()
+
+@app.route('/api/events', methods=['POST'])
+def api_post_events():
+    """Client'lardan gelen engelleme olayları (toplu, tekrar gönderilebilir)"""
+    data = request.get_json(silent=True) or {}
+    client_id = data.get('clientId')
+    events = data.get('events')
+    if not client_id or not isinstance(events, list):
+        return jsonify({'error': 'clientId and events required'}), 400
+    
+    accepted = []
+    with events_lock:
+        for event in events:
+            if not isinstance(event, dict) or not event.get('id') or not event.get('type'):
+                continue
+            # Client bağlantı koparsa aynı paketi tekrar gönderir
+            if event['id'] in seen_event_ids:
+                continue
+            if len(recent_events) == recent_events.maxlen:
+                seen_event_ids.discard(recent_events[0]['id'])
+            recent_events.append(event)
+            seen_event_ids.add(event['id'])
+            accepted.append(event)
+        stats['blocker_events'] += len(accepted)
+    
+    for event in accepted:
+        print(f"🚨 Olay: {event['type']} ({client_id}) {event.get('app') or event.get('url') or event.get('reason') or ''}")
+        socketio.emit('blocker_event', event)
+    return jsonify({'accepted': len(accepted)})
+
+@app.route('/api/events', methods=['GET'])
+def api_get_events():
+    """Son engelleme olayları (isteğe bağlı clientId filtresi)"""
+    client_id = request.args.get('clientId')
+    with events_lock:
+        events = [e for e in recent_events if not client_id or e.get('clientId') == client_id]
+    return jsonify({'events': events})
+
+def policy_path(kind):
+    return os.path.join(POLICY_DIR, f'{kind}.json')
+
+def load_policy(kind):
+    try:
+        with open(policy_path(kind)) as f:
+            return json.load(f)
+    except FileNotFoundError:
+        return None
+
+def save_policy(kind, policy):
+    os.makedirs(POLICY_DIR, exist_ok=True)
+    tmp = policy_path(kind) + '.tmp'
+    with open(tmp, 'w') as f:
+        json.dump(policy, f, indent=2)
+    os.replace(tmp, policy_path(kind))
+
+@app.route('/api/policy/<kind>', methods=['GET'])
+def api_get_policy(kind):
+    """Client'ların çektiği versiyonlu engelleme politikası"""
+    if kind not in POLICY_KINDS:
+        return jsonify({'error': 'unknown policy kind'}), 404
+    with policy_lock:
+        policy = load_policy(kind)
+    if policy is None:
+        return jsonify({'error': 'no policy'}), 404
+    # Kaldırılan politika: client'lar 404'te son politikayı korur, 410'da yerel config'e döner
+    if policy.get('revoked'):
+        return jsonify({'error': 'policy revoked', 'version': policy['version']}), 410
+    
+    etag = f'"v{policy["version"]}"'
+    if request.headers.get('If-None-Match') == etag:
+        return '', 304
+    resp = jsonify(policy)
+    resp.headers['ETag'] = etag
+    return resp
+
+@app.route('/api/policy/<kind>', methods=['PUT'])
+def api_put_policy(kind):
+    """Yeni politika yayınla (blocked_apps.json / blocked_websites.json formatında)"""
+    if kind not in POLICY_KINDS:
+        return jsonify({'error': 'unknown policy kind'}), 404
+    config = request.get_json(silent=True)
+    if not isinstance(config, dict):
+        return jsonify({'error': 'JSON object required'}), 400
+    
+    with policy_lock:
+        current = load_policy(kind)
+        policy = {
+            'version': (current['version'] if current else 0) + 1,
+            'config': config
+        }
+        save_policy(kind, policy)
+    
+    print(f"📜 {kind} politikası yayınlandı (versiyon {policy['version']})")
+    return jsonify({'version': policy['version']})
+
+@app.route('/api/policy/<kind>', methods=['DELETE'])
+def api_delete_policy(kind):
+    """Politikayı kaldır, client'lar yerel config'e döner"""
+    if kind not in POLICY_KINDS:
+        return jsonify({'error': 'unknown policy kind'}), 404
+    with policy_lock:
+        current = load_policy(kind)
+        if current is None or current.get('revoked'):
+            return jsonify({'status': 'success'})
+        # Dosya silinmez: versiyon sırası korunur ve client'lar kaldırmayı 410 ile görür
+        save_policy(kind, {'version': current['version'] + 1, 'revoked': True})
+    
+    print(f"📜 {kind} politikası kaldırıldı")
+    return jsonify({'status': 'success'})
+
 # WebSocket Events
 @socketio.on('connect')
 def handle_connect():
@@ -220,12 +603,19 @@ def handle_screen_update(data):
         connected_clients[client_id]['last_seen'] = datetime.now()
         connected_clients[client_id]['frames_sent'] += 1
     
+    if data.get('type') == 'screen_delta':
+        relay_screen_delta(client_id, data)
+        return
+    
     # Ekran verisini sakla
     latest_screens[client_id] = {
         'clientId': client_id,
         'image': data.get('image'),
         'timestamp': data.get('timestamp', int(time.time())),
-        'type': 'screen_update'
+        'type': 'screen_update',
+        'seq': data.get('seq', 0),
+        'width': data.get('width'),
+        'height': data.get('height')
     }
     
     # İstatistikleri güncelle
@@ -242,6 +632,23 @@ def handle_screen_update(data):
     if stats['total_frames'] % 50 == 0:
         print(f"📺 Frame #{stats['total_frames']} işlendi. Clients: {len(connected_clients)}, Viewers: {len(viewers)}")
 
+def relay_screen_delta(client_id, data):
+    """Delta karesini viewer'lara ilet (son keyframe latest_screens'te kalır)"""
+    stats['total_frames'] += 1
+    tiles = data.get('tiles') or []
+    tiles_size = sum(len(tile.get('image', '')) for tile in tiles)
+    stats['total_data_mb'] += tiles_size * 0.75 / (1024 * 1024)
+    
+    socketio.emit('screen_delta', {
+        'clientId': client_id,
+        'timestamp': data.get('timestamp', int(time.time())),
+        'type': 'screen_delta',
+        'seq': data.get('seq', 0),
+        'width': data.get('width'),
+        'height': data.get('height'),
+        'tiles': tiles
+    })
+
 def emit_client_list():
     """Güncel client listesini tüm viewer'lara gönder"""
     socketio.emit('client_list', {
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+const (
+	taskAppBlocker     = "app_blocker"
+	taskWebsiteBlocker = "website_blocker"
+)
+
+// blockerRunner engelleyicilerin config'e göre başlatılıp durdurulmasını
+// sıralar. Config değiştiğinde (dosya, sunucu politikası veya komut ile)
+// *_enabled bayrağına göre engelleyici başlatılır veya durdurulur.
+type blockerRunner struct {
+	mu      sync.Mutex
+	started bool
+}
+
+// StartBlockers engelleyicileri mevcut config'e göre başlatır. Bundan sonraki
+// config değişiklikleri updateBlockers ile uygulanır.
+func (c *Client) StartBlockers() {
+	c.blockers.mu.Lock()
+	c.blockers.started = true
+	c.blockers.mu.Unlock()
+
+	c.updateBlockers()
+}
+
+// StopBlockers engelleyicileri durdurur ve hosts dosyasındaki engelleme bölümünü kaldırır.
+func (c *Client) StopBlockers() {
+	c.blockers.mu.Lock()
+	defer c.blockers.mu.Unlock()
+
+	c.blockers.started = false
+	c.super.Stop(taskAppBlocker)
+	if c.super.Stop(taskWebsiteBlocker) {
+		c.unblockWebsites()
+	}
+}
+
+// updateBlockers çalışan engelleyicileri config'teki enabled bayraklarıyla
+// eşitler.
+func (c *Client) updateBlockers() {
+	b := &c.blockers
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.started {
+		return
+	}
+
+	appCfg := c.appBlockerConfig()
+	appEnabled := appCfg != nil && appCfg.Settings.AppBlockerEnabled
+	switch {
+	case appEnabled && !c.super.Running(taskAppBlocker):
+		c.super.Start(taskAppBlocker, c.StartAppBlocker)
+	case !appEnabled && c.super.Stop(taskAppBlocker):
+		log.Println("⏹️ Uygulama engelleyici durduruldu")
+	}
+
+	webCfg := c.websiteBlockerConfig()
+	webEnabled := webCfg != nil && webCfg.Settings.WebsiteBlockerEnabled
+	switch {
+	case webEnabled && !c.super.Running(taskWebsiteBlocker):
+		c.super.Start(taskWebsiteBlocker, c.StartWebsiteBlocker)
+	case !webEnabled && c.super.Stop(taskWebsiteBlocker):
+		c.unblockWebsites()
+		log.Println("⏹️ Website engelleyici durduruldu")
+	}
+}
+
+// StartConfigWatcher yerel engelleme config dosyalarını izler ve değiştiklerinde
+// yeniden yükler.
+func (c *Client) StartConfigWatcher(ctx context.Context) {
+	files := []string{appBlockerConfigFile, websiteBlockerConfigFile}
+	watchConfigFiles(ctx, files, func(path string) {
+		log.Printf("🔄 %s değişti, yeniden yükleniyor...", path)
+		switch path {
+		case appBlockerConfigFile:
+			c.loadAppBlockerConfig()
+		case websiteBlockerConfigFile:
+			c.loadWebsiteBlockerConfig()
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Engellenen siteler redirect_to adresine yönlendirildiğinde tarayıcı bağlantı
+// hatası göstermesin diye client bu adreste küçük bir HTTP sunucusu çalıştırır.
+// Sayfa siteyi, engelleyen politikayı ve sitenin uyarı mesajını gösterir; her
+// ziyaret block_page_visited olayı olarak raporlanır. HTTPS bağlantıları
+// sertifika olmadan cevaplanamaz, tarayıcı bunlarda yine hata gösterir.
+
+const defaultBlockPagePort = 80
+
+var blockPageTemplate = template.Must(template.New("block").Parse(`<!DOCTYPE html>
+<html lang="tr">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Site engellendi</title>
+<style>
+body { font-family: sans-serif; background: #f4f4f5; color: #222; display: flex; justify-content: center; padding-top: 12vh; margin: 0; }
+main { background: #fff; border-radius: 8px; padding: 32px 40px; max-width: 520px; box-shadow: 0 2px 8px rgba(0,0,0,.1); }
+h1 { margin-top: 0; font-size: 1.5em; }
+.message { background: #fff4e5; border-left: 4px solid #f59e0b; padding: 12px 16px; }
+dl { display: grid; grid-template-columns: auto 1fr; gap: 6px 16px; color: #555; }
+dt { font-weight: bold; }
+dd { margin: 0; word-break: break-all; }
+</style>
+</head>
+<body>
+<main>
+<h1>🚫 Bu site engellendi</h1>
+{{if .Message}}<p class="message">{{.Message}}</p>{{end}}
+<dl>
+<dt>Adres</dt><dd>{{.Host}}</dd>
+{{if .Site}}<dt>Site</dt><dd>{{.Site}}</dd>{{end}}
+<dt>Politika</dt><dd>{{.Policy}}</dd>
+</dl>
+</main>
+</body>
+</html>
+`))
+
+type blockPageData struct {
+	Host    string
+	Site    string
+	Message string
+	Policy  string
+}
+
+// blockPageAddr sayfanın sunulacağı adres; sayfa kapalıysa veya yöntem
+// adresi yönlendirmiyorsa boş döner. redirect_to başka bir makineyi
+// gösteriyorsa sayfa o makinede sunulamaz, boş adresle birlikte neden döner.
+func (cfg *WebsiteBlockerConfig) blockPageAddr() (string, error) {
+	settings := cfg.Settings
+	if !settings.BlockPage || settings.BlockingMethod == "browser_check" {
+		return "", nil
+	}
+	ip := net.ParseIP(settings.RedirectTo)
+	if ip == nil {
+		return "", nil
+	}
+	switch {
+	case ip.IsUnspecified():
+		// 0.0.0.0'a yapılan bağlantılar yerel makineye gider; tüm ağdan
+		// erişilebilir olmaması için sadece loopback dinlenir
+		if ip.To4() != nil {
+			ip = net.IPv4(127, 0, 0, 1)
+		} else {
+			ip = net.IPv6loopback
+		}
+	case !ip.IsLoopback():
+		return "", fmt.Errorf("redirect_to (%s) yerel bir adres değil, engelleme sayfası başlatılmadı", settings.RedirectTo)
+	}
+	port := settings.BlockPagePort
+	if port == 0 {
+		port = defaultBlockPagePort
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(port)), nil
+}
+
+// blockedSite host'u engelleyen siteyi bulur; matcher cfg.blockedHosts()
+// ile oluşturulmuş olmalıdır.
+func (cfg *WebsiteBlockerConfig) blockedSite(matcher *hostMatcher, host string) (BlockedWebsite, bool) {
+	h, ok := matcher.match(host)
+	if !ok {
+		return BlockedWebsite{}, false
+	}
+	for _, site := range cfg.BlockedWebsites {
+		if site.Name == h.Site {
+			return site, true
+		}
+	}
+	return BlockedWebsite{Name: h.Site}, true
+}
+
+func (c *Client) websitePolicyName() string {
+	if policy := c.policies.get(policyKindWebsites); policy != nil {
+		return fmt.Sprintf("Sunucu politikası (versiyon %d)", policy.Version)
+	}
+	return "Yerel config (" + websiteBlockerConfigFile + ")"
+}
+
+func (c *Client) blockPageHandler() http.Handler {
+	throttle := newEventThrottle(time.Minute)
+	var (
+		rulesMu  sync.Mutex
+		rulesFor *WebsiteBlockerConfig
+		matcher  *hostMatcher
+	)
+	// rules güncel config'i ve eşleştiricisini döner; eşleştirici sadece
+	// config değiştiğinde yeniden oluşturulur
+	rules := func() (*WebsiteBlockerConfig, *hostMatcher) {
+		rulesMu.Lock()
+		defer rulesMu.Unlock()
+		if current := c.websiteBlockerConfig(); current != rulesFor {
+			rulesFor = current
+			matcher = nil
+			if current != nil {
+				matcher = newHostMatcher(current.blockedHosts())
+			}
+		}
+		return rulesFor, matcher
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.ToLower(r.Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.TrimSuffix(host, ".")
+
+		data := blockPageData{Host: host, Policy: c.websitePolicyName()}
+		if cfg, matcher := rules(); cfg != nil {
+			if site, ok := cfg.blockedSite(matcher, host); ok {
+				data.Site = site.Name
+				data.Message = site.WarningMessage
+
+				// Sayfa kaynakları (favicon vb.) ayrı olay üretmesin
+				if throttle.allow(host) {
+					log.Printf("🚫 Engelleme sayfası gösterildi: %s (%s)", host, site.Name)
+					c.emitEvent(BlockerEvent{Type: eventBlockPageVisited, Site: site.Name, URL: host + r.URL.RequestURI()})
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		// Engelleme kaldırıldığında tarayıcı eski sayfayı göstermemeli
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusForbidden)
+		if r.Method != http.MethodHead {
+			blockPageTemplate.Execute(w, data)
+		}
+	})
+}
+
+// runBlockPage engelleme sayfasını addr üzerinde ctx iptal edilene kadar sunar.
+// Port kullanılıyorsa veya yetki yoksa (80 root ister) belirli aralıklarla tekrar dener.
+func (c *Client) runBlockPage(ctx context.Context, addr string) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           c.blockPageHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	for {
+		ln, err := net.Listen("tcp", addr)
+		if err == nil {
+			log.Printf("📄 Engelleme sayfası başlatıldı: http://%s", addr)
+			err = server.Serve(ln)
+			if errors.Is(err, http.ErrServerClosed) {
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("⚠️ Engelleme sayfası %s adresinde açılamadı: %v", addr, err)
+		if !sleepCtx(ctx, 30*time.Second) {
+			return
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlockPageAddr(t *testing.T) {
+	tests := []struct {
+		method   string
+		redirect string
+		port     int
+		addr     string
+		wantErr  bool
+	}{
+		{redirect: "127.0.0.1", addr: "127.0.0.1:80"},
+		{redirect: "0.0.0.0", port: 8080, addr: "127.0.0.1:8080"},
+		{redirect: "::", addr: "[::1]:80"},
+		{redirect: "::1", addr: "[::1]:80"},
+		{method: "browser_check", redirect: "127.0.0.1"},
+		{redirect: ""},
+		// Başka bir makine sayfayı sunar, client dinlemez
+		{redirect: "10.0.0.5", wantErr: true},
+		{redirect: "2001:db8::1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cfg := &WebsiteBlockerConfig{}
+		cfg.Settings.BlockPage = true
+		cfg.Settings.BlockingMethod = tt.method
+		cfg.Settings.RedirectTo = tt.redirect
+		cfg.Settings.BlockPagePort = tt.port
+
+		addr, err := cfg.blockPageAddr()
+		if addr != tt.addr || (err != nil) != tt.wantErr {
+			t.Errorf("%q (%s): %q, %v; beklenen %q, hata %v", tt.redirect, tt.method, addr, err, tt.addr, tt.wantErr)
+		}
+	}
+}
+
+func testBlockPageClient(t *testing.T, cfg *WebsiteBlockerConfig) *Client {
+	t.Helper()
+	c := &Client{
+		clientID: "c1",
+		policies: &policyState{policies: make(map[string]*cachedPolicy)},
+		events:   openEventQueue(EventSettings{QueueFile: filepath.Join(t.TempDir(), "events.json"), MaxQueued: 100, BatchSize: 100}),
+		now:      time.Now,
+	}
+	c.setWebsiteBlockerConfig(cfg)
+	return c
+}
+
+func TestBlockPageHandler(t *testing.T) {
+	cfg := &WebsiteBlockerConfig{BlockedWebsites: []BlockedWebsite{
+		{Name: "YouTube", URLs: []string{"youtube.com"}, WarningMessage: "Video <siteleri> yasak"},
+	}}
+	c := testBlockPageClient(t, cfg)
+	handler := c.blockPageHandler()
+
+	tests := []struct {
+		name   string
+		method string
+		host   string
+		target string
+		body   []string // sayfada bulunması gerekenler
+		absent []string
+		event  string // beklenen block_page_visited URL'si, boşsa olay yok
+	}{
+		{
+			name:   "engelli site",
+			host:   "WWW.YouTube.com:80",
+			target: "/watch?v=1",
+			body:   []string{"<dd>www.youtube.com</dd>", "<dd>YouTube</dd>", "Video &lt;siteleri&gt; yasak", "Yerel config"},
+			event:  "www.youtube.com/watch?v=1",
+		},
+		{
+			// Sayfa kaynakları aynı dakikada yeni olay üretmez
+			name:   "tekrar ziyaret",
+			host:   "www.youtube.com",
+			target: "/favicon.ico",
+			body:   []string{"<dd>YouTube</dd>"},
+		},
+		{
+			name:   "engelli olmayan adres",
+			host:   "example.com",
+			target: "/",
+			body:   []string{"<dd>example.com</dd>"},
+			absent: []string{"<dt>Site</dt>", "class=\"message\""},
+		},
+		{
+			name:   "HEAD",
+			method: http.MethodHead,
+			host:   "m.youtube.com.",
+			target: "/",
+			event:  "m.youtube.com/",
+		},
+	}
+
+	for _, tt := range tests {
+		method := tt.method
+		if method == "" {
+			method = http.MethodGet
+		}
+		req := httptest.NewRequest(method, "http://placeholder"+tt.target, nil)
+		req.Host = tt.host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: durum kodu %d, beklenen 403", tt.name, rec.Code)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("%s: Cache-Control %q", tt.name, got)
+		}
+		if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+			t.Errorf("%s: Content-Type %q", tt.name, got)
+		}
+		body := rec.Body.String()
+		if method == http.MethodHead && body != "" {
+			t.Errorf("%s: HEAD cevabında gövde olmamalı", tt.name)
+		}
+		for _, want := range tt.body {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: sayfada %q yok:\n%s", tt.name, want, body)
+			}
+		}
+		for _, unwanted := range tt.absent {
+			if strings.Contains(body, unwanted) {
+				t.Errorf("%s: sayfada %q olmamalı", tt.name, unwanted)
+			}
+		}
+
+		events := c.events.peek()
+		c.events.remove(events)
+		switch {
+		case tt.event == "" && len(events) != 0:
+			t.Errorf("%s: beklenmeyen olaylar %+v", tt.name, events)
+		case tt.event != "" && (len(events) != 1 || events[0].Type != eventBlockPageVisited ||
+			events[0].URL != tt.event || events[0].Site != "YouTube" || events[0].ClientID != "c1"):
+			t.Errorf("%s: olaylar %+v, beklenen %s olayı", tt.name, events, tt.event)
+		}
+	}
+
+	// Config değişince eşleştirici yeniden oluşturulur
+	c.setWebsiteBlockerConfig(&WebsiteBlockerConfig{BlockedWebsites: []BlockedWebsite{
+		{Name: "Example", URLs: []string{"example.com"}},
+	}})
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), "<dd>Example</dd>") {
+		t.Errorf("yeni config'teki site sayfada yok:\n%s", rec.Body.String())
+	}
+	req = httptest.NewRequest(http.MethodGet, "http://youtube.com/", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if strings.Contains(rec.Body.String(), "<dt>Site</dt>") {
+		t.Error("config'ten çıkarılan site hâlâ eşleşiyor")
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"log"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Capturer ekran görüntüsü alan backend'leri soyutlar.
+type Capturer interface {
+	Name() string
+	Capture() (image.Image, error)
+	Close() error
+}
+
+func newCapturer(settings CaptureSettings) (Capturer, error) {
+	switch settings.Backend {
+	case "x11":
+		return newX11Capturer(settings)
+	case "command":
+		return newCommandCapturer()
+	case "auto":
+		if runtime.GOOS == "linux" {
+			capturer, err := newX11Capturer(settings)
+			if err == nil {
+				return capturer, nil
+			}
+			log.Printf("⚠️ X11 yakalama kullanılamıyor, komut tabanlı yakalamaya geçiliyor: %v", err)
+		}
+		return newCommandCapturer()
+	default:
+		return nil, fmt.Errorf("bilinmeyen yakalama backend'i: %s", settings.Backend)
+	}
+}
+
+// commandCapturer platformun ekran görüntüsü aracını çalıştırır
+// (screencapture, gnome-screenshot/scrot, PowerShell).
+type commandCapturer struct{}
+
+func newCommandCapturer() (Capturer, error) {
+	switch runtime.GOOS {
+	case "darwin", "linux", "windows":
+		return &commandCapturer{}, nil
+	default:
+		return nil, fmt.Errorf("desteklenmeyen platform: %s", runtime.GOOS)
+	}
+}
+
+func (cc *commandCapturer) Name() string {
+	return "command"
+}
+
+func (cc *commandCapturer) Close() error {
+	return nil
+}
+
+func (cc *commandCapturer) Capture() (image.Image, error) {
+	switch runtime.GOOS {
+	case "darwin": // macOS
+		return cc.captureMacOS()
+	case "linux":
+		return cc.captureLinux()
+	case "windows":
+		return cc.captureWindows()
+	default:
+		return nil, fmt.Errorf("desteklenmeyen platform: %s", runtime.GOOS)
+	}
+}
+
+func (cc *commandCapturer) captureMacOS() (image.Image, error) {
+	tmpFile := "/tmp/screenshot_" + fmt.Sprintf("%d", time.Now().UnixNano()) + ".png"
+
+	cmd := exec.Command("screencapture", "-x", "-t", "png", tmpFile)
+	err := cmd.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := exec.Command("cat", tmpFile).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	exec.Command("rm", tmpFile).Run()
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+func (cc *commandCapturer) captureLinux() (image.Image, error) {
+	cmd := exec.Command("gnome-screenshot", "-f", "/dev/stdout")
+	output, err := cmd.Output()
+	if err != nil {
+		cmd = exec.Command("scrot", "-o", "/dev/stdout")
+		output, err = cmd.Output()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(output))
+	return img, err
+}
+
+func (cc *commandCapturer) captureWindows() (image.Image, error) {
+	powershellScript := `
+	Add-Type -AssemblyName System.Windows.Forms
+	Add-Type -AssemblyName System.Drawing
+	$Screen = [System.Windows.Forms.SystemInformation]::VirtualScreen
+	$bitmap = New-Object System.Drawing.Bitmap $Screen.Width, $Screen.Height
+	$graphics = [System.Drawing.Graphics]::FromImage($bitmap)
+	$graphics.CopyFromScreen($Screen.Left, $Screen.Top, 0, 0, $bitmap.Size)
+	$stream = New-Object System.IO.MemoryStream
+	$bitmap.Save($stream, [System.Drawing.Imaging.ImageFormat]::Png)
+	[Convert]::ToBase64String($stream.ToArray())
+	`
+
+	cmd := exec.Command("powershell", "-Command", powershellScript)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+)
+
+// x11Capturer kök pencereyi doğrudan X protokolü üzerinden okur
+// (mümkünse MIT-SHM, değilse GetImage).
+type x11Capturer struct {
+	x         *x11Conn
+	useSHM    bool
+	shm       *x11Shm
+	shmWarned bool
+}
+
+func newX11Capturer(settings CaptureSettings) (Capturer, error) {
+	x, err := dialX11(settings.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	screen := x.defaultScreen()
+	log.Printf("🖥️ X11 ekranı bağlandı: %dx%d, derinlik %d", screen.width, screen.height, screen.rootDepth)
+
+	xc := &x11Capturer{x: x, useSHM: settings.shmEnabled()}
+	// MIT-SHM baştan denenir ki Name() ilk karede değil açılışta doğru backend'i göstersin
+	if xc.useSHM {
+		xc.trySHM(int(screen.width) * int(screen.height) * 4)
+	}
+	return xc, nil
+}
+
+func (xc *x11Capturer) Name() string {
+	if xc.shm != nil {
+		return "x11-shm"
+	}
+	return "x11"
+}
+
+func (xc *x11Capturer) Close() error {
+	if xc.shm != nil {
+		xc.shm.Close()
+		xc.shm = nil
+	}
+	return xc.x.Close()
+}
+
+func (xc *x11Capturer) Capture() (image.Image, error) {
+	screen := xc.x.defaultScreen()
+
+	// Çözünürlük xrandr ile değişmiş olabilir, her karede güncel boyutu al
+	geom, err := xc.x.getGeometry(screen.root)
+	if err != nil {
+		return nil, err
+	}
+	width, height := geom.width, geom.height
+
+	var (
+		depth  byte
+		visual uint32
+		data   []byte
+	)
+
+	if xc.useSHM {
+		xc.trySHM(int(width) * int(height) * 4)
+	}
+
+	if xc.shm != nil {
+		depth, visual, data, err = xc.shm.getImage(screen.root, 0, 0, width, height)
+	} else {
+		depth, visual, data, err = xc.x.getImage(screen.root, 0, 0, width, height)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return xc.x.toRGBA(data, int(width), int(height), depth, visual)
+}
+
+// trySHM paylaşımlı belleği en az size byte olacak şekilde hazırlar; olmazsa
+// bir kez uyarıp GetImage'a geçer.
+func (xc *x11Capturer) trySHM(size int) {
+	if err := xc.ensureShm(size); err != nil {
+		if !xc.shmWarned {
+			log.Printf("⚠️ MIT-SHM kullanılamıyor, GetImage ile devam ediliyor: %v", err)
+			xc.shmWarned = true
+		}
+		xc.useSHM = false
+	}
+}
+
+func (xc *x11Capturer) ensureShm(size int) error {
+	if xc.shm != nil && xc.shm.size() >= size {
+		return nil
+	}
+	if xc.shm != nil {
+		xc.shm.Close()
+		xc.shm = nil
+	}
+	shm, err := xc.x.newShm(size)
+	if err != nil {
+		return err
+	}
+	xc.shm = shm
+	return nil
+}
+
+// toRGBA ZPixmap verisini sunucunun piksel formatına göre RGBA'ya çevirir.
+func (x *x11Conn) toRGBA(data []byte, width, height int, depth byte, visualID uint32) (*image.RGBA, error) {
+	format, ok := x.formats[depth]
+	if !ok {
+		return nil, fmt.Errorf("X11 derinliği için piksel formatı yok: %d", depth)
+	}
+	vis, ok := x.defaultScreen().visuals[visualID]
+	if !ok {
+		return nil, fmt.Errorf("X11 visual bulunamadı: 0x%x", visualID)
+	}
+
+	bpp := int(format.bitsPerPixel)
+	if bpp != 16 && bpp != 24 && bpp != 32 {
+		return nil, fmt.Errorf("desteklenmeyen bit/piksel: %d", bpp)
+	}
+	pad := int(format.scanlinePad)
+	stride := ((width*bpp + pad - 1) / pad) * pad / 8
+	if len(data) < stride*height {
+		return nil, fmt.Errorf("X11 görüntü verisi eksik: %d < %d", len(data), stride*height)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bytesPP := bpp / 8
+	lsbFirst := x.imageByteOrder == 0
+
+	// Yaygın durum: 32 bit BGRX, bayt bayt kopyala
+	if bpp == 32 && lsbFirst && vis.redMask == 0xff0000 && vis.greenMask == 0xff00 && vis.blueMask == 0xff {
+		for y := 0; y < height; y++ {
+			src := data[y*stride : y*stride+width*4]
+			dst := img.Pix[y*img.Stride : y*img.Stride+width*4]
+			for i := 0; i < len(src); i += 4 {
+				dst[i] = src[i+2]
+				dst[i+1] = src[i+1]
+				dst[i+2] = src[i]
+				dst[i+3] = 0xff
+			}
+		}
+		return img, nil
+	}
+
+	rShift, rMax := maskShift(vis.redMask)
+	gShift, gMax := maskShift(vis.greenMask)
+	bShift, bMax := maskShift(vis.blueMask)
+
+	for y := 0; y < height; y++ {
+		row := data[y*stride:]
+		dst := img.Pix[y*img.Stride:]
+		for px := 0; px < width; px++ {
+			p := row[px*bytesPP : px*bytesPP+bytesPP]
+			var v uint32
+			for i := 0; i < bytesPP; i++ {
+				if lsbFirst {
+					v |= uint32(p[i]) << (8 * uint(i))
+				} else {
+					v = v<<8 | uint32(p[i])
+				}
+			}
+			dst[px*4] = scaleChannel((v&vis.redMask)>>rShift, rMax)
+			dst[px*4+1] = scaleChannel((v&vis.greenMask)>>gShift, gMax)
+			dst[px*4+2] = scaleChannel((v&vis.blueMask)>>bShift, bMax)
+			dst[px*4+3] = 0xff
+		}
+	}
+	return img, nil
+}
+
+func maskShift(mask uint32) (shift uint32, maxVal uint32) {
+	if mask == 0 {
+		return 0, 0
+	}
+	for mask&1 == 0 {
+		mask >>= 1
+		shift++
+	}
+	return shift, mask
+}
+
+func scaleChannel(v, maxVal uint32) uint8 {
+	if maxVal == 0 {
+		return 0
+	}
+	return uint8(v * 255 / maxVal)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	x11OpCreateGC          = 55
+	x11OpPolyFillRectangle = 70
+	x11GCForeground        = 1 << 2
+)
+
+// startXvfb boş bir display'de Xvfb başlatır; Xvfb kurulu değilse test atlanır.
+func startXvfb(t *testing.T, width, height int) string {
+	t.Helper()
+	path, err := exec.LookPath("Xvfb")
+	if err != nil {
+		t.Skip("Xvfb kurulu değil")
+	}
+
+	// -displayfd ile Xvfb boş bir display seçer ve numarasını fd 3'e yazar
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	screen := fmt.Sprintf("%dx%dx24", width, height)
+	cmd := exec.Command(path, "-displayfd", "3", "-screen", "0", screen, "-nolisten", "tcp")
+	cmd.ExtraFiles = []*os.File{w}
+	if err := cmd.Start(); err != nil {
+		w.Close()
+		t.Fatal(err)
+	}
+	w.Close()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	line := make(chan string, 1)
+	go func() {
+		s, _ := bufio.NewReader(r).ReadString('\n')
+		line <- strings.TrimSpace(s)
+	}()
+	select {
+	case n := <-line:
+		if n == "" {
+			t.Fatal("Xvfb display numarası vermedi")
+		}
+		return ":" + n
+	case <-time.After(10 * time.Second):
+		t.Fatal("Xvfb açılmadı")
+	}
+	return ""
+}
+
+// fillRect kök pencereye verilen renkte bir dikdörtgen çizer.
+func fillRect(t *testing.T, x *x11Conn, r image.Rectangle, c color.RGBA) {
+	t.Helper()
+	screen := x.defaultScreen()
+	vis := screen.visuals[screen.rootVisual]
+	pixel := func(v uint8, mask uint32) uint32 {
+		shift, maxVal := maskShift(mask)
+		return (uint32(v) * maxVal / 255) << shift
+	}
+
+	gc := x.newID()
+	body := make([]byte, 16)
+	x11Order.PutUint32(body[0:], gc)
+	x11Order.PutUint32(body[4:], screen.root)
+	x11Order.PutUint32(body[8:], x11GCForeground)
+	x11Order.PutUint32(body[12:], pixel(c.R, vis.redMask)|pixel(c.G, vis.greenMask)|pixel(c.B, vis.blueMask))
+	if err := x.sendVoid(x11Request(x11OpCreateGC, 0, body), nil); err != nil {
+		t.Fatalf("CreateGC: %v", err)
+	}
+
+	body = make([]byte, 16)
+	x11Order.PutUint32(body[0:], screen.root)
+	x11Order.PutUint32(body[4:], gc)
+	x11Order.PutUint16(body[8:], uint16(r.Min.X))
+	x11Order.PutUint16(body[10:], uint16(r.Min.Y))
+	x11Order.PutUint16(body[12:], uint16(r.Dx()))
+	x11Order.PutUint16(body[14:], uint16(r.Dy()))
+	if err := x.sendVoid(x11Request(x11OpPolyFillRectangle, 0, body), nil); err != nil {
+		t.Fatalf("PolyFillRectangle: %v", err)
+	}
+}
+
+func TestX11CapturerXvfb(t *testing.T) {
+	const width, height = 64, 48
+	display := startXvfb(t, width, height)
+
+	x, err := dialX11(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer x.Close()
+
+	quadrants := []struct {
+		rect  image.Rectangle
+		color color.RGBA
+	}{
+		{image.Rect(0, 0, 32, 24), color.RGBA{255, 0, 0, 255}},
+		{image.Rect(32, 0, 64, 24), color.RGBA{0, 255, 0, 255}},
+		{image.Rect(0, 24, 32, 48), color.RGBA{0, 0, 255, 255}},
+		{image.Rect(32, 24, 64, 48), color.RGBA{255, 255, 255, 255}},
+	}
+	for _, q := range quadrants {
+		fillRect(t, x, q.rect, q.color)
+	}
+
+	noSHM := false
+	for _, tc := range []struct {
+		name    string
+		useSHM  *bool
+		backend string
+	}{
+		{"GetImage", &noSHM, "x11"},
+		{"MIT-SHM", nil, "x11-shm"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			capturer, err := newX11Capturer(CaptureSettings{Display: display, UseSHM: tc.useSHM})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer capturer.Close()
+
+			// SHM açılışta denendiği için ilk kareden önce doğru backend görünmeli
+			if got := capturer.Name(); got != tc.backend {
+				t.Fatalf("Name() = %q, beklenen %q", got, tc.backend)
+			}
+
+			img, err := capturer.Capture()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.Bounds(); got != image.Rect(0, 0, width, height) {
+				t.Fatalf("boyut %v, beklenen %dx%d", got, width, height)
+			}
+			for _, q := range quadrants {
+				for _, p := range []image.Point{q.rect.Min, q.rect.Max.Sub(image.Pt(1, 1))} {
+					if got := color.RGBAModel.Convert(img.At(p.X, p.Y)); got != q.color {
+						t.Errorf("%v pikseli %v, beklenen %v", p, got, q.color)
+					}
+				}
+			}
+			if got := capturer.Name(); got != tc.backend {
+				t.Errorf("yakalamadan sonra Name() = %q, beklenen %q", got, tc.backend)
+			}
+		})
+	}
+}
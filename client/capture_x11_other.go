@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+func newX11Capturer(settings CaptureSettings) (Capturer, error) {
+	return nil, errors.New("X11 yakalama sadece Linux'ta destekleniyor")
+}
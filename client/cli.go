@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Alt komutlar: "client <komut> [argümanlar]". İlk argüman bir alt komut
+// değilse sunucu adresi olarak yorumlanır.
+var subcommands = map[string]func(args []string) int{
+	"validate-config": runValidateConfig,
+	"status":          runStatus,
+	"consent":         runConsent,
+	"pause":           runPause,
+	"resume":          runResume,
+	"unblock":         runUnblock,
+}
+
+func runSubcommand(name string, args []string) (int, bool) {
+	cmd, ok := subcommands[name]
+	if !ok {
+		return 0, false
+	}
+	return cmd(args), true
+}
+
+// runValidateConfig engelleme config dosyalarını doğrular; hata varsa 1 döner.
+func runValidateConfig(args []string) int {
+	fs := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	appsFile := fs.String("apps", appBlockerConfigFile, "uygulama engelleme config dosyası")
+	websitesFile := fs.String("websites", websiteBlockerConfigFile, "website engelleme config dosyası")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	checks := []struct {
+		flag  string
+		path  string
+		parse func([]byte) ValidationErrors
+	}{
+		{"apps", *appsFile, func(data []byte) ValidationErrors {
+			_, errs := parseAppBlockerConfig(data)
+			return errs
+		}},
+		{"websites", *websitesFile, func(data []byte) ValidationErrors {
+			_, errs := parseWebsiteBlockerConfig(data)
+			return errs
+		}},
+	}
+
+	failed := false
+	for _, check := range checks {
+		data, err := os.ReadFile(check.path)
+		if err != nil {
+			// Varsayılan dosyanın olmaması hata değil
+			if os.IsNotExist(err) && !explicit[check.flag] {
+				fmt.Printf("⏭️  %s bulunamadı, atlandı\n", check.path)
+				continue
+			}
+			fmt.Printf("❌ %s okunamadı: %v\n", check.path, err)
+			failed = true
+			continue
+		}
+
+		errs := check.parse(data)
+		if len(errs) == 0 {
+			fmt.Printf("✅ %s geçerli\n", check.path)
+			continue
+		}
+
+		if errs.HasErrors() {
+			fmt.Printf("❌ %s geçersiz:\n", check.path)
+			failed = true
+		} else {
+			fmt.Printf("✅ %s geçerli (uyarılarla):\n", check.path)
+		}
+		for _, e := range errs {
+			mark := "❌"
+			if e.Warning {
+				mark = "⚠️ "
+			}
+			fmt.Printf("   %s %s\n", mark, e)
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Sunucunun client'a gönderebileceği komutlar. WebSocket modunda komutlar
+// okuma döngüsünden, HTTP modunda long-poll ile /api/commands'tan gelir.
+// Her komut clientId ile ilişkilendirilmiş bir command_result ile yanıtlanır.
+
+const (
+	cmdPauseCapture   = "pause_capture"
+	cmdResumeCapture  = "resume_capture"
+	cmdSetFPS         = "set_fps"
+	cmdSetQuality     = "set_quality"
+	cmdReloadBlockers = "reload_blockers"
+	cmdSnapshot       = "snapshot"
+
+	commandPollWait = 25 * time.Second
+)
+
+type ServerCommand struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+	Params  struct {
+		FPS     int `json:"fps"`
+		Quality int `json:"quality"`
+	} `json:"params"`
+}
+
+type CommandResult struct {
+	Type      string      `json:"type"`
+	ID        string      `json:"id"`
+	ClientID  string      `json:"clientId"`
+	Command   string      `json:"command"`
+	Success   bool        `json:"success"`
+	Error     string      `json:"error,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp int64       `json:"timestamp"`
+}
+
+// StartCommandChannel komut işleyicisini ve (HTTP modunda) long-poll
+// döngüsünü başlatır.
+func (c *Client) StartCommandChannel() {
+	c.super.Start("command_worker", c.runCommandWorker)
+	if c.useHTTP {
+		c.super.Start("command_poll", c.pollCommands)
+	}
+}
+
+// enqueueCommand okuma döngüsünü bloklamadan komutu işleyiciye iletir.
+func (c *Client) enqueueCommand(cmd ServerCommand) {
+	select {
+	case c.commands <- cmd:
+	default:
+		log.Printf("⚠️ Komut kuyruğu dolu, komut reddedildi: %s", cmd.Command)
+		c.sendCommandResult(cmd, nil, errors.New("komut kuyruğu dolu"))
+	}
+}
+
+func (c *Client) runCommandWorker(ctx context.Context) {
+	for {
+		var cmd ServerCommand
+		select {
+		case <-ctx.Done():
+			return
+		case cmd = <-c.commands:
+		}
+
+		log.Printf("📥 Sunucu komutu: %s (%s)", cmd.Command, cmd.ID)
+		data, err := c.executeCommand(cmd)
+		if err != nil {
+			log.Printf("⚠️ Komut başarısız: %s: %v", cmd.Command, err)
+		}
+		c.sendCommandResult(cmd, data, err)
+	}
+}
+
+func (c *Client) executeCommand(cmd ServerCommand) (interface{}, error) {
+	switch cmd.Command {
+	case cmdPauseCapture:
+		c.capturePaused.Store(true)
+		log.Println("⏸️ Ekran yakalama sunucu tarafından duraklatıldı")
+		return nil, nil
+
+	case cmdResumeCapture:
+		c.capturePaused.Store(false)
+		c.encoder.RequestKeyframe()
+		log.Println("▶️ Ekran yakalama sunucu tarafından devam ettirildi")
+		return nil, nil
+
+	case cmdSetFPS:
+		fps := cmd.Params.FPS
+		if fps < 1 || fps > 60 {
+			return nil, fmt.Errorf("geçersiz FPS: %d (1-60)", fps)
+		}
+		c.setFPS(fps)
+		log.Printf("🎞️ FPS değiştirildi: %d", fps)
+		return map[string]int{"fps": fps}, nil
+
+	case cmdSetQuality:
+		quality := cmd.Params.Quality
+		if quality < 1 || quality > 100 {
+			return nil, fmt.Errorf("geçersiz kalite: %d (1-100)", quality)
+		}
+		c.encoder.SetQuality(quality)
+		c.encoder.RequestKeyframe()
+		log.Printf("🖼️ JPEG kalitesi değiştirildi: %d", quality)
+		return map[string]int{"quality": quality}, nil
+
+	case cmdReloadBlockers:
+		return c.reloadBlockerConfigs(), nil
+
+	case cmdSnapshot:
+		return c.takeSnapshot()
+
+	default:
+		return nil, fmt.Errorf("bilinmeyen komut: %s", cmd.Command)
+	}
+}
+
+// takeSnapshot küçültülmemiş tek bir ekran görüntüsü alır.
+func (c *Client) takeSnapshot() (interface{}, error) {
+	if !c.consentGranted.Load() {
+		return nil, errConsentRequired
+	}
+	if c.privacyPaused.Load() {
+		return nil, errPrivacyPaused
+	}
+	img, err := c.takeScreenshot()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := encodeJPEGDataURL(img, 90)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"image":  encoded,
+		"width":  img.Bounds().Dx(),
+		"height": img.Bounds().Dy(),
+	}, nil
+}
+
+func (c *Client) sendCommandResult(cmd ServerCommand, data interface{}, err error) {
+	result := CommandResult{
+		Type:      "command_result",
+		ID:        cmd.ID,
+		ClientID:  c.clientID,
+		Command:   cmd.Command,
+		Success:   err == nil,
+		Data:      data,
+		Timestamp: time.Now().Unix(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if !c.useHTTP {
+		if !c.ws.SendJSON(result) {
+			log.Printf("⚠️ Komut sonucu gönderilemedi (kuyruk dolu): %s", cmd.ID)
+		}
+		return
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("⚠️ Komut sonucu hazırlanamadı: %v", err)
+		return
+	}
+	resp, err := c.httpClient.Post(c.serverURL+"/api/command-result", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("⚠️ Komut sonucu gönderilemedi: %v", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// pollCommands HTTP modunda sunucudan komutları long-poll ile çeker.
+func (c *Client) pollCommands(ctx context.Context) {
+	pollClient := &http.Client{Timeout: commandPollWait + 10*time.Second}
+	pollURL := fmt.Sprintf("%s/api/commands?clientId=%s&wait=%d",
+		c.serverURL, url.QueryEscape(c.clientID), int(commandPollWait.Seconds()))
+
+	unsupportedLogged := false
+	for ctx.Err() == nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pollURL, nil)
+		if err != nil {
+			log.Printf("⚠️ Komut isteği oluşturulamadı: %v", err)
+			return
+		}
+		resp, err := pollClient.Do(req)
+		if err != nil {
+			sleepCtx(ctx, 5*time.Second)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusNotFound {
+			// Eski sunucu komut kanalını desteklemiyor
+			resp.Body.Close()
+			if !unsupportedLogged {
+				log.Println("ℹ️ Sunucu komut kanalını desteklemiyor, daha sonra tekrar denenecek")
+				unsupportedLogged = true
+			}
+			sleepCtx(ctx, 5*time.Minute)
+			continue
+		}
+
+		var body struct {
+			Commands []ServerCommand `json:"commands"`
+		}
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+			sleepCtx(ctx, 5*time.Second)
+			continue
+		}
+		if err != nil {
+			log.Printf("⚠️ Komut listesi parse edilemedi: %v", err)
+			sleepCtx(ctx, 5*time.Second)
+			continue
+		}
+
+		for _, cmd := range body.Commands {
+			c.enqueueCommand(cmd)
+		}
+	}
+}
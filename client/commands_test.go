@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeCapturer sabit bir kare döner ve kaç kez çağrıldığını sayar.
+type fakeCapturer struct {
+	img   image.Image
+	calls int
+}
+
+func (f *fakeCapturer) Name() string { return "fake" }
+
+func (f *fakeCapturer) Capture() (image.Image, error) {
+	f.calls++
+	return f.img, nil
+}
+
+func (f *fakeCapturer) Close() error { return nil }
+
+func testCommandClient() (*Client, *fakeCapturer) {
+	capturer := &fakeCapturer{img: image.NewRGBA(image.Rect(0, 0, 40, 30))}
+	c := &Client{
+		config:   defaultClientConfig(),
+		capturer: capturer,
+		encoder:  newFrameEncoder(defaultClientConfig().Encoding),
+	}
+	c.setFPS(20)
+	c.consentGranted.Store(true)
+	return c, capturer
+}
+
+func TestCommandParams(t *testing.T) {
+	tests := []struct {
+		command string
+		fps     int
+		quality int
+		wantErr bool
+	}{
+		{command: cmdSetFPS, fps: 1},
+		{command: cmdSetFPS, fps: 60},
+		{command: cmdSetFPS, fps: 0, wantErr: true},
+		{command: cmdSetFPS, fps: 61, wantErr: true},
+		{command: cmdSetFPS, fps: -5, wantErr: true},
+		{command: cmdSetQuality, quality: 1},
+		{command: cmdSetQuality, quality: 100},
+		{command: cmdSetQuality, quality: 0, wantErr: true},
+		{command: cmdSetQuality, quality: 101, wantErr: true},
+		{command: "reboot", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		c, _ := testCommandClient()
+		cmd := ServerCommand{ID: "1", Command: tt.command}
+		cmd.Params.FPS = tt.fps
+		cmd.Params.Quality = tt.quality
+
+		_, err := c.executeCommand(cmd)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s fps=%d quality=%d: hata %v, beklenen hata %v", tt.command, tt.fps, tt.quality, err, tt.wantErr)
+		}
+
+		// Reddedilen değerler ayarları değiştirmez
+		wantInterval, wantQuality := time.Second/20, int32(c.config.Encoding.Quality)
+		if !tt.wantErr && tt.command == cmdSetFPS {
+			wantInterval = time.Second / time.Duration(tt.fps)
+		}
+		if !tt.wantErr && tt.command == cmdSetQuality {
+			wantQuality = int32(tt.quality)
+		}
+		if got := time.Duration(c.frameInterval.Load()); got != wantInterval {
+			t.Errorf("%s fps=%d: kare aralığı %v, beklenen %v", tt.command, tt.fps, got, wantInterval)
+		}
+		if got := c.encoder.quality.Load(); got != wantQuality {
+			t.Errorf("%s quality=%d: kalite %d, beklenen %d", tt.command, tt.quality, got, wantQuality)
+		}
+	}
+}
+
+func TestCommandPauseResume(t *testing.T) {
+	c, _ := testCommandClient()
+	if _, err := c.executeCommand(ServerCommand{Command: cmdPauseCapture}); err != nil || !c.capturePaused.Load() {
+		t.Fatalf("pause_capture: hata %v, duraklatıldı %v", err, c.capturePaused.Load())
+	}
+	if _, err := c.executeCommand(ServerCommand{Command: cmdResumeCapture}); err != nil || c.capturePaused.Load() {
+		t.Fatalf("resume_capture: hata %v, duraklatıldı %v", err, c.capturePaused.Load())
+	}
+	if !c.encoder.keyframeRequested.Load() {
+		t.Error("devam ettirince keyframe istenmedi")
+	}
+}
+
+func TestCommandSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		consent bool
+		paused  bool
+		wantErr error
+	}{
+		{name: "onay yok", consent: false, wantErr: errConsentRequired},
+		{name: "gizlilik molası", consent: true, paused: true, wantErr: errPrivacyPaused},
+		{name: "onay yok ve mola", consent: false, paused: true, wantErr: errConsentRequired},
+		{name: "izinli", consent: true},
+	}
+
+	for _, tt := range tests {
+		c, capturer := testCommandClient()
+		c.consentGranted.Store(tt.consent)
+		c.privacyPaused.Store(tt.paused)
+
+		data, err := c.executeCommand(ServerCommand{Command: cmdSnapshot})
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: hata %v, beklenen %v", tt.name, err, tt.wantErr)
+			}
+			// Reddedilen istekte ekran hiç yakalanmaz
+			if capturer.calls != 0 || data != nil {
+				t.Errorf("%s: ekran yakalandı (%d çağrı), veri %v", tt.name, capturer.calls, data)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		snapshot := data.(map[string]interface{})
+		if snapshot["width"] != 40 || snapshot["height"] != 30 {
+			t.Errorf("%s: boyut %vx%v, beklenen 40x30", tt.name, snapshot["width"], snapshot["height"])
+		}
+		if encoded, _ := snapshot["image"].(string); !strings.HasPrefix(encoded, "data:image/jpeg;base64,") {
+			t.Errorf("%s: görüntü data URL değil", tt.name)
+		}
+	}
+}
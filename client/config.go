@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+)
+
+const clientConfigFile = "client_config.json"
+
+type CaptureSettings struct {
+	// "auto", "x11" veya "command"
+	Backend string `json:"backend"`
+	// X11 için DISPLAY değeri (boşsa ortam değişkeni kullanılır)
+	Display string `json:"display"`
+	// X11 yakalamada MIT-SHM kullanılsın mı
+	UseSHM *bool `json:"use_shm"`
+	// Saniyedeki kare sayısı
+	FPS int `json:"fps"`
+}
+
+type EncodingSettings struct {
+	// JPEG kalitesi (1-100)
+	Quality int `json:"quality"`
+	// Sadece değişen karoları gönder; sunucu desteklediğini bildirirse
+	// varsayılan olarak açıktır, false ile kapatılır
+	DeltaFrames *bool `json:"delta_frames"`
+	TileSize    int   `json:"tile_size"`
+	// Delta modunda tam kare (keyframe) gönderme aralığı
+	KeyframeIntervalSeconds int `json:"keyframe_interval_seconds"`
+}
+
+type TransportSettings struct {
+	// "http" veya "websocket"
+	Mode string `json:"mode"`
+	// Tam WebSocket URL'i; boşsa sunucu adresinden türetilir
+	// (ws(s)://<sunucu host:port><websocket_path>, yol varsayılan /ws)
+	WebSocketURL  string `json:"websocket_url"`
+	WebSocketPath string `json:"websocket_path"`
+
+	PingIntervalSeconds int `json:"ping_interval_seconds"`
+	PongTimeoutSeconds  int `json:"pong_timeout_seconds"`
+	ReconnectMinSeconds int `json:"reconnect_min_seconds"`
+	ReconnectMaxSeconds int `json:"reconnect_max_seconds"`
+	// Gönderilmeyi bekleyen en fazla kare sayısı (dolunca en eskisi atılır)
+	QueueSize int `json:"queue_size"`
+}
+
+type PolicySettings struct {
+	// Engelleme politikalarını sunucudan çek
+	Enabled             bool `json:"enabled"`
+	SyncIntervalSeconds int  `json:"sync_interval_seconds"`
+	// Son geçerli politikaların saklandığı dizin
+	CacheDir string `json:"cache_dir"`
+}
+
+type SpoolSettings struct {
+	// Sunucuya ulaşılamazken kareleri diske biriktir
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"`
+	// Limitler aşılınca en eski kareler silinir
+	MaxSizeMB   int `json:"max_size_mb"`
+	MaxAgeHours int `json:"max_age_hours"`
+	// Çevrimdışıyken kaç saniyede bir tam kare saklanacağı
+	IntervalSeconds int `json:"interval_seconds"`
+}
+
+type EventSettings struct {
+	// Engelleme olaylarını sunucuya raporla
+	Enabled bool `json:"enabled"`
+	// Gönderilemeyen olayların saklandığı dosya
+	QueueFile string `json:"queue_file"`
+	// Kuyruk dolunca en eski olaylar atılır
+	MaxQueued int `json:"max_queued"`
+	// Tek istekte gönderilecek en fazla olay
+	BatchSize            int `json:"batch_size"`
+	FlushIntervalSeconds int `json:"flush_interval_seconds"`
+}
+
+type StatusSettings struct {
+	// Çalışan client'ın durumunun yazıldığı dosya ("status" alt komutu okur)
+	File string `json:"file"`
+	// Kaydın sürdüğünü kalıcı masaüstü bildirimiyle göster
+	Notification bool `json:"notification"`
+}
+
+type ConsentSettings struct {
+	// Kullanıcı izleme politikasını onaylamadan ekran kaydı yapma
+	Required bool   `json:"required"`
+	File     string `json:"file"`
+	// Onay bekleyen politika ("consent" alt komutu okur)
+	RequestFile string `json:"request_file"`
+}
+
+// MaskRect ekran koordinatlarında (sol üst köşe 0,0) karartılacak alan.
+type MaskRect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// MaskWindow karartılacak pencereleri seçer; dolu alanların hepsi eşleşmelidir.
+type MaskWindow struct {
+	// WM_CLASS (instance veya class), büyük/küçük harf duyarsız
+	Class string `json:"class,omitempty"`
+	// Pencere başlığı için düzenli ifade (ör. "(?i)internet bankacılığı")
+	Title string `json:"title,omitempty"`
+	// Pencereyi açan process adı (_NET_WM_PID)
+	Process string `json:"process,omitempty"`
+}
+
+type PrivacySettings struct {
+	// Her karede karartılan sabit alanlar
+	MaskRects []MaskRect `json:"mask_rects"`
+	// Pencereleri karartılacak uygulamalar (sadece Linux/X11)
+	MaskWindows []MaskWindow `json:"mask_windows"`
+}
+
+type PauseSettings struct {
+	// Kullanıcının kaydı kısa süreliğine duraklatabilmesi (gizlilik molası)
+	Enabled bool `json:"enabled"`
+	// Günlük toplam mola süresi (gece yarısı sıfırlanır)
+	DailyBudgetMinutes int `json:"daily_budget_minutes"`
+	// Tek seferde en fazla mola süresi
+	MaxPauseMinutes int `json:"max_pause_minutes"`
+	// Süre belirtilmediğinde (ör. SIGUSR1) verilen mola
+	DefaultMinutes int  `json:"default_minutes"`
+	RequireReason  bool `json:"require_reason"`
+	// "pause"/"resume" alt komutlarının bağlandığı Unix soketi (0600)
+	Socket string `json:"socket"`
+	// Client root olarak çalışırken soketin sahibi yapılacak masaüstü kullanıcısı
+	SocketUser string `json:"socket_user"`
+	StateFile  string `json:"state_file"`
+}
+
+type ClientConfig struct {
+	Capture   CaptureSettings   `json:"capture"`
+	Encoding  EncodingSettings  `json:"encoding"`
+	Transport TransportSettings `json:"transport"`
+	Policy    PolicySettings    `json:"policy"`
+	Spool     SpoolSettings     `json:"spool"`
+	Events    EventSettings     `json:"events"`
+	Status    StatusSettings    `json:"status"`
+	Consent   ConsentSettings   `json:"consent"`
+	Privacy   PrivacySettings   `json:"privacy"`
+	Pause     PauseSettings     `json:"pause"`
+}
+
+func defaultClientConfig() *ClientConfig {
+	return &ClientConfig{
+		Capture: CaptureSettings{
+			Backend: "auto",
+			FPS:     20,
+		},
+		Encoding: EncodingSettings{
+			Quality:                 50,
+			TileSize:                64,
+			KeyframeIntervalSeconds: 10,
+		},
+		Transport: TransportSettings{
+			Mode:                "http",
+			PingIntervalSeconds: 20,
+			PongTimeoutSeconds:  60,
+			ReconnectMinSeconds: 1,
+			ReconnectMaxSeconds: 30,
+			QueueSize:           8,
+		},
+		Policy: PolicySettings{
+			Enabled:             true,
+			SyncIntervalSeconds: 60,
+			CacheDir:            "policy_cache",
+		},
+		Spool: SpoolSettings{
+			Enabled:         true,
+			Dir:             "spool",
+			MaxSizeMB:       200,
+			MaxAgeHours:     24,
+			IntervalSeconds: 5,
+		},
+		Events: EventSettings{
+			Enabled:              true,
+			QueueFile:            "event_queue.json",
+			MaxQueued:            1000,
+			BatchSize:            50,
+			FlushIntervalSeconds: 5,
+		},
+		Status: StatusSettings{
+			File:         "client_status.json",
+			Notification: true,
+		},
+		Consent: ConsentSettings{
+			Required:    true,
+			File:        "consent.json",
+			RequestFile: "consent_request.json",
+		},
+		Pause: PauseSettings{
+			Enabled:            true,
+			DailyBudgetMinutes: 30,
+			MaxPauseMinutes:    15,
+			DefaultMinutes:     10,
+			RequireReason:      true,
+			Socket:             "client.sock",
+			StateFile:          "pause_state.json",
+		},
+	}
+}
+
+func loadClientConfig() *ClientConfig {
+	cfg := defaultClientConfig()
+
+	data, err := os.ReadFile(clientConfigFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Client config dosyası okunamadı: %v", err)
+		}
+	} else if err := json.Unmarshal(data, cfg); err != nil {
+		log.Printf("❌ Client config dosyası parse edilemedi: %v", err)
+		cfg = defaultClientConfig()
+	}
+
+	// Environment variable'lar dosyadaki değerleri ezer
+	if backend := os.Getenv("CAPTURE_BACKEND"); backend != "" {
+		cfg.Capture.Backend = backend
+	}
+	cfg.Capture.Backend = strings.ToLower(strings.TrimSpace(cfg.Capture.Backend))
+	if cfg.Capture.Backend == "" {
+		cfg.Capture.Backend = "auto"
+	}
+	if cfg.Capture.FPS < 1 || cfg.Capture.FPS > 60 {
+		cfg.Capture.FPS = 20
+	}
+	if cfg.Encoding.Quality < 1 || cfg.Encoding.Quality > 100 {
+		cfg.Encoding.Quality = 50
+	}
+	if cfg.Encoding.TileSize < 16 {
+		cfg.Encoding.TileSize = 64
+	}
+	if cfg.Encoding.KeyframeIntervalSeconds <= 0 {
+		cfg.Encoding.KeyframeIntervalSeconds = 10
+	}
+
+	if mode := os.Getenv("TRANSPORT"); mode != "" {
+		cfg.Transport.Mode = mode
+	}
+	if wsURL := os.Getenv("WEBSOCKET_URL"); wsURL != "" {
+		cfg.Transport.WebSocketURL = wsURL
+	}
+	cfg.Transport.Mode = strings.ToLower(strings.TrimSpace(cfg.Transport.Mode))
+	if cfg.Transport.Mode != "websocket" {
+		cfg.Transport.Mode = "http"
+	}
+	if cfg.Transport.PingIntervalSeconds <= 0 {
+		cfg.Transport.PingIntervalSeconds = 20
+	}
+	if cfg.Transport.PongTimeoutSeconds <= cfg.Transport.PingIntervalSeconds {
+		cfg.Transport.PongTimeoutSeconds = cfg.Transport.PingIntervalSeconds * 3
+	}
+	if cfg.Transport.ReconnectMinSeconds <= 0 {
+		cfg.Transport.ReconnectMinSeconds = 1
+	}
+	if cfg.Transport.ReconnectMaxSeconds < cfg.Transport.ReconnectMinSeconds {
+		cfg.Transport.ReconnectMaxSeconds = 30
+	}
+	if cfg.Transport.QueueSize <= 0 {
+		cfg.Transport.QueueSize = 8
+	}
+
+	if cfg.Policy.SyncIntervalSeconds <= 0 {
+		cfg.Policy.SyncIntervalSeconds = 60
+	}
+	if cfg.Policy.CacheDir == "" {
+		cfg.Policy.CacheDir = "policy_cache"
+	}
+
+	if cfg.Spool.Dir == "" {
+		cfg.Spool.Dir = "spool"
+	}
+	if cfg.Spool.MaxSizeMB <= 0 {
+		cfg.Spool.MaxSizeMB = 200
+	}
+	if cfg.Spool.MaxAgeHours <= 0 {
+		cfg.Spool.MaxAgeHours = 24
+	}
+	if cfg.Spool.IntervalSeconds <= 0 {
+		cfg.Spool.IntervalSeconds = 5
+	}
+
+	if cfg.Events.QueueFile == "" {
+		cfg.Events.QueueFile = "event_queue.json"
+	}
+	if cfg.Events.MaxQueued <= 0 {
+		cfg.Events.MaxQueued = 1000
+	}
+	if cfg.Events.BatchSize <= 0 {
+		cfg.Events.BatchSize = 50
+	}
+	if cfg.Events.FlushIntervalSeconds <= 0 {
+		cfg.Events.FlushIntervalSeconds = 5
+	}
+
+	if cfg.Status.File == "" {
+		cfg.Status.File = "client_status.json"
+	}
+	if cfg.Consent.File == "" {
+		cfg.Consent.File = "consent.json"
+	}
+	if cfg.Consent.RequestFile == "" {
+		cfg.Consent.RequestFile = "consent_request.json"
+	}
+	if cfg.Pause.DailyBudgetMinutes < 0 {
+		cfg.Pause.DailyBudgetMinutes = 0
+	}
+	if cfg.Pause.MaxPauseMinutes <= 0 {
+		cfg.Pause.MaxPauseMinutes = 15
+	}
+	if cfg.Pause.DefaultMinutes <= 0 || cfg.Pause.DefaultMinutes > cfg.Pause.MaxPauseMinutes {
+		cfg.Pause.DefaultMinutes = cfg.Pause.MaxPauseMinutes
+	}
+	if cfg.Pause.Socket == "" {
+		cfg.Pause.Socket = "client.sock"
+	}
+	if cfg.Pause.StateFile == "" {
+		cfg.Pause.StateFile = "pause_state.json"
+	}
+
+	return cfg
+}
+
+func (s CaptureSettings) shmEnabled() bool {
+	return s.UseSHM == nil || *s.UseSHM
+}
+
+// deltaEnabled sunucu screen_delta mesajlarını destekliyorsa ve config'de
+// kapatılmamışsa true döner.
+func (s EncodingSettings) deltaEnabled(serverSupports bool) bool {
+	return serverSupports && (s.DeltaFrames == nil || *s.DeltaFrames)
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// Ekran kaydı, kullanıcı izleme politikasını görüp onaylamadan başlamaz.
+// Onay politikanın özetiyle (hash) birlikte saklanır; politika değişirse
+// (sunucu politikası, config dosyaları, yakalama ayarları) kayıt durur ve
+// yeniden onay istenir. Client terminalden çalışıyorsa onay orada sorulur,
+// aksi halde "consent" alt komutuyla verilir.
+
+const (
+	taskConsentGate      = "consent_gate"
+	consentCheckInterval = 2 * time.Second
+)
+
+var errConsentRequired = errors.New("izleme politikası kullanıcı tarafından onaylanmadı")
+
+// monitoringPolicy kullanıcıya gösterilen ve onaylanan izleme kapsamı.
+type monitoringPolicy struct {
+	Server         string   `json:"server"`
+	ClientID       string   `json:"client_id"`
+	FPS            int      `json:"fps"`
+	Quality        int      `json:"quality"`
+	Transport      string   `json:"transport"`
+	OfflineSpool   bool     `json:"offline_spool"`
+	SpoolMaxHours  int      `json:"spool_max_hours,omitempty"`
+	EventReporting bool     `json:"event_reporting"`
+	BlockedApps    []string `json:"blocked_apps,omitempty"`
+	BlockedSites   []string `json:"blocked_sites,omitempty"`
+}
+
+type consentRecord struct {
+	PolicyHash string           `json:"policy_hash"`
+	Policy     monitoringPolicy `json:"policy"`
+	AcceptedAt time.Time        `json:"accepted_at"`
+	User       string           `json:"user,omitempty"`
+}
+
+// monitoringPolicy güncel izleme kapsamını döner. Hız ve kalite config'ten
+// değil çalışan değerlerden alınır; sunucunun set_fps / set_quality komutları
+// da yeniden onay gerektirir.
+func (c *Client) monitoringPolicy() monitoringPolicy {
+	p := monitoringPolicy{
+		Server:         c.serverURL,
+		ClientID:       c.clientID,
+		FPS:            c.fps(),
+		Quality:        c.encoder.Quality(),
+		Transport:      c.config.Transport.Mode,
+		OfflineSpool:   c.config.Spool.Enabled,
+		EventReporting: c.config.Events.Enabled,
+	}
+	if p.OfflineSpool {
+		p.SpoolMaxHours = c.config.Spool.MaxAgeHours
+	}
+	if cfg := c.appBlockerConfig(); cfg != nil && cfg.Settings.AppBlockerEnabled {
+		for _, app := range cfg.BlockedApplications {
+			p.BlockedApps = append(p.BlockedApps, app.Name)
+		}
+	}
+	if cfg := c.websiteBlockerConfig(); cfg != nil && cfg.Settings.WebsiteBlockerEnabled {
+		for _, site := range cfg.BlockedWebsites {
+			p.BlockedSites = append(p.BlockedSites, fmt.Sprintf("%s (%s)", site.Name, strings.Join(site.URLs, ", ")))
+		}
+	}
+	return p
+}
+
+func (p monitoringPolicy) hash() string {
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (p monitoringPolicy) describe() string {
+	var b strings.Builder
+	b.WriteString("📋 İzleme politikası\n")
+	fmt.Fprintf(&b, "  • Ekran görüntüsü saniyede %d kare, JPEG kalitesi %d\n", p.FPS, p.Quality)
+	fmt.Fprintf(&b, "  • Gönderildiği sunucu: %s (%s)\n", p.Server, p.Transport)
+	fmt.Fprintf(&b, "  • Client ID: %s\n", p.ClientID)
+	if p.OfflineSpool {
+		fmt.Fprintf(&b, "  • Bağlantı yokken kareler en fazla %d saat diskte saklanır\n", p.SpoolMaxHours)
+	}
+	if p.EventReporting {
+		b.WriteString("  • Engelleme olayları (uygulama tespiti, kapatma, site engelleme) sunucuya raporlanır\n")
+	}
+	if len(p.BlockedApps) > 0 {
+		fmt.Fprintf(&b, "  • Engellenen uygulamalar: %s\n", strings.Join(p.BlockedApps, ", "))
+	}
+	if len(p.BlockedSites) > 0 {
+		b.WriteString("  • Engellenen siteler:\n")
+		for _, site := range p.BlockedSites {
+			fmt.Fprintf(&b, "      - %s\n", site)
+		}
+	}
+	return b.String()
+}
+
+func readConsentRecord(path string) (*consentRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var record consentRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func writeConsentRecord(path string, record consentRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// consentValid kayıtlı onayın verilen politika için geçerli olup olmadığını döner.
+func (c *Client) consentValid(hash string) bool {
+	record, err := readConsentRecord(c.config.Consent.File)
+	return err == nil && record.PolicyHash == hash && !record.AcceptedAt.IsZero()
+}
+
+// runConsentGate ekran yakalamayı sadece güncel politika onaylıyken çalıştırır.
+func (c *Client) runConsentGate(ctx context.Context) {
+	ticker := time.NewTicker(consentCheckInterval)
+	defer ticker.Stop()
+
+	var (
+		requested string     // onay istenen politika hash'i
+		declined  string     // kullanıcının reddettiği politika hash'i
+		answers   chan error // terminaldeki sorunun cevabı (nil: kabul)
+		noPrompt  = !stdinIsTerminal()
+		asked     consentRecord
+	)
+
+	for {
+		policy := c.monitoringPolicy()
+		hash := policy.hash()
+
+		if c.consentValid(hash) {
+			if !c.consentGranted.Swap(true) {
+				log.Println("✅ İzleme politikası onaylı, ekran kaydı başlıyor")
+				os.Remove(c.config.Consent.RequestFile)
+			}
+			c.super.Start(taskCapture, c.StartScreenCapture)
+			requested = ""
+		} else {
+			if c.consentGranted.Swap(false) {
+				log.Println("🔒 İzleme politikası değişti, yeniden onay gerekli; ekran kaydı durduruldu")
+			}
+			c.super.Stop(taskCapture)
+
+			if hash != requested {
+				requested = hash
+				c.requestConsent(policy, hash)
+				if answers == nil && hash != declined && !noPrompt {
+					asked = consentRecord{PolicyHash: hash, Policy: policy}
+					answers = promptConsent()
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-answers:
+			answers = nil
+			if asked.PolicyHash != requested {
+				// Soru sorulurken politika değişti, güncel politika tekrar sorulur
+				requested = ""
+				continue
+			}
+			if errors.Is(err, errConsentDeclined) {
+				declined = asked.PolicyHash
+				log.Println("❌ İzleme politikası onaylanmadı, ekran kaydı yapılmayacak")
+				continue
+			}
+			if err != nil {
+				// Terminal kapalı (ör. stdin /dev/null), onay alt komutla verilebilir
+				noPrompt = true
+				continue
+			}
+			if err := recordConsent(c.config.Consent.File, asked); err != nil {
+				log.Printf("⚠️ Onay kaydedilemedi: %v", err)
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// requestConsent onay bekleyen politikayı "consent" alt komutu için yazar ve
+// kullanıcıyı bildirimle uyarır.
+func (c *Client) requestConsent(policy monitoringPolicy, hash string) {
+	log.Printf("🔒 Ekran kaydı için izleme politikasının onaylanması gerekiyor:\n%s", policy.describe())
+	if err := writeConsentRecord(c.config.Consent.RequestFile, consentRecord{PolicyHash: hash, Policy: policy}); err != nil {
+		log.Printf("⚠️ Onay isteği yazılamadı: %v", err)
+	}
+	c.notify(Notification{
+		Key:     "consent",
+		Title:   "Screen Recorder",
+		Body:    "Ekran kaydı başlamadan önce izleme politikasını onaylamanız gerekiyor (client consent).",
+		Urgency: notifyUrgencyCritical,
+	})
+}
+
+// promptConsent onayı terminalden sorar (politika requestConsent'te loglanmıştır).
+// Okuma iptal edilemediği için ayrı goroutine'de yapılır; cevap kanala yazılır.
+func promptConsent() chan error {
+	answers := make(chan error, 1)
+	go func() {
+		answers <- askConsent(os.Stdin)
+	}()
+	return answers
+}
+
+var errConsentDeclined = errors.New("onaylanmadı")
+
+// askConsent kabul edilirse nil, reddedilirse errConsentDeclined döner.
+func askConsent(in *os.File) error {
+	fmt.Print("Bu izleme politikasını kabul ediyor musunuz? (evet/hayır): ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "evet", "e", "yes", "y":
+		return nil
+	}
+	return errConsentDeclined
+}
+
+func recordConsent(path string, record consentRecord) error {
+	record.AcceptedAt = time.Now()
+	if u, err := user.Current(); err == nil {
+		record.User = u.Username
+	}
+	if err := writeConsentRecord(path, record); err != nil {
+		return err
+	}
+	log.Printf("📝 Onay kaydedildi (%s, %s)", record.User, record.AcceptedAt.Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runConsent çalışan client'ın onay beklediği politikayı gösterir ve onayı kaydeder.
+func runConsent(args []string) int {
+	settings := loadClientConfig().Consent
+	fs := flag.NewFlagSet("consent", flag.ContinueOnError)
+	show := fs.Bool("show", false, "sadece mevcut onayı göster")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	current, _ := readConsentRecord(settings.File)
+	request, err := readConsentRecord(settings.RequestFile)
+	if *show || err != nil {
+		if current == nil || current.AcceptedAt.IsZero() {
+			fmt.Println("ℹ️ Kayıtlı onay yok")
+		} else {
+			fmt.Printf("✅ %s tarafından %s tarihinde onaylandı\n", current.User, current.AcceptedAt.Format("2006-01-02 15:04:05"))
+			fmt.Print(current.Policy.describe())
+		}
+		if !*show {
+			fmt.Println("ℹ️ Onay bekleyen politika yok")
+		}
+		return 0
+	}
+
+	if current != nil && current.PolicyHash == request.PolicyHash && !current.AcceptedAt.IsZero() {
+		fmt.Println("✅ Bu politika zaten onaylanmış")
+		return 0
+	}
+	fmt.Print(request.Policy.describe())
+	if askConsent(os.Stdin) != nil {
+		fmt.Println("❌ Onaylanmadı, ekran kaydı yapılmayacak")
+		return 1
+	}
+	if err := recordConsent(settings.File, *request); err != nil {
+		fmt.Printf("❌ Onay kaydedilemedi: %v\n", err)
+		return 1
+	}
+	fmt.Println("✅ Onay kaydedildi, ekran kaydı birkaç saniye içinde başlayacak")
+	return 0
+}
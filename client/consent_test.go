@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Sunucunun çalışırken değiştirdiği hız ve kalite onaylanan politikaya girer.
+func TestMonitoringPolicyLiveValues(t *testing.T) {
+	c, _ := testCommandClient()
+	c.config.Consent.File = filepath.Join(t.TempDir(), "consent.json")
+
+	policy := c.monitoringPolicy()
+	if policy.FPS != 20 || policy.Quality != c.config.Encoding.Quality {
+		t.Fatalf("başlangıç politikası fps %d, kalite %d", policy.FPS, policy.Quality)
+	}
+	if err := recordConsent(c.config.Consent.File, consentRecord{PolicyHash: policy.hash(), Policy: policy}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.consentValid(c.monitoringPolicy().hash()) {
+		t.Fatal("değişmeyen politika için onay geçerli olmalı")
+	}
+
+	tests := []struct {
+		command string
+		fps     int
+		quality int
+	}{
+		{command: cmdSetFPS, fps: 7},
+		{command: cmdSetQuality, quality: 35},
+	}
+	for _, tt := range tests {
+		before := c.monitoringPolicy().hash()
+		cmd := ServerCommand{Command: tt.command}
+		cmd.Params.FPS, cmd.Params.Quality = tt.fps, tt.quality
+		if _, err := c.executeCommand(cmd); err != nil {
+			t.Fatal(err)
+		}
+
+		policy := c.monitoringPolicy()
+		if tt.fps != 0 && policy.FPS != tt.fps {
+			t.Errorf("%s: politikadaki fps %d, beklenen %d", tt.command, policy.FPS, tt.fps)
+		}
+		if tt.quality != 0 && policy.Quality != tt.quality {
+			t.Errorf("%s: politikadaki kalite %d, beklenen %d", tt.command, policy.Quality, tt.quality)
+		}
+		if policy.hash() == before || c.consentValid(policy.hash()) {
+			t.Errorf("%s: politika değişince yeniden onay gerekmeli", tt.command)
+		}
+	}
+}
+
+func TestClientFPS(t *testing.T) {
+	c := &Client{}
+	if got := c.fps(); got != 0 {
+		t.Errorf("ayarlanmamış fps %d", got)
+	}
+	for fps := 1; fps <= 60; fps++ {
+		c.setFPS(fps)
+		if got := c.fps(); got != fps {
+			t.Errorf("setFPS(%d) sonrası fps %d", fps, got)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimal D-Bus istemcisi. Sadece oturum bus'ına bağlanıp senkron method
+// çağrısı yapmayı destekler (masaüstü bildirimleri için); libdbus/cgo gerektirmez.
+
+const (
+	dbusMethodCall   = 1
+	dbusMethodReturn = 2
+	dbusError        = 3
+
+	dbusFieldPath        = 1
+	dbusFieldInterface   = 2
+	dbusFieldMember      = 3
+	dbusFieldErrorName   = 4
+	dbusFieldReplySerial = 5
+	dbusFieldDestination = 6
+	dbusFieldSignature   = 8
+
+	dbusCallTimeout = 5 * time.Second
+	// Bozuk bir mesajın sınırsız bellek ayırmasını engeller
+	dbusMaxMessage = 1 << 20
+)
+
+type dbusConn struct {
+	conn   net.Conn
+	rd     *bufio.Reader
+	mu     sync.Mutex
+	serial uint32
+}
+
+// dbusEncoder D-Bus tiplerini little-endian olarak hizalayarak yazar.
+// Hizalama tamponun başına göredir.
+type dbusEncoder struct {
+	buf []byte
+}
+
+func (e *dbusEncoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *dbusEncoder) byte(b byte) { e.buf = append(e.buf, b) }
+
+func (e *dbusEncoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *dbusEncoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+func (e *dbusEncoder) signature(s string) {
+	e.buf = append(e.buf, byte(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// array uzunluk alanını yazar, elemanları fill ile ekler ve uzunluğu düzeltir.
+// İlk elemanın hizalama boşluğu dizi boş olsa da yazılır.
+func (e *dbusEncoder) array(elemAlign int, fill func()) {
+	e.uint32(0)
+	lenPos := len(e.buf) - 4
+	e.align(elemAlign)
+	start := len(e.buf)
+	fill()
+	binary.LittleEndian.PutUint32(e.buf[lenPos:], uint32(len(e.buf)-start))
+}
+
+func dbusSessionAddress() (string, error) {
+	if addr := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); addr != "" {
+		return addr, nil
+	}
+	path := fmt.Sprintf("/run/user/%d/bus", os.Getuid())
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.New("DBUS_SESSION_BUS_ADDRESS tanımlı değil")
+	}
+	return "unix:path=" + path, nil
+}
+
+// dialSessionBus oturum bus'ına bağlanır, EXTERNAL ile kimlik doğrular ve
+// Hello ile kaydolur. Adres birden fazla seçenek içerebilir (';' ile ayrılmış).
+func dialSessionBus() (*dbusConn, error) {
+	address, err := dbusSessionAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, option := range strings.Split(address, ";") {
+		transport, params, ok := strings.Cut(option, ":")
+		if !ok || transport != "unix" {
+			lastErr = fmt.Errorf("desteklenmeyen D-Bus adresi: %q", option)
+			continue
+		}
+
+		var socket string
+		for _, kv := range strings.Split(params, ",") {
+			key, value, _ := strings.Cut(kv, "=")
+			switch key {
+			case "path":
+				socket = unescapeDBusAddress(value)
+			case "abstract":
+				socket = "@" + unescapeDBusAddress(value)
+			}
+		}
+		if socket == "" {
+			lastErr = fmt.Errorf("desteklenmeyen D-Bus adresi: %q", option)
+			continue
+		}
+
+		conn, err := net.DialTimeout("unix", socket, dbusCallTimeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		d := &dbusConn{conn: conn, rd: bufio.NewReader(conn)}
+		if err := d.authenticate(); err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+		if _, _, err := d.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", "", nil); err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+		return d, nil
+	}
+	return nil, lastErr
+}
+
+// unescapeDBusAddress adreslerdeki %xx kaçışlarını çözer.
+func unescapeDBusAddress(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+2 < len(value) {
+			if v, err := strconv.ParseUint(value[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+func (d *dbusConn) authenticate() error {
+	d.conn.SetDeadline(time.Now().Add(dbusCallTimeout))
+	defer d.conn.SetDeadline(time.Time{})
+
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := d.conn.Write([]byte("\x00AUTH EXTERNAL " + uid + "\r\n")); err != nil {
+		return err
+	}
+	line, err := d.rd.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("D-Bus kimlik doğrulaması reddedildi: %s", strings.TrimSpace(line))
+	}
+	_, err = d.conn.Write([]byte("BEGIN\r\n"))
+	return err
+}
+
+func (d *dbusConn) Close() error {
+	return d.conn.Close()
+}
+
+// call method çağrısı yapar ve cevabın imzasını ve gövdesini döner.
+// Arada gelen sinyaller ve başka mesajlar atlanır.
+func (d *dbusConn) call(dest, path, iface, member, sig string, body []byte) (string, *dbusDecoder, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.serial++
+	serial := d.serial
+
+	msg := dbusEncoder{}
+	msg.byte('l')
+	msg.byte(dbusMethodCall)
+	msg.byte(0) // flags
+	msg.byte(1) // protokol versiyonu
+	msg.uint32(uint32(len(body)))
+	msg.uint32(serial)
+	msg.array(8, func() {
+		field := func(code byte, typ string, write func()) {
+			msg.align(8)
+			msg.byte(code)
+			msg.signature(typ)
+			write()
+		}
+		field(dbusFieldPath, "o", func() { msg.string(path) })
+		field(dbusFieldDestination, "s", func() { msg.string(dest) })
+		field(dbusFieldInterface, "s", func() { msg.string(iface) })
+		field(dbusFieldMember, "s", func() { msg.string(member) })
+		if sig != "" {
+			field(dbusFieldSignature, "g", func() { msg.signature(sig) })
+		}
+	})
+	msg.align(8)
+	msg.buf = append(msg.buf, body...)
+
+	d.conn.SetDeadline(time.Now().Add(dbusCallTimeout))
+	defer d.conn.SetDeadline(time.Time{})
+
+	if _, err := d.conn.Write(msg.buf); err != nil {
+		return "", nil, err
+	}
+
+	for {
+		reply, err := d.readMessage()
+		if err != nil {
+			return "", nil, err
+		}
+		if reply.replySerial != serial {
+			continue
+		}
+		switch reply.msgType {
+		case dbusMethodReturn:
+			return reply.signature, reply.body, nil
+		case dbusError:
+			text := reply.errorName
+			if strings.HasPrefix(reply.signature, "s") {
+				if detail, err := reply.body.string(); err == nil {
+					text += ": " + detail
+				}
+			}
+			return "", nil, errors.New(text)
+		}
+	}
+}
+
+type dbusMessage struct {
+	msgType     byte
+	replySerial uint32
+	signature   string
+	errorName   string
+	body        *dbusDecoder
+}
+
+func (d *dbusConn) readMessage() (*dbusMessage, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(d.rd, fixed); err != nil {
+		return nil, err
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	switch fixed[0] {
+	case 'l':
+	case 'B':
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("geçersiz D-Bus mesajı (endian %q)", fixed[0])
+	}
+
+	bodyLen := order.Uint32(fixed[4:])
+	fieldsLen := order.Uint32(fixed[12:])
+	headerLen := 16 + int(fieldsLen)
+	headerLen += (8 - headerLen%8) % 8
+	if bodyLen > dbusMaxMessage || fieldsLen > dbusMaxMessage {
+		return nil, errors.New("D-Bus mesajı çok büyük")
+	}
+
+	data := make([]byte, headerLen+int(bodyLen))
+	copy(data, fixed)
+	if _, err := io.ReadFull(d.rd, data[16:]); err != nil {
+		return nil, err
+	}
+
+	msg := &dbusMessage{msgType: fixed[1]}
+	fields := &dbusDecoder{buf: data[:16+fieldsLen], pos: 16, order: order}
+	for fields.pos < len(fields.buf) {
+		fields.align(8)
+		code, err := fields.byte()
+		if err != nil {
+			return nil, err
+		}
+		typ, err := fields.signature()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case "s", "o":
+			value, err := fields.string()
+			if err != nil {
+				return nil, err
+			}
+			if code == dbusFieldErrorName {
+				msg.errorName = value
+			}
+		case "g":
+			value, err := fields.signature()
+			if err != nil {
+				return nil, err
+			}
+			if code == dbusFieldSignature {
+				msg.signature = value
+			}
+		case "u":
+			value, err := fields.uint32()
+			if err != nil {
+				return nil, err
+			}
+			if code == dbusFieldReplySerial {
+				msg.replySerial = value
+			}
+		default:
+			return nil, fmt.Errorf("beklenmeyen D-Bus başlık tipi %q", typ)
+		}
+	}
+
+	msg.body = &dbusDecoder{buf: data[headerLen:], order: order}
+	return msg, nil
+}
+
+// dbusDecoder gelen mesaj gövdesindeki temel tipleri okur.
+type dbusDecoder struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+var errDBusShort = errors.New("D-Bus mesajı eksik")
+
+func (d *dbusDecoder) align(n int) {
+	d.pos += (n - d.pos%n) % n
+}
+
+func (d *dbusDecoder) byte() (byte, error) {
+	if d.pos >= len(d.buf) {
+		return 0, errDBusShort
+	}
+	d.pos++
+	return d.buf[d.pos-1], nil
+}
+
+func (d *dbusDecoder) uint32() (uint32, error) {
+	d.align(4)
+	if d.pos+4 > len(d.buf) {
+		return 0, errDBusShort
+	}
+	d.pos += 4
+	return d.order.Uint32(d.buf[d.pos-4:]), nil
+}
+
+func (d *dbusDecoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	if d.pos+int(n)+1 > len(d.buf) {
+		return "", errDBusShort
+	}
+	s := string(d.buf[d.pos : d.pos+int(n)])
+	d.pos += int(n) + 1
+	return s, nil
+}
+
+func (d *dbusDecoder) signature() (string, error) {
+	n, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	if d.pos+int(n)+1 > len(d.buf) {
+		return "", errDBusShort
+	}
+	s := string(d.buf[d.pos : d.pos+int(n)])
+	d.pos += int(n) + 1
+	return s, nil
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestDBusEncoderAlignment(t *testing.T) {
+	e := dbusEncoder{}
+	e.byte('x')
+	e.uint32(7)
+	e.string("ab")
+	e.signature("as")
+	e.byte(1)
+
+	want := []byte{
+		'x', 0, 0, 0, 7, 0, 0, 0,
+		2, 0, 0, 0, 'a', 'b', 0,
+		2, 'a', 's', 0,
+		1,
+	}
+	if !bytes.Equal(e.buf, want) {
+		t.Fatalf("kodlanan\n%v\nbeklenen\n%v", e.buf, want)
+	}
+
+	// Boş dizide de ilk elemanın hizalaması yazılır: uzunluk 8..12, boşluk 16'ya kadar
+	e = dbusEncoder{}
+	e.string("ab")
+	e.array(8, func() {})
+	e.byte(1)
+	want = []byte{
+		2, 0, 0, 0, 'a', 'b', 0, 0,
+		0, 0, 0, 0,
+		0, 0, 0, 0,
+		1,
+	}
+	if !bytes.Equal(e.buf, want) {
+		t.Fatalf("boş dizi\n%v\nbeklenen\n%v", e.buf, want)
+	}
+
+	// Dizi uzunluğu hizalama boşluğunu içermez
+	e = dbusEncoder{}
+	e.array(8, func() {
+		e.align(8)
+		e.string("k")
+	})
+	if n := binary.LittleEndian.Uint32(e.buf); n != uint32(len(e.buf)-8) || n != 6 {
+		t.Fatalf("dizi uzunluğu %d, beklenen 6 (%v)", n, e.buf)
+	}
+}
+
+// dbusTestMessage sunucunun göndereceği bir cevap veya sinyal mesajı kodlar.
+func dbusTestMessage(msgType byte, replySerial uint32, errorName, sig string, body []byte) []byte {
+	msg := dbusEncoder{}
+	msg.byte('l')
+	msg.byte(msgType)
+	msg.byte(0)
+	msg.byte(1)
+	msg.uint32(uint32(len(body)))
+	msg.uint32(1000 + replySerial)
+	msg.array(8, func() {
+		field := func(code byte, typ string, write func()) {
+			msg.align(8)
+			msg.byte(code)
+			msg.signature(typ)
+			write()
+		}
+		if replySerial != 0 {
+			field(dbusFieldReplySerial, "u", func() { msg.uint32(replySerial) })
+		}
+		if errorName != "" {
+			field(dbusFieldErrorName, "s", func() { msg.string(errorName) })
+		}
+		if sig != "" {
+			field(dbusFieldSignature, "g", func() { msg.signature(sig) })
+		}
+	})
+	msg.align(8)
+	msg.buf = append(msg.buf, body...)
+	return msg.buf
+}
+
+func readTestMessage(t *testing.T, data []byte) *dbusMessage {
+	t.Helper()
+	d := &dbusConn{rd: bufio.NewReader(bytes.NewReader(data))}
+	msg, err := d.readMessage()
+	if err != nil {
+		t.Fatalf("mesaj okunamadı: %v", err)
+	}
+	return msg
+}
+
+func TestDBusReadMessage(t *testing.T) {
+	body := dbusEncoder{}
+	body.string("ayrıntı")
+	body.uint32(42)
+	msg := readTestMessage(t, dbusTestMessage(dbusError, 9, "org.example.Error", "su", body.buf))
+
+	if msg.msgType != dbusError || msg.replySerial != 9 || msg.errorName != "org.example.Error" || msg.signature != "su" {
+		t.Fatalf("başlık alanları: %+v", msg)
+	}
+	if s, err := msg.body.string(); err != nil || s != "ayrıntı" {
+		t.Fatalf("gövde string: %q, %v", s, err)
+	}
+	if v, err := msg.body.uint32(); err != nil || v != 42 {
+		t.Fatalf("gövde uint32: %d, %v", v, err)
+	}
+	if _, err := msg.body.uint32(); err != errDBusShort {
+		t.Fatalf("gövde sonu: %v", err)
+	}
+}
+
+func TestDBusReadMessageBigEndian(t *testing.T) {
+	be := binary.BigEndian
+	data := []byte{'B', dbusMethodReturn, 0, 1}
+	data = be.AppendUint32(data, 4)  // gövde uzunluğu
+	data = be.AppendUint32(data, 7)  // serial
+	data = be.AppendUint32(data, 15) // başlık alanları uzunluğu
+	data = append(data, dbusFieldReplySerial, 1, 'u', 0)
+	data = be.AppendUint32(data, 3)
+	data = append(data, dbusFieldSignature, 1, 'g', 0, 1, 'u', 0)
+	data = append(data, 0) // 8'e hizalama
+	data = be.AppendUint32(data, 0x01020304)
+
+	msg := readTestMessage(t, data)
+	if msg.msgType != dbusMethodReturn || msg.replySerial != 3 || msg.signature != "u" {
+		t.Fatalf("başlık alanları: %+v", msg)
+	}
+	if v, err := msg.body.uint32(); err != nil || v != 0x01020304 {
+		t.Fatalf("gövde: %#x, %v", v, err)
+	}
+}
+
+func TestDBusReadMessageMalformed(t *testing.T) {
+	valid := dbusTestMessage(dbusMethodReturn, 1, "", "u", []byte{1, 0, 0, 0})
+	huge := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint32(huge[4:], dbusMaxMessage+1)
+	badField := dbusTestMessage(dbusMethodReturn, 1, "", "", nil)
+	badField[17] = 1
+	badField[18] = 'x' // bilinmeyen başlık tipi
+
+	for name, data := range map[string][]byte{
+		"endian":       append([]byte{'X'}, valid[1:]...),
+		"çok büyük":    huge,
+		"eksik gövde":  valid[:len(valid)-2],
+		"eksik başlık": valid[:10],
+		"başlık tipi":  badField,
+	} {
+		d := &dbusConn{rd: bufio.NewReader(bytes.NewReader(data))}
+		if _, err := d.readMessage(); err == nil {
+			t.Errorf("%s: hata bekleniyordu", name)
+		}
+	}
+}
+
+// notifyCall fake bildirim servisine gelen Notify çağrısının gövdesi.
+type notifyCall struct {
+	appName   string
+	replaceID uint32
+	icon      string
+	summary   string
+	body      string
+	urgency   byte
+	expire    uint32
+}
+
+// decodeNotifyCall fake servis goroutine'inde çalıştığı için t.Fatal kullanmaz.
+func decodeNotifyCall(t *testing.T, body *dbusDecoder) notifyCall {
+	t.Helper()
+	var call notifyCall
+	var err error
+	must := func(e error) {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	str := func() string {
+		s, e := body.string()
+		must(e)
+		return s
+	}
+	u32 := func() uint32 {
+		v, e := body.uint32()
+		must(e)
+		return v
+	}
+
+	call.appName = str()
+	call.replaceID = u32()
+	call.icon = str()
+	call.summary = str()
+	call.body = str()
+	if n := u32(); n != 0 { // actions as
+		t.Errorf("actions dizisi boş olmalı, uzunluk %d", n)
+	}
+	hintsLen := u32() // hints a{sv}
+	body.align(8)
+	end := body.pos + int(hintsLen)
+	for body.pos < end && err == nil {
+		body.align(8)
+		key := str()
+		sig, e := body.signature()
+		must(e)
+		if key != "urgency" || sig != "y" {
+			t.Errorf("beklenmeyen hint %q (%q)", key, sig)
+		}
+		call.urgency, e = body.byte()
+		must(e)
+	}
+	call.expire = u32()
+	if err != nil {
+		t.Errorf("Notify gövdesi çözülemedi: %v", err)
+	}
+	if body.pos != len(body.buf) {
+		t.Errorf("Notify gövdesinde %d bayt fazla", len(body.buf)-body.pos)
+	}
+	return call
+}
+
+func TestDBusNotifierNotify(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	notifier := &dbusNotifier{
+		conn: &dbusConn{conn: client, rd: bufio.NewReader(client)},
+		ids:  make(map[string]uint32),
+	}
+
+	calls := make(chan notifyCall, 4)
+	go func() {
+		bus := &dbusConn{conn: server, rd: bufio.NewReader(server)}
+		for serial := uint32(1); ; serial++ {
+			msg, err := bus.readMessage()
+			if err != nil {
+				return
+			}
+			if msg.msgType != dbusMethodCall || msg.signature != "susssasa{sv}i" {
+				t.Errorf("beklenmeyen çağrı: %+v", msg)
+				return
+			}
+			calls <- decodeNotifyCall(t, msg.body)
+
+			// Araya giren sinyal ve başka cevaplar atlanmalı
+			server.Write(dbusTestMessage(4, 0, "", "", nil))
+			server.Write(dbusTestMessage(dbusMethodReturn, serial+100, "", "u", []byte{9, 0, 0, 0}))
+			if serial == 3 {
+				errBody := dbusEncoder{}
+				errBody.string("servis yok")
+				server.Write(dbusTestMessage(dbusError, serial, "org.freedesktop.DBus.Error.ServiceUnknown", "s", errBody.buf))
+				return
+			}
+			server.Write(dbusTestMessage(dbusMethodReturn, serial, "", "u", binary.LittleEndian.AppendUint32(nil, 40+serial)))
+		}
+	}()
+
+	n := Notification{Key: "Steam", Title: "🚫 Steam", Body: "Uyarı 1/3", Urgency: notifyUrgencyNormal}
+	if err := notifier.Notify(n); err != nil {
+		t.Fatal(err)
+	}
+	first := <-calls
+	want := notifyCall{appName: "Screen Recorder", icon: "dialog-warning", summary: "🚫 Steam", body: "Uyarı 1/3",
+		urgency: notifyUrgencyNormal, expire: notifyExpireMillis}
+	if first != want {
+		t.Fatalf("Notify çağrısı %+v, beklenen %+v", first, want)
+	}
+
+	// Aynı anahtarlı bildirim öncekinin yerine geçer; kalıcı bildirim süresizdir
+	n.Urgency, n.Persistent = notifyUrgencyCritical, true
+	if err := notifier.Notify(n); err != nil {
+		t.Fatal(err)
+	}
+	second := <-calls
+	if second.replaceID != 41 || second.urgency != notifyUrgencyCritical || second.expire != 0 {
+		t.Fatalf("ikinci Notify çağrısı %+v", second)
+	}
+
+	// Hata cevabı döner ve bağlantı bir sonraki bildirimde yeniden kurulur
+	err := notifier.Notify(n)
+	<-calls
+	if err == nil || !strings.Contains(err.Error(), "ServiceUnknown: servis yok") {
+		t.Fatalf("hata %v", err)
+	}
+	if notifier.conn != nil {
+		t.Fatal("hatadan sonra bağlantı kapatılmalı")
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Delta kodlama: kare sabit boyutlu karolara bölünür, her karonun hash'i
+// bir önceki kareyle karşılaştırılır ve sadece değişen karolar gönderilir.
+// Belirli aralıklarla (ve boyut değişince) tam kare (keyframe) gönderilir.
+
+type TileData struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	W     int    `json:"w"`
+	H     int    `json:"h"`
+	Image string `json:"image,omitempty"`
+
+	// Ham JPEG verisi; JSON formatında Image alanına base64 olarak yazılır
+	Data []byte `json:"-"`
+}
+
+type frameEncoder struct {
+	settings EncodingSettings
+	seed     maphash.Seed
+	quality  atomic.Int32 // çalışırken sunucu komutuyla değiştirilebilir
+	delta    atomic.Bool  // sunucu delta karelerini destekliyor mu (bağlanınca belirlenir)
+
+	seq               uint64
+	width             int
+	height            int
+	hashes            []uint64
+	lastKeyframe      time.Time
+	keyframeRequested atomic.Bool // transport goroutine'lerinden de set edilir
+}
+
+func newFrameEncoder(settings EncodingSettings) *frameEncoder {
+	e := &frameEncoder{
+		settings: settings,
+		seed:     maphash.MakeSeed(),
+	}
+	e.quality.Store(int32(settings.Quality))
+	return e
+}
+
+func (e *frameEncoder) SetQuality(quality int) {
+	e.quality.Store(int32(quality))
+}
+
+// Quality kullanılan JPEG kalitesini döner.
+func (e *frameEncoder) Quality() int {
+	return int(e.quality.Load())
+}
+
+// SetDelta delta kodlamayı açar veya kapatır. Değişiklikten sonraki ilk kare
+// tam kare olarak gönderilir.
+func (e *frameEncoder) SetDelta(enabled bool) {
+	if e.delta.Load() != enabled {
+		e.keyframeRequested.Store(true)
+		e.delta.Store(enabled)
+	}
+}
+
+// DeltaEnabled delta kodlamanın açık olup olmadığını döner.
+func (e *frameEncoder) DeltaEnabled() bool {
+	return e.delta.Load()
+}
+
+// RequestKeyframe bir sonraki karenin tam kare olarak gönderilmesini sağlar
+// (örn. gönderim hatasından sonra sunucu tarafı senkronu kaybetmiş olabilir).
+func (e *frameEncoder) RequestKeyframe() {
+	e.keyframeRequested.Store(true)
+}
+
+// Encode kareyi kodlar. Ekranda değişiklik yoksa nil döner.
+func (e *frameEncoder) Encode(img image.Image, clientID string) (*ScreenData, error) {
+	frame := toRGBA(img)
+	width, height := frame.Rect.Dx(), frame.Rect.Dy()
+	tileSize := e.settings.TileSize
+	cols := (width + tileSize - 1) / tileSize
+	rows := (height + tileSize - 1) / tileSize
+
+	delta := e.delta.Load()
+	var hashes []uint64
+	if delta {
+		hashes = make([]uint64, cols*rows)
+		for ty := 0; ty < rows; ty++ {
+			for tx := 0; tx < cols; tx++ {
+				hashes[ty*cols+tx] = e.hashTile(frame, e.tileRect(frame, tx, ty))
+			}
+		}
+	}
+
+	keyframe := !delta || e.keyframeRequested.Load() ||
+		width != e.width || height != e.height || len(hashes) != len(e.hashes) ||
+		time.Since(e.lastKeyframe) >= time.Duration(e.settings.KeyframeIntervalSeconds)*time.Second
+
+	var dirty []int
+	if !keyframe {
+		for i := range hashes {
+			if hashes[i] != e.hashes[i] {
+				dirty = append(dirty, i)
+			}
+		}
+		if len(dirty) == 0 {
+			return nil, nil
+		}
+		// Ekranın yarısından fazlası değiştiyse tam kare daha ucuz
+		if len(dirty)*2 > len(hashes) {
+			keyframe = true
+		}
+	}
+
+	screenData := &ScreenData{
+		Timestamp: time.Now().Unix(),
+		ClientID:  clientID,
+		Seq:       e.seq + 1,
+		Width:     width,
+		Height:    height,
+	}
+
+	quality := int(e.quality.Load())
+	if keyframe {
+		encoded, err := encodeJPEG(frame, quality)
+		if err != nil {
+			e.keyframeRequested.Store(true)
+			return nil, err
+		}
+		screenData.Type = "screen_update"
+		screenData.ImageData = encoded
+		screenData.Keyframe = true
+		e.lastKeyframe = time.Now()
+		e.keyframeRequested.Store(false)
+	} else {
+		screenData.Type = "screen_delta"
+		screenData.TileSize = tileSize
+		for _, i := range dirty {
+			rect := e.tileRect(frame, i%cols, i/cols)
+			encoded, err := encodeJPEG(frame.SubImage(rect), quality)
+			if err != nil {
+				e.keyframeRequested.Store(true)
+				return nil, err
+			}
+			screenData.Tiles = append(screenData.Tiles, TileData{
+				X:    rect.Min.X - frame.Rect.Min.X,
+				Y:    rect.Min.Y - frame.Rect.Min.Y,
+				W:    rect.Dx(),
+				H:    rect.Dy(),
+				Data: encoded,
+			})
+		}
+	}
+
+	e.seq++
+	e.width, e.height = width, height
+	e.hashes = hashes
+	return screenData, nil
+}
+
+func (e *frameEncoder) tileRect(frame *image.RGBA, tx, ty int) image.Rectangle {
+	ts := e.settings.TileSize
+	origin := frame.Rect.Min.Add(image.Pt(tx*ts, ty*ts))
+	return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(ts, ts))}.Intersect(frame.Rect)
+}
+
+func (e *frameEncoder) hashTile(frame *image.RGBA, rect image.Rectangle) uint64 {
+	var h maphash.Hash
+	h.SetSeed(e.seed)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		start := frame.PixOffset(rect.Min.X, y)
+		h.Write(frame.Pix[start : start+rect.Dx()*4])
+	}
+	return h.Sum64()
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Rect, img, img.Bounds().Min, draw.Src)
+	return rgba
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func jpegDataURL(data []byte) string {
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func encodeJPEGDataURL(img image.Image, quality int) (string, error) {
+	data, err := encodeJPEG(img, quality)
+	if err != nil {
+		return "", err
+	}
+	return jpegDataURL(data), nil
+}
+
+// decodeFrameImage ham veriyi, yoksa data URL'i çözer.
+func decodeFrameImage(raw []byte, dataURL string) (image.Image, error) {
+	if len(raw) > 0 {
+		img, _, err := image.Decode(bytes.NewReader(raw))
+		return img, err
+	}
+	return decodeDataURL(dataURL)
+}
+
+func decodeDataURL(data string) (image.Image, error) {
+	idx := strings.Index(data, ",")
+	if !strings.HasPrefix(data, "data:") || idx < 0 {
+		return nil, errors.New("geçersiz data URL")
+	}
+	raw, err := base64.StdEncoding.DecodeString(data[idx+1:])
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	return img, err
+}
+
+var errDeltaOutOfSync = errors.New("delta karesi beklenen sırada değil, keyframe gerekli")
+
+// FrameDecoder screen_update ve screen_delta mesajlarından tam kareyi yeniden
+// oluşturan referans çözücüdür. Sunucu/viewer tarafındaki implementasyonların
+// doğrulanması için kullanılır.
+type FrameDecoder struct {
+	frame *image.RGBA
+	seq   uint64
+}
+
+func (d *FrameDecoder) Apply(screenData *ScreenData) (*image.RGBA, error) {
+	switch screenData.Type {
+	case "screen_update":
+		img, err := decodeFrameImage(screenData.ImageData, screenData.Image)
+		if err != nil {
+			return nil, err
+		}
+		d.frame = toRGBA(img)
+		d.seq = screenData.Seq
+		return d.frame, nil
+
+	case "screen_delta":
+		if d.frame == nil || screenData.Seq != d.seq+1 {
+			return nil, errDeltaOutOfSync
+		}
+		if screenData.Width != d.frame.Rect.Dx() || screenData.Height != d.frame.Rect.Dy() {
+			return nil, errDeltaOutOfSync
+		}
+		for _, tile := range screenData.Tiles {
+			img, err := decodeFrameImage(tile.Data, tile.Image)
+			if err != nil {
+				return nil, fmt.Errorf("karo (%d,%d) çözülemedi: %v", tile.X, tile.Y, err)
+			}
+			rect := image.Rect(tile.X, tile.Y, tile.X+tile.W, tile.Y+tile.H)
+			draw.Draw(d.frame, rect, img, img.Bounds().Min, draw.Src)
+		}
+		d.seq = screenData.Seq
+		return d.frame, nil
+
+	default:
+		return nil, fmt.Errorf("bilinmeyen mesaj tipi: %s", screenData.Type)
+	}
+}
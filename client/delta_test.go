@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// Testlerde karolar tek renk gri olarak boyanır: gri tonlar YCbCr'de birebir
+// temsil edilir ve sabit bloklar kalite 100'de JPEG'den kayıpsız döner, böylece
+// çözülen kare kaynakla piksel piksel karşılaştırılabilir.
+
+const testTileSize = 16
+
+func testEncoder() *frameEncoder {
+	e := newFrameEncoder(EncodingSettings{
+		Quality:                 100,
+		TileSize:                testTileSize,
+		KeyframeIntervalSeconds: 3600,
+	})
+	e.SetDelta(true)
+	return e
+}
+
+// grayFrame her karosu farklı gri tonda bir kare oluşturur.
+func grayFrame(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for ty := 0; ty*testTileSize < height; ty++ {
+		for tx := 0; tx*testTileSize < width; tx++ {
+			paintTile(img, tx, ty, uint8(16+(ty*7+tx)*12%224))
+		}
+	}
+	return img
+}
+
+func paintTile(img *image.RGBA, tx, ty int, level uint8) {
+	rect := image.Rect(tx*testTileSize, ty*testTileSize, (tx+1)*testTileSize, (ty+1)*testTileSize)
+	draw.Draw(img, rect, &image.Uniform{color.Gray{Y: level}}, image.Point{}, draw.Src)
+}
+
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	c := image.NewRGBA(img.Rect)
+	copy(c.Pix, img.Pix)
+	return c
+}
+
+func assertSameFrame(t *testing.T, got, want *image.RGBA) {
+	t.Helper()
+	if got.Rect != want.Rect {
+		t.Fatalf("kare boyutu %v, beklenen %v", got.Rect, want.Rect)
+	}
+	for y := want.Rect.Min.Y; y < want.Rect.Max.Y; y++ {
+		for x := want.Rect.Min.X; x < want.Rect.Max.X; x++ {
+			if g, w := got.RGBAAt(x, y), want.RGBAAt(x, y); g != w {
+				t.Fatalf("(%d,%d) pikseli %v, beklenen %v", x, y, g, w)
+			}
+		}
+	}
+}
+
+func encodeFrame(t *testing.T, e *frameEncoder, img *image.RGBA) *ScreenData {
+	t.Helper()
+	sd, err := e.Encode(img, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sd == nil {
+		t.Fatal("değişen kare için mesaj üretilmedi")
+	}
+	return sd
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	e := testEncoder()
+	var d FrameDecoder
+
+	frame := grayFrame(64, 48)
+	key := encodeFrame(t, e, frame)
+	if key.Type != "screen_update" || !key.Keyframe {
+		t.Fatalf("ilk kare keyframe olmalı, gelen %s", key.Type)
+	}
+	got, err := d.Apply(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameFrame(t, got, frame)
+
+	// Değişiklik yoksa mesaj üretilmez
+	if sd, err := e.Encode(cloneRGBA(frame), "test"); err != nil || sd != nil {
+		t.Fatalf("değişmeyen kare için mesaj üretildi: %v, %v", sd, err)
+	}
+
+	for i, changes := range [][][3]int{
+		{{0, 0, 200}, {3, 2, 40}},
+		{{1, 1, 96}},
+	} {
+		frame = cloneRGBA(frame)
+		for _, c := range changes {
+			paintTile(frame, c[0], c[1], uint8(c[2]))
+		}
+		delta := encodeFrame(t, e, frame)
+		if delta.Type != "screen_delta" {
+			t.Fatalf("delta %d: tip %s, beklenen screen_delta", i, delta.Type)
+		}
+		if len(delta.Tiles) != len(changes) {
+			t.Fatalf("delta %d: %d karo, beklenen %d", i, len(delta.Tiles), len(changes))
+		}
+		if got, err = d.Apply(delta); err != nil {
+			t.Fatalf("delta %d: %v", i, err)
+		}
+		assertSameFrame(t, got, frame)
+	}
+}
+
+func TestDeltaOutOfSync(t *testing.T) {
+	e := testEncoder()
+	frame := grayFrame(64, 48)
+	key := encodeFrame(t, e, frame)
+
+	frame = cloneRGBA(frame)
+	paintTile(frame, 0, 0, 200)
+	first := encodeFrame(t, e, frame)
+	frame = cloneRGBA(frame)
+	paintTile(frame, 2, 1, 40)
+	second := encodeFrame(t, e, frame)
+
+	// Keyframe almamış çözücü delta uygulayamaz
+	var fresh FrameDecoder
+	if _, err := fresh.Apply(first); !errors.Is(err, errDeltaOutOfSync) {
+		t.Fatalf("keyframe'siz delta: %v, beklenen errDeltaOutOfSync", err)
+	}
+
+	// Kaybolan delta sonrası gelen delta reddedilir
+	var d FrameDecoder
+	if _, err := d.Apply(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Apply(second); !errors.Is(err, errDeltaOutOfSync) {
+		t.Fatalf("atlanan delta: %v, beklenen errDeltaOutOfSync", err)
+	}
+
+	// Keyframe istenince çözücü tekrar senkronlanır
+	e.RequestKeyframe()
+	frame = cloneRGBA(frame)
+	paintTile(frame, 3, 2, 120)
+	resync := encodeFrame(t, e, frame)
+	if !resync.Keyframe {
+		t.Fatal("RequestKeyframe sonrası keyframe gönderilmedi")
+	}
+	got, err := d.Apply(resync)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameFrame(t, got, frame)
+}
+
+func TestDeltaResolutionChange(t *testing.T) {
+	e := testEncoder()
+	var d FrameDecoder
+
+	small := grayFrame(64, 48)
+	if _, err := d.Apply(encodeFrame(t, e, small)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Boyut değişince delta değil keyframe gönderilir
+	large := grayFrame(96, 64)
+	sd := encodeFrame(t, e, large)
+	if !sd.Keyframe || sd.Width != 96 || sd.Height != 64 {
+		t.Fatalf("boyut değişiminde keyframe bekleniyordu: tip %s, %dx%d", sd.Type, sd.Width, sd.Height)
+	}
+	got, err := d.Apply(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameFrame(t, got, large)
+
+	// Yeni boyutta delta uygulanabilir
+	large = cloneRGBA(large)
+	paintTile(large, 5, 3, 8)
+	got, err = d.Apply(encodeFrame(t, e, large))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameFrame(t, got, large)
+
+	// Çözücünün karesinden farklı boyutta delta senkron dışıdır
+	mismatched := &ScreenData{Type: "screen_delta", Seq: sd.Seq + 2, Width: 64, Height: 48}
+	if _, err := d.Apply(mismatched); !errors.Is(err, errDeltaOutOfSync) {
+		t.Fatalf("farklı boyutta delta: %v, beklenen errDeltaOutOfSync", err)
+	}
+}
+
+func TestDeltaNegotiation(t *testing.T) {
+	off, on := false, true
+	for _, tt := range []struct {
+		setting *bool
+		server  bool
+		want    bool
+	}{
+		{nil, true, true},
+		{nil, false, false},
+		{&on, false, false},
+		{&off, true, false},
+	} {
+		if got := (EncodingSettings{DeltaFrames: tt.setting}).deltaEnabled(tt.server); got != tt.want {
+			t.Errorf("delta_frames %v, sunucu %v: %v, beklenen %v", tt.setting, tt.server, got, tt.want)
+		}
+	}
+
+	// Delta kapalıyken her değişen kare tam kare gönderilir
+	e := testEncoder()
+	e.SetDelta(false)
+	frame := grayFrame(64, 48)
+	encodeFrame(t, e, frame)
+	frame = cloneRGBA(frame)
+	paintTile(frame, 0, 0, 200)
+	if sd := encodeFrame(t, e, frame); sd.Type != "screen_update" {
+		t.Fatalf("delta kapalıyken tip %s, beklenen screen_update", sd.Type)
+	}
+
+	// Sunucu desteklediğini bildirince önce keyframe, sonra delta gelir
+	e.SetDelta(true)
+	frame = cloneRGBA(frame)
+	paintTile(frame, 1, 1, 40)
+	if sd := encodeFrame(t, e, frame); !sd.Keyframe {
+		t.Fatalf("delta açılınca keyframe bekleniyordu, gelen %s", sd.Type)
+	}
+	frame = cloneRGBA(frame)
+	paintTile(frame, 2, 1, 80)
+	if sd := encodeFrame(t, e, frame); sd.Type != "screen_delta" {
+		t.Fatalf("delta açıkken tip %s, beklenen screen_delta", sd.Type)
+	}
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dns_proxy yöntemi: client localhost'ta bir DNS sunucusu çalıştırır. Engellenen
+// adlar (joker desenler dahil) redirect_to adresine yönlendirilir veya
+// NXDOMAIN ile cevaplanır, diğer sorgular upstream sunuculara iletilir.
+// Sistemin DNS ayarı dns_listen adresini göstermelidir.
+
+const (
+	defaultDNSListen   = "127.0.0.1:53"
+	dnsUpstreamTimeout = 3 * time.Second
+	dnsBlockedTTL      = 60
+	// Firefox bu ad çözülemezse DNS-over-HTTPS'i kendiliğinden kapatır
+	dohCanaryDomain = "use-application-dns.net"
+)
+
+var defaultDNSUpstreams = []string{"1.1.1.1:53", "8.8.8.8:53"}
+
+// hostMatcher alan adlarını engellenen adlarla (tam veya joker) eşleştirir.
+type hostMatcher struct {
+	exact     map[string]blockedHost
+	wildcards []blockedHost
+}
+
+func newHostMatcher(hosts []blockedHost) *hostMatcher {
+	m := &hostMatcher{exact: make(map[string]blockedHost)}
+	for _, h := range hosts {
+		if _, ok := m.exact[h.Host]; !ok {
+			m.exact[h.Host] = h
+		}
+		if h.Wildcard {
+			m.wildcards = append(m.wildcards, h)
+		}
+	}
+	return m
+}
+
+func (m *hostMatcher) match(name string) (blockedHost, bool) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if h, ok := m.exact[name]; ok {
+		return h, true
+	}
+	for _, h := range m.wildcards {
+		if strings.HasSuffix(name, "."+h.Host) {
+			return h, true
+		}
+	}
+	return blockedHost{}, false
+}
+
+type dnsProxy struct {
+	listen    string
+	upstreams []string
+	// Her sorguda güncel config'ten eşleştirme ve yönlendirme adresleri
+	rules     func() (*hostMatcher, net.IP, net.IP)
+	onBlocked func(name string, host blockedHost)
+
+	udp net.PacketConn
+	tcp net.Listener
+}
+
+// normalizeUpstream portu olmayan adrese 53 ekler.
+func normalizeUpstream(addr string) (string, error) {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr, nil
+	}
+	if net.ParseIP(strings.Trim(addr, "[]")) == nil {
+		return "", fmt.Errorf("%q bir IP adresi değil", addr)
+	}
+	return net.JoinHostPort(strings.Trim(addr, "[]"), "53"), nil
+}
+
+// Listen listen adresinde UDP ve TCP soketlerini açar. Port 0 verilirse
+// (testlerde) TCP de UDP'nin aldığı portu kullanır.
+func (p *dnsProxy) Listen() error {
+	udp, err := net.ListenPacket("udp", p.listen)
+	if err != nil {
+		return err
+	}
+	tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+	if err != nil {
+		udp.Close()
+		return err
+	}
+	p.udp, p.tcp = udp, tcp
+	return nil
+}
+
+// Serve sorguları ctx iptal edilene kadar cevaplar; soketler kapanınca döner.
+func (p *dnsProxy) Serve(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		p.udp.Close()
+		p.tcp.Close()
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p.serveUDP(p.udp)
+	}()
+	go func() {
+		defer wg.Done()
+		p.serveTCP(p.tcp)
+	}()
+	wg.Wait()
+}
+
+func (p *dnsProxy) serveUDP(conn net.PacketConn) {
+	for {
+		buf := make([]byte, 4096)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		go func() {
+			if reply := p.handle(buf[:n], false); reply != nil {
+				conn.WriteTo(reply, addr)
+			}
+		}()
+	}
+}
+
+func (p *dnsProxy) serveTCP(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			for {
+				conn.SetDeadline(time.Now().Add(2 * dnsUpstreamTimeout))
+				query, err := readTCPMessage(conn)
+				if err != nil {
+					return
+				}
+				reply := p.handle(query, true)
+				if reply == nil || writeTCPMessage(conn, reply) != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// handle sorguyu cevaplar; cevap verilemiyorsa nil döner.
+func (p *dnsProxy) handle(query []byte, tcp bool) []byte {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil {
+		return nil
+	}
+	question, err := parser.Question()
+	if err != nil || header.Response || header.OpCode != 0 {
+		return p.forwardOrFail(query, header, nil, tcp)
+	}
+
+	name := strings.TrimSuffix(strings.ToLower(question.Name.String()), ".")
+	if name == dohCanaryDomain {
+		return buildDNSReply(header, question, dnsmessage.RCodeNameError, nil)
+	}
+
+	matcher, redirect4, redirect6 := p.rules()
+	if host, ok := matcher.match(name); ok {
+		if p.onBlocked != nil {
+			p.onBlocked(name, host)
+		}
+		return blockedDNSReply(header, question, redirect4, redirect6)
+	}
+	return p.forwardOrFail(query, header, &question, tcp)
+}
+
+func (p *dnsProxy) forwardOrFail(query []byte, header dnsmessage.Header, question *dnsmessage.Question, tcp bool) []byte {
+	reply, err := p.forward(query, header.ID, tcp)
+	if err == nil {
+		return reply
+	}
+	if question == nil {
+		return nil
+	}
+	return buildDNSReply(header, *question, dnsmessage.RCodeServerFailure, nil)
+}
+
+// forward sorguyu upstream'lere sırayla gönderir ve ilk geçerli cevabı döner.
+func (p *dnsProxy) forward(query []byte, id uint16, tcp bool) ([]byte, error) {
+	lastErr := errors.New("upstream DNS sunucusu yok")
+	for _, upstream := range p.upstreams {
+		reply, err := exchangeDNS(upstream, query, tcp)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(reply) < 2 || binary.BigEndian.Uint16(reply) != id {
+			lastErr = fmt.Errorf("%s geçersiz cevap döndü", upstream)
+			continue
+		}
+		return reply, nil
+	}
+	return nil, lastErr
+}
+
+func exchangeDNS(upstream string, query []byte, tcp bool) ([]byte, error) {
+	network := "udp"
+	if tcp {
+		network = "tcp"
+	}
+	conn, err := net.DialTimeout(network, upstream, dnsUpstreamTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dnsUpstreamTimeout))
+
+	if tcp {
+		if err := writeTCPMessage(conn, query); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// TCP üzerinde her DNS mesajının önünde 2 byte uzunluk vardır.
+func readTCPMessage(r io.Reader) ([]byte, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeTCPMessage(w io.Writer, msg []byte) error {
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := w.Write(buf)
+	return err
+}
+
+// blockedDNSReply engellenen ad için cevap üretir: yönlendirme adresi varsa
+// A/AAAA kaydı (diğer tiplerde boş cevap), yoksa NXDOMAIN.
+func blockedDNSReply(header dnsmessage.Header, question dnsmessage.Question, redirect4, redirect6 net.IP) []byte {
+	if redirect4 == nil && redirect6 == nil {
+		return buildDNSReply(header, question, dnsmessage.RCodeNameError, nil)
+	}
+
+	rh := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: dnsBlockedTTL}
+	var answer dnsmessage.ResourceBody
+	switch {
+	case question.Type == dnsmessage.TypeA && redirect4 != nil:
+		var a dnsmessage.AResource
+		copy(a.A[:], redirect4.To4())
+		answer = &a
+	case question.Type == dnsmessage.TypeAAAA && redirect6 != nil:
+		var aaaa dnsmessage.AAAAResource
+		copy(aaaa.AAAA[:], redirect6.To16())
+		answer = &aaaa
+	}
+	if answer == nil {
+		return buildDNSReply(header, question, dnsmessage.RCodeSuccess, nil)
+	}
+	return buildDNSReply(header, question, dnsmessage.RCodeSuccess, &dnsmessage.Resource{Header: rh, Body: answer})
+}
+
+func buildDNSReply(header dnsmessage.Header, question dnsmessage.Question, rcode dnsmessage.RCode, answer *dnsmessage.Resource) []byte {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 header.ID,
+			Response:           true,
+			OpCode:             header.OpCode,
+			RecursionDesired:   header.RecursionDesired,
+			RecursionAvailable: true,
+			RCode:              rcode,
+		},
+		Questions: []dnsmessage.Question{question},
+	}
+	if answer != nil {
+		msg.Answers = []dnsmessage.Resource{*answer}
+	}
+	reply, err := msg.Pack()
+	if err != nil {
+		return nil
+	}
+	return reply
+}
+
+// dnsUpstreams config'teki upstream'leri (yoksa varsayılanları) port ile döner.
+func (cfg *WebsiteBlockerConfig) dnsUpstreams() []string {
+	if len(cfg.Settings.DNSUpstreams) == 0 {
+		return defaultDNSUpstreams
+	}
+	var upstreams []string
+	for _, u := range cfg.Settings.DNSUpstreams {
+		if addr, err := normalizeUpstream(u); err == nil {
+			upstreams = append(upstreams, addr)
+		}
+	}
+	return upstreams
+}
+
+func (cfg *WebsiteBlockerConfig) dnsListen() string {
+	if cfg.Settings.DNSListen == "" {
+		return defaultDNSListen
+	}
+	return cfg.Settings.DNSListen
+}
+
+// redirectIPs dns_proxy cevaplarında kullanılan adresler; redirect_to boşsa
+// ikisi de nil'dir (NXDOMAIN).
+func (cfg *WebsiteBlockerConfig) redirectIPs() (net.IP, net.IP) {
+	ip := net.ParseIP(cfg.Settings.RedirectTo)
+	if ip == nil {
+		return nil, nil
+	}
+	if ip.To4() == nil {
+		return nil, ip
+	}
+	v6 := net.ParseIP(cfg.Settings.RedirectToIPv6)
+	if v6 == nil {
+		v6 = net.ParseIP(defaultRedirectIPv6)
+	}
+	return ip, v6
+}
+
+// dnsProxyKey proxy'nin yeniden başlatılmasını gerektiren ayarlar; dns_proxy
+// yöntemi seçili değilse boştur.
+func (cfg *WebsiteBlockerConfig) dnsProxyKey() string {
+	if cfg.Settings.BlockingMethod != "dns_proxy" {
+		return ""
+	}
+	return cfg.dnsListen() + " " + strings.Join(cfg.dnsUpstreams(), ",")
+}
+
+// runDNSProxy dns_proxy yöntemi seçiliyken yerel DNS sunucusunu çalıştırır.
+// Adres kullanılıyorsa (ör. systemd-resolved) belirli aralıklarla tekrar dener.
+// Dinleme adresi veya upstream'ler değişince StartWebsiteBlocker proxy'yi
+// durdurup yeni config ile tekrar başlatır.
+func (c *Client) runDNSProxy(ctx context.Context, cfg *WebsiteBlockerConfig) {
+	throttle := newEventThrottle(time.Minute)
+	var (
+		rulesMu   sync.Mutex
+		rulesFor  *WebsiteBlockerConfig
+		matcher   *hostMatcher
+		redirect4 net.IP
+		redirect6 net.IP
+	)
+	proxy := &dnsProxy{
+		listen:    cfg.dnsListen(),
+		upstreams: cfg.dnsUpstreams(),
+		rules: func() (*hostMatcher, net.IP, net.IP) {
+			rulesMu.Lock()
+			defer rulesMu.Unlock()
+			// Config değiştiyse eşleştirici yeniden oluşturulur
+			if current := c.websiteBlockerConfig(); current != nil && current != rulesFor {
+				rulesFor = current
+				matcher = newHostMatcher(current.blockedHosts())
+				redirect4, redirect6 = current.redirectIPs()
+			}
+			return matcher, redirect4, redirect6
+		},
+		onBlocked: func(name string, host blockedHost) {
+			// Tarayıcılar aynı adı sık sorar
+			if !throttle.allow(name) {
+				return
+			}
+			log.Printf("🚫 DNS sorgusu engellendi: %s (%s)", name, host.Site)
+			c.emitEvent(BlockerEvent{Type: eventDNSBlocked, Site: host.Site, URL: name})
+		},
+	}
+
+	for {
+		err := proxy.Listen()
+		if err == nil {
+			break
+		}
+		log.Printf("⚠️ DNS proxy %s adresini dinleyemedi: %v", proxy.listen, err)
+		if !sleepCtx(ctx, 30*time.Second) {
+			return
+		}
+	}
+	log.Printf("🌐 DNS proxy başlatıldı: %s (upstream: %s)", proxy.listen, strings.Join(proxy.upstreams, ", "))
+	proxy.Serve(ctx)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var testUpstreamIP = [4]byte{93, 184, 216, 34}
+
+// startTestUpstream her A sorgusunu testUpstreamIP ile cevaplayan bir DNS
+// sunucusu (UDP ve TCP, aynı port) başlatır ve gelen soru adlarını kaydeder.
+func startTestUpstream(t *testing.T) (string, func() []string) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", conn.LocalAddr().String())
+	if err != nil {
+		conn.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		ln.Close()
+	})
+
+	var (
+		mu    sync.Mutex
+		names []string
+	)
+	answer := func(query []byte) []byte {
+		var parser dnsmessage.Parser
+		header, err := parser.Start(query)
+		if err != nil {
+			return nil
+		}
+		question, err := parser.Question()
+		if err != nil {
+			return nil
+		}
+		mu.Lock()
+		names = append(names, question.Name.String())
+		mu.Unlock()
+
+		return buildDNSReply(header, question, dnsmessage.RCodeSuccess, &dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 300},
+			Body:   &dnsmessage.AResource{A: testUpstreamIP},
+		})
+	}
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply := answer(buf[:n]); reply != nil {
+				conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			if query, err := readTCPMessage(c); err == nil {
+				if reply := answer(query); reply != nil {
+					writeTCPMessage(c, reply)
+				}
+			}
+			c.Close()
+		}
+	}()
+
+	return conn.LocalAddr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), names...)
+	}
+}
+
+// startTestProxy verilen config ile 127.0.0.1'de rastgele portta proxy başlatır.
+func startTestProxy(t *testing.T, cfg *WebsiteBlockerConfig, upstream string) (string, func() []string) {
+	t.Helper()
+	var (
+		mu      sync.Mutex
+		blocked []string
+	)
+	matcher := newHostMatcher(cfg.blockedHosts())
+	redirect4, redirect6 := cfg.redirectIPs()
+	proxy := &dnsProxy{
+		listen:    "127.0.0.1:0",
+		upstreams: []string{upstream},
+		rules: func() (*hostMatcher, net.IP, net.IP) {
+			return matcher, redirect4, redirect6
+		},
+		onBlocked: func(name string, host blockedHost) {
+			mu.Lock()
+			blocked = append(blocked, name)
+			mu.Unlock()
+		},
+	}
+	if err := proxy.Listen(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		proxy.Serve(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return proxy.udp.LocalAddr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), blocked...)
+	}
+}
+
+func queryDNS(t *testing.T, network, server, name string, qtype dnsmessage.Type) dnsmessage.Message {
+	t.Helper()
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 0x2a, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.DialTimeout(network, server, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var reply []byte
+	if network == "tcp" {
+		if err := writeTCPMessage(conn, packed); err != nil {
+			t.Fatal(err)
+		}
+		if reply, err = readTCPMessage(conn); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		if _, err := conn.Write(packed); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 4096)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply = buf[:n]
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(reply); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Header.ID != query.Header.ID || !msg.Header.Response {
+		t.Fatalf("%s: geçersiz cevap başlığı %+v", name, msg.Header)
+	}
+	return msg
+}
+
+func answerA(t *testing.T, msg dnsmessage.Message) [4]byte {
+	t.Helper()
+	if msg.Header.RCode != dnsmessage.RCodeSuccess || len(msg.Answers) != 1 {
+		t.Fatalf("tek A cevabı bekleniyordu: rcode %v, %d cevap", msg.Header.RCode, len(msg.Answers))
+	}
+	a, ok := msg.Answers[0].Body.(*dnsmessage.AResource)
+	if !ok {
+		t.Fatalf("A kaydı bekleniyordu: %T", msg.Answers[0].Body)
+	}
+	return a.A
+}
+
+func TestDNSProxy(t *testing.T) {
+	upstream, upstreamNames := startTestUpstream(t)
+
+	cfg := &WebsiteBlockerConfig{BlockedWebsites: []BlockedWebsite{
+		{Name: "YouTube", URLs: []string{"youtube.com"}},
+		{Name: "TikTok", URLs: []string{"*.tiktok.com"}},
+	}}
+	cfg.Settings.RedirectTo = "127.0.0.1"
+	server, blocked := startTestProxy(t, cfg, upstream)
+
+	// Engellenen adlar yönlendirme adresine çözülür
+	for _, name := range []string{"youtube.com.", "WWW.YouTube.com.", "vm.tiktok.com.", "a.b.tiktok.com."} {
+		if got := answerA(t, queryDNS(t, "udp", server, name, dnsmessage.TypeA)); got != [4]byte{127, 0, 0, 1} {
+			t.Errorf("%s: %v, beklenen 127.0.0.1", name, got)
+		}
+	}
+	if names := blocked(); len(names) != 4 {
+		t.Errorf("onBlocked %d kez çağrıldı, beklenen 4: %v", len(names), names)
+	}
+
+	if names := upstreamNames(); len(names) != 0 {
+		t.Errorf("engellenen adlar upstream'e iletildi: %v", names)
+	}
+
+	// İzin verilen adlar upstream'e iletilir (UDP ve TCP); benzer adlar engellenmez
+	for _, q := range []struct{ network, name string }{
+		{"udp", "example.org."},
+		{"udp", "notyoutube.com."},
+		{"tcp", "tiktok.com.evil.test."},
+	} {
+		if got := answerA(t, queryDNS(t, q.network, server, q.name, dnsmessage.TypeA)); got != testUpstreamIP {
+			t.Errorf("%s (%s): %v, beklenen upstream cevabı %v", q.name, q.network, got, testUpstreamIP)
+		}
+	}
+	want := []string{"example.org.", "notyoutube.com.", "tiktok.com.evil.test."}
+	if names := upstreamNames(); len(names) != len(want) {
+		t.Errorf("upstream'e giden sorgular %v, beklenen %v", names, want)
+	}
+	if names := blocked(); len(names) != 4 {
+		t.Errorf("izin verilen adlar için onBlocked çağrıldı: %v", names)
+	}
+}
+
+func TestDNSProxyNXDOMAIN(t *testing.T) {
+	upstream, upstreamNames := startTestUpstream(t)
+
+	// redirect_to yoksa engellenen adlar NXDOMAIN döner
+	cfg := &WebsiteBlockerConfig{BlockedWebsites: []BlockedWebsite{
+		{Name: "YouTube", URLs: []string{"youtube.com"}},
+	}}
+	server, _ := startTestProxy(t, cfg, upstream)
+
+	msg := queryDNS(t, "udp", server, "m.youtube.com.", dnsmessage.TypeA)
+	if msg.Header.RCode != dnsmessage.RCodeNameError || len(msg.Answers) != 0 {
+		t.Errorf("m.youtube.com: rcode %v, %d cevap; beklenen NXDOMAIN", msg.Header.RCode, len(msg.Answers))
+	}
+	if names := upstreamNames(); len(names) != 0 {
+		t.Errorf("engellenen ad upstream'e iletildi: %v", names)
+	}
+
+	if got := answerA(t, queryDNS(t, "udp", server, "example.org.", dnsmessage.TypeA)); got != testUpstreamIP {
+		t.Errorf("example.org: %v, beklenen upstream cevabı %v", got, testUpstreamIP)
+	}
+}
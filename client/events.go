@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Engelleme olayları (tespit, uyarı, kapatma, site engelleme) sunucuya
+// toplu olarak POST /api/events ile gönderilir. Gönderilemeyen olaylar diskteki
+// kuyrukta bekler ve bağlantı gelince sırayla tekrar denenir.
+
+const (
+	eventAppDetected   = "app_detected"
+	eventAppWarned     = "app_warned"
+	eventAppKilled     = "app_killed"
+	eventKillFailed    = "kill_failed"
+	eventSiteBlocked   = "site_blocked"
+	eventHostsTampered = "hosts_tampered"
+	eventDNSBlocked    = "dns_blocked"
+	// Yönlendirilen siteye girilmek istendi, engelleme sayfası gösterildi
+	eventBlockPageVisited = "block_page_visited"
+	// Kullanıcının gizlilik molası
+	eventCapturePaused  = "capture_paused"
+	eventCaptureResumed = "capture_resumed"
+
+	// Art arda gelen olayların tek istekte gitmesi için bekleme
+	eventBatchDelay  = time.Second
+	eventMaxBackoff  = 5 * time.Minute
+	eventRequestWait = 15 * time.Second
+)
+
+type BlockerEvent struct {
+	ID        string `json:"id"` // sunucunun tekrar gönderimleri ayıklaması için
+	Type      string `json:"type"`
+	Timestamp int64  `json:"timestamp"`
+	ClientID  string `json:"clientId"`
+
+	App         string       `json:"app,omitempty"`
+	PIDs        []int        `json:"pids,omitempty"`
+	Warnings    int          `json:"warnings,omitempty"`
+	MaxWarnings int          `json:"maxWarnings,omitempty"`
+	Kill        []KillResult `json:"kill,omitempty"`
+
+	Site    string `json:"site,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Browser string `json:"browser,omitempty"`
+
+	Reason          string `json:"reason,omitempty"`
+	DurationSeconds int    `json:"durationSeconds,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
+
+type eventQueue struct {
+	mu       sync.Mutex
+	settings EventSettings
+	events   []BlockerEvent
+	counter  uint64
+	notify   chan struct{}
+	dirty    bool // diske yazılmamış değişiklik var
+}
+
+func openEventQueue(settings EventSettings) *eventQueue {
+	q := &eventQueue{
+		settings: settings,
+		notify:   make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(settings.QueueFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Olay kuyruğu okunamadı: %v", err)
+		}
+		return q
+	}
+	if err := json.Unmarshal(data, &q.events); err != nil {
+		log.Printf("⚠️ Olay kuyruğu bozuk, atlandı: %v", err)
+		q.events = nil
+	}
+	if len(q.events) > 0 {
+		log.Printf("📨 Gönderilmeyi bekleyen %d olay var", len(q.events))
+	}
+	return q
+}
+
+// add olayı bellekteki kuyruğa ekler. Disk her olayda değil, gönderici
+// döngüsünde persist ile toplu yazılır.
+func (q *eventQueue) add(ev BlockerEvent) {
+	q.mu.Lock()
+	q.counter++
+	ev.ID = fmt.Sprintf("%x-%x", time.Now().UnixNano(), q.counter)
+	q.events = append(q.events, ev)
+	if over := len(q.events) - q.settings.MaxQueued; over > 0 {
+		q.events = append([]BlockerEvent(nil), q.events[over:]...)
+	}
+	q.dirty = true
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// peek gönderilecek en eski olayları döner (kuyruktan çıkarmaz).
+func (q *eventQueue) peek() []BlockerEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := min(len(q.events), q.settings.BatchSize)
+	return append([]BlockerEvent(nil), q.events[:n]...)
+}
+
+// remove gönderilen olayları ID ile kuyruktan çıkarır. Gönderim sırasında
+// kuyruk taşıp eski olaylar atılmış olabileceği için sıra varsayılmaz.
+func (q *eventQueue) remove(sent []BlockerEvent) {
+	ids := make(map[string]bool, len(sent))
+	for _, ev := range sent {
+		ids[ev.ID] = true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	kept := q.events[:0]
+	for _, ev := range q.events {
+		if !ids[ev.ID] {
+			kept = append(kept, ev)
+		}
+	}
+	q.events = kept
+	q.dirty = true
+}
+
+// persist son yazımdan beri değişen kuyruğu diske yazar. Sadece gönderici
+// döngüsünden çağrılır; yazma sırasında kilit tutulmaz, böylece engelleyiciler
+// olay eklerken fsync beklemez.
+func (q *eventQueue) persist() {
+	q.mu.Lock()
+	if !q.dirty {
+		q.mu.Unlock()
+		return
+	}
+	q.dirty = false
+	var data []byte
+	if len(q.events) > 0 {
+		data, _ = json.Marshal(q.events)
+	}
+	q.mu.Unlock()
+
+	var err error
+	if data == nil {
+		if err = os.Remove(q.settings.QueueFile); os.IsNotExist(err) {
+			err = nil
+		}
+	} else {
+		err = writeFileAtomic(q.settings.QueueFile, data, 0600)
+	}
+	if err != nil {
+		log.Printf("⚠️ Olay kuyruğu yazılamadı: %v", err)
+		q.mu.Lock()
+		q.dirty = true // bir sonraki turda tekrar denenir
+		q.mu.Unlock()
+	}
+}
+
+// eventThrottle aynı anahtar için olayları aralık başına bire indirir.
+type eventThrottle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+func newEventThrottle(interval time.Duration) *eventThrottle {
+	return &eventThrottle{interval: interval, last: make(map[string]time.Time)}
+}
+
+func (t *eventThrottle) allow(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := t.last[key]; ok && now.Sub(last) < t.interval {
+		return false
+	}
+	t.last[key] = now
+	if len(t.last) > 1000 {
+		for k, last := range t.last {
+			if now.Sub(last) >= t.interval {
+				delete(t.last, k)
+			}
+		}
+	}
+	return true
+}
+
+// emitEvent olayı kuyruğa ekler; gönderim runEventSender'da yapılır.
+func (c *Client) emitEvent(ev BlockerEvent) {
+	if c.events == nil {
+		return
+	}
+	ev.ClientID = c.clientID
+	if ev.Timestamp == 0 {
+		ev.Timestamp = c.now().Unix()
+	}
+	c.events.add(ev)
+}
+
+// emitKillEvent kapatma sonuçlarını app_killed / kill_failed olarak raporlar.
+func (c *Client) emitKillEvent(app BlockedApp, results []KillResult) {
+	if len(results) == 0 {
+		return
+	}
+	ev := BlockerEvent{Type: eventAppKilled, App: app.Name, Kill: results}
+	var failed []string
+	for _, r := range results {
+		ev.PIDs = append(ev.PIDs, r.PID)
+		if !r.Success() {
+			failed = append(failed, fmt.Sprintf("%d: %s", r.PID, r.Error))
+		}
+	}
+	if len(failed) > 0 {
+		ev.Type = eventKillFailed
+		ev.Message = strings.Join(failed, "; ")
+	}
+	c.emitEvent(ev)
+}
+
+// runEventSender kuyruktaki olayları toplu olarak gönderir. Hata durumunda
+// bekleme süresi ikiye katlanarak (en fazla eventMaxBackoff) tekrar denenir.
+// Kuyruk her turda (ve çıkışta) bir kez diske yazılır.
+func (c *Client) runEventSender(ctx context.Context) {
+	interval := time.Duration(c.config.Events.FlushIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer c.events.persist()
+
+	backoff := interval
+	var retryAt time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.events.notify:
+			if !sleepCtx(ctx, eventBatchDelay) {
+				return
+			}
+		}
+
+		// Gönderim denenmeden önce yeni olaylar diske alınır
+		c.events.persist()
+		if !c.online() || time.Now().Before(retryAt) {
+			continue
+		}
+
+		err := c.flushEvents(ctx)
+		c.events.persist()
+		if err == nil {
+			backoff = interval
+			retryAt = time.Time{}
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("⚠️ Olaylar gönderilemedi, %s sonra tekrar denenecek: %v", backoff, err)
+		retryAt = time.Now().Add(backoff)
+		if backoff *= 2; backoff > eventMaxBackoff {
+			backoff = eventMaxBackoff
+		}
+	}
+}
+
+func (c *Client) flushEvents(ctx context.Context) error {
+	for ctx.Err() == nil {
+		batch := c.events.peek()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := c.postEvents(ctx, batch); err != nil {
+			var statusErr *httpStatusError
+			if errors.As(err, &statusErr) && statusErr.permanent() {
+				// Aynı paketi tekrar göndermek kuyruğu kilitler
+				log.Printf("⚠️ Sunucu %d olayı reddetti, atlandı: %v", len(batch), err)
+				c.events.remove(batch)
+				continue
+			}
+			return err
+		}
+		c.events.remove(batch)
+	}
+	return ctx.Err()
+}
+
+func (c *Client) postEvents(ctx context.Context, batch []BlockerEvent) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"clientId": c.clientID,
+		"events":   batch,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, eventRequestWait)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.serverURL+"/api/events", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &httpStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return nil
+}
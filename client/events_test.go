@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEventQueuePersistCoalesced(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "event_queue.json")
+	settings := EventSettings{QueueFile: file, MaxQueued: 3, BatchSize: 10}
+	q := openEventQueue(settings)
+
+	for i := 0; i < 5; i++ {
+		q.add(BlockerEvent{Type: eventAppDetected, App: "a"})
+	}
+	// Olay eklemek diske yazmaz
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("persist öncesi kuyruk dosyası yazılmış: %v", err)
+	}
+
+	q.persist()
+	reopened := openEventQueue(settings)
+	if got := len(reopened.events); got != 3 {
+		t.Fatalf("yeniden açılan kuyrukta %d olay, beklenen 3 (max_queued)", got)
+	}
+
+	// Gönderilen olaylar kaldırılınca dosya silinir
+	q.remove(q.peek())
+	q.persist()
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("boş kuyruk dosyası silinmedi: %v", err)
+	}
+}
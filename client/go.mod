@@ -4,4 +4,7 @@ go 1.21
 
 require github.com/gorilla/websocket v1.5.1
 
-require golang.org/x/net v0.17.0 // indirect
+require (
+	golang.org/x/net v0.17.0
+	golang.org/x/text v0.13.0 // indirect
+)
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// Hosts dosyasında client'a ait tek bir bölüm vardır. Bölüm başlangıç ve
+// bitiş satırlarıyla ayrılır, her yazımda baştan oluşturulur ve dosyanın geri
+// kalanına dokunulmaz. İçerik değişmediyse dosya yazılmaz; yazım geçici dosya
+// + rename ile yapılır, yarım kalmış bir hosts dosyası oluşmaz.
+
+const (
+	hostsSectionBegin = "# BEGIN screen recorder client - otomatik oluşturuldu, elle düzenlemeyin"
+	hostsSectionEnd   = "# END screen recorder client"
+
+	// Eski sürümlerin (sudo echo >> /etc/hosts) eklediği bölümler de client'a
+	// aittir; okunurken bölüme dahil edilir ve ilk yazımda temizlenir
+	legacyHostsSectionBegin = "# Blocked websites by screen recorder client"
+	legacyHostsSectionEnd   = "# End blocked websites"
+)
+
+type hostsEntry struct {
+	IP   string
+	Host string
+	Site string // bölümde yorum satırı olarak yazılır
+}
+
+type hostsFile struct {
+	path string
+}
+
+func defaultHostsPath() string {
+	if runtime.GOOS == "windows" {
+		return os.Getenv("SystemRoot") + `\System32\drivers\etc\hosts`
+	}
+	return "/etc/hosts"
+}
+
+func newHostsFile(path string) *hostsFile {
+	if path == "" {
+		path = defaultHostsPath()
+	}
+	return &hostsFile{path: path}
+}
+
+// hostsContent hosts dosyasının client bölümü dışındaki kısmı ve bölümdeki kayıtlar.
+type hostsContent struct {
+	rest     []string // bölüm satırları çıkarılmış dosya
+	entries  []hostsEntry
+	sections int // bulunan bölüm sayısı (eski sürümlerden kalan kopyalar dahil)
+	crlf     bool
+}
+
+func parseHosts(data string) hostsContent {
+	var hc hostsContent
+	hc.crlf = strings.Contains(data, "\r\n")
+
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var (
+		end     string   // içinde bulunulan bölümün bitiş satırı
+		section []string // bölümün ham satırları
+		entries []hostsEntry
+		removed bool // önceki satır bir bölümün sonuydu
+	)
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == hostsSectionBegin || trimmed == legacyHostsSectionBegin:
+			// Önceki başlangıcın bitişi yoksa o satırlar client'ın değildir
+			if end != "" {
+				hc.rest = append(hc.rest, section...)
+			}
+			end = hostsSectionEnd
+			if trimmed == legacyHostsSectionBegin {
+				end = legacyHostsSectionEnd
+			}
+			section = []string{line}
+			entries = nil
+		case end != "" && trimmed == end:
+			end = ""
+			hc.sections++
+			hc.entries = append(hc.entries, entries...)
+			removed = true
+			continue
+		case end != "":
+			section = append(section, line)
+			if entry, ok := parseHostsLine(trimmed); ok {
+				entries = append(entries, entry...)
+			}
+		case removed && trimmed == "" && (len(hc.rest) == 0 || strings.TrimSpace(hc.rest[len(hc.rest)-1]) == ""):
+			// Kaldırılan bölümün iki yanındaki boş satırlardan biri yeterli
+		default:
+			hc.rest = append(hc.rest, line)
+		}
+		removed = false
+	}
+	// Bitişi olmayan bölüm client'ın sayılmaz (yazım yarıda kalmış veya
+	// işaret elle silinmiş); dosyanın geri kalanını silmemek için olduğu gibi bırakılır
+	if end != "" {
+		hc.rest = append(hc.rest, section...)
+	}
+	return hc
+}
+
+// parseHostsLine "IP host1 host2 # yorum" satırını kayıtlara ayırır.
+func parseHostsLine(line string) ([]hostsEntry, bool) {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return nil, false
+	}
+	entries := make([]hostsEntry, 0, len(fields)-1)
+	for _, host := range fields[1:] {
+		entries = append(entries, hostsEntry{IP: fields[0], Host: strings.ToLower(host)})
+	}
+	return entries, true
+}
+
+// render bölümü verilen kayıtlarla yeniden oluşturur. Kayıt yoksa bölüm yazılmaz.
+func (hc hostsContent) render(entries []hostsEntry) string {
+	lines := append([]string(nil), hc.rest...)
+	if len(entries) > 0 || hc.sections > 0 {
+		// Bölümden önce bıraktığımız boş satır bölümle birlikte gider
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+	}
+	if len(entries) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, hostsSectionBegin)
+		site := ""
+		for _, e := range entries {
+			if e.Site != site && e.Site != "" {
+				lines = append(lines, "# "+e.Site)
+			}
+			site = e.Site
+			lines = append(lines, e.IP+" "+e.Host)
+		}
+		lines = append(lines, hostsSectionEnd)
+	}
+
+	newline := "\n"
+	if hc.crlf {
+		newline = "\r\n"
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, newline) + newline
+}
+
+func (h *hostsFile) read() (hostsContent, string, error) {
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return hostsContent{}, "", err
+	}
+	return parseHosts(string(data)), string(data), nil
+}
+
+// Entries client bölümündeki kayıtları döner.
+func (h *hostsFile) Entries() ([]hostsEntry, error) {
+	hc, _, err := h.read()
+	return hc.entries, err
+}
+
+// Apply client bölümünü verilen kayıtlarla değiştirir. Dosya zaten
+// istenen haldeyse yazmaz ve false döner.
+func (h *hostsFile) Apply(entries []hostsEntry) (bool, error) {
+	hc, current, err := h.read()
+	if err != nil {
+		return false, err
+	}
+	// Yazılacak bölüm yoksa dosya yeniden oluşturulmaz (satır sonları vb. değişmez)
+	if len(entries) == 0 && hc.sections == 0 {
+		return false, nil
+	}
+	updated := hc.render(entries)
+	if updated == current {
+		return false, nil
+	}
+	if hc.sections > 1 && len(entries) > 0 {
+		log.Printf("🧹 Hosts dosyasındaki %d tekrar eden engelleme bölümü birleştirildi", hc.sections-1)
+	}
+	return true, h.write([]byte(updated))
+}
+
+// Remove client bölümünü (eski sürümlerden kalanlar dahil) kaldırır; bölüm
+// yoksa dosyaya dokunmaz ve false döner.
+func (h *hostsFile) Remove() (bool, error) {
+	return h.Apply(nil)
+}
+
+// write dosyayı atomik olarak değiştirir. Yetki yoksa (client root değilse)
+// aynı işlem sudo ile yapılır.
+func (h *hostsFile) write(data []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(h.path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	err := writeFileAtomic(h.path, data, perm)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, syscall.EBUSY):
+		// Container'larda /etc/hosts bind mount'tur, yerine rename edilemez
+		return os.WriteFile(h.path, data, perm)
+	case errors.Is(err, os.ErrPermission) && runtime.GOOS != "windows":
+		return h.writeWithSudo(data, perm)
+	}
+	return err
+}
+
+func (h *hostsFile) writeWithSudo(data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp("", "hosts-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Dosya adları komuta gömülmez, argüman olarak geçilir
+	script := `cat "$1" > "$2.tmp" && chmod "$3" "$2.tmp" && mv -f "$2.tmp" "$2"`
+	cmd := exec.Command("sudo", "sh", "-c", script, "sh", tmp.Name(), h.path, fmt.Sprintf("%o", perm))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("sudo ile yazılamadı: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// runUnblock client'ın hosts bölümünü kaldırır (ör. client çöktükten sonra).
+func runUnblock(args []string) int {
+	path := ""
+	if data, err := os.ReadFile(websiteBlockerConfigFile); err == nil {
+		if cfg, _ := parseWebsiteBlockerConfig(data); cfg != nil {
+			path = cfg.Settings.HostsFile
+		}
+	}
+
+	fs := flag.NewFlagSet("unblock", flag.ContinueOnError)
+	hostsPath := fs.String("hosts", newHostsFile(path).path, "hosts dosyası")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	removed, err := newHostsFile(*hostsPath).Remove()
+	if err != nil {
+		fmt.Printf("❌ %s güncellenemedi: %v\n", *hostsPath, err)
+		return 1
+	}
+	if !removed {
+		fmt.Printf("ℹ️ %s içinde engelleme bölümü yok\n", *hostsPath)
+		return 0
+	}
+	fmt.Printf("✅ Engelleme bölümü %s dosyasından kaldırıldı\n", *hostsPath)
+
+	// Çalışan client website engelleyici açıksa bölümü bir sonraki kontrolde geri yazar
+	if data, err := os.ReadFile(loadClientConfig().Status.File); err == nil {
+		var st ClientStatus
+		if json.Unmarshal(data, &st) == nil && st.WebsiteBlocker && processAlive(st.PID) {
+			fmt.Printf("⚠️ Client çalışıyor (pid %d), website engelleme açık olduğu için bölüm tekrar eklenecek\n", st.PID)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testHostsEntries = []hostsEntry{
+	{IP: "0.0.0.0", Host: "example.com", Site: "example.com"},
+	{IP: "0.0.0.0", Host: "www.example.com", Site: "example.com"},
+	{IP: "0.0.0.0", Host: "video.test", Site: "video.test"},
+}
+
+// testSection testHostsEntries için beklenen bölümü verilen satır sonuyla döner.
+func testSection(newline string) string {
+	return strings.Join([]string{
+		hostsSectionBegin,
+		"# example.com",
+		"0.0.0.0 example.com",
+		"0.0.0.0 www.example.com",
+		"# video.test",
+		"0.0.0.0 video.test",
+		hostsSectionEnd,
+	}, newline) + newline
+}
+
+func writeTestHosts(t *testing.T, content string) *hostsFile {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return newHostsFile(path)
+}
+
+func readTestHosts(t *testing.T, h *hostsFile) string {
+	t.Helper()
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestHostsApply(t *testing.T) {
+	section := testSection("\n")
+	oldSection := hostsSectionBegin + "\n0.0.0.0 old.test\n" + hostsSectionEnd + "\n"
+
+	tests := []struct {
+		name    string
+		initial string
+		want    string
+	}{
+		{
+			name:    "boş dosya",
+			initial: "",
+			want:    section,
+		},
+		{
+			name:    "yeni bölüm",
+			initial: "127.0.0.1 localhost\n",
+			want:    "127.0.0.1 localhost\n\n" + section,
+		},
+		{
+			name:    "bölüm zaten güncel",
+			initial: "127.0.0.1 localhost\n\n" + section,
+			want:    "127.0.0.1 localhost\n\n" + section,
+		},
+		{
+			name:    "eski kayıtlar değiştirilir",
+			initial: "127.0.0.1 localhost\n\n" + oldSection,
+			want:    "127.0.0.1 localhost\n\n" + section,
+		},
+		{
+			name:    "tekrar eden bölümler birleştirilir",
+			initial: "127.0.0.1 localhost\n\n" + oldSection + "::1 ip6-localhost\n\n" + oldSection,
+			want:    "127.0.0.1 localhost\n\n::1 ip6-localhost\n\n" + section,
+		},
+		{
+			name: "eski sürüm bölümü temizlenir",
+			initial: "127.0.0.1 localhost\n\n" + legacyHostsSectionBegin + "\n0.0.0.0 example.com\n" +
+				legacyHostsSectionEnd + "\n",
+			want: "127.0.0.1 localhost\n\n" + section,
+		},
+		{
+			name:    "CRLF korunur",
+			initial: "127.0.0.1 localhost\r\n\r\n" + strings.ReplaceAll(oldSection, "\n", "\r\n"),
+			want:    "127.0.0.1 localhost\r\n\r\n" + testSection("\r\n"),
+		},
+		{
+			// Bitişi olmayan işaretten sonraki satırlar kullanıcınındır, silinmez
+			name:    "bitişi olmayan başlangıç",
+			initial: "127.0.0.1 localhost\n" + hostsSectionBegin + "\n10.0.0.1 nas.local\n",
+			want:    "127.0.0.1 localhost\n" + hostsSectionBegin + "\n10.0.0.1 nas.local\n\n" + section,
+		},
+		{
+			name: "bölüm dışı içerik korunur",
+			initial: "# kullanıcı notu\n127.0.0.1\tlocalhost   \n\n10.0.0.1 nas.local # yorum\n\n" +
+				oldSection + "\n192.168.1.5\tprinter\n",
+			want: "# kullanıcı notu\n127.0.0.1\tlocalhost   \n\n10.0.0.1 nas.local # yorum\n\n" +
+				"192.168.1.5\tprinter\n\n" + section,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := writeTestHosts(t, tt.initial)
+
+			changed, err := h.Apply(testHostsEntries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wantChanged := tt.want != tt.initial; changed != wantChanged {
+				t.Errorf("Apply değişti = %v, beklenen %v", changed, wantChanged)
+			}
+			if got := readTestHosts(t, h); got != tt.want {
+				t.Fatalf("dosya:\n%q\nbeklenen:\n%q", got, tt.want)
+			}
+
+			// Tekrar uygulamak dosyaya dokunmaz
+			changed, err = h.Apply(testHostsEntries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed {
+				t.Error("ikinci Apply dosyayı değiştirdi")
+			}
+			if got := readTestHosts(t, h); got != tt.want {
+				t.Fatalf("ikinci Apply sonrası dosya:\n%q\nbeklenen:\n%q", got, tt.want)
+			}
+
+			entries, err := h.Entries()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]hostsEntry, len(testHostsEntries))
+			for i, e := range testHostsEntries {
+				want[i] = hostsEntry{IP: e.IP, Host: e.Host}
+			}
+			if !reflect.DeepEqual(entries, want) {
+				t.Errorf("Entries = %v, beklenen %v", entries, want)
+			}
+		})
+	}
+}
+
+func TestHostsRemove(t *testing.T) {
+	section := testSection("\n")
+
+	tests := []struct {
+		name    string
+		initial string
+		want    string
+	}{
+		{
+			name:    "bölüm kaldırılır",
+			initial: "127.0.0.1 localhost\n\n" + section,
+			want:    "127.0.0.1 localhost\n",
+		},
+		{
+			name: "eski sürüm ve yeni bölüm birlikte kaldırılır",
+			initial: "127.0.0.1 localhost\n\n" + legacyHostsSectionBegin + "\n0.0.0.0 example.com\n" +
+				legacyHostsSectionEnd + "\n\n" + section,
+			want: "127.0.0.1 localhost\n",
+		},
+		{
+			name:    "CRLF",
+			initial: "127.0.0.1 localhost\r\n\r\n" + testSection("\r\n") + "::1 ip6-localhost\r\n",
+			want:    "127.0.0.1 localhost\r\n\r\n::1 ip6-localhost\r\n",
+		},
+		{
+			name:    "bölüm yoksa dosyaya dokunulmaz",
+			initial: "127.0.0.1 localhost\n\n\n# son satırda satır sonu yok",
+			want:    "127.0.0.1 localhost\n\n\n# son satırda satır sonu yok",
+		},
+		{
+			name:    "bitişi olmayan başlangıç kaldırılmaz",
+			initial: "127.0.0.1 localhost\n" + hostsSectionBegin + "\n10.0.0.1 nas.local\n",
+			want:    "127.0.0.1 localhost\n" + hostsSectionBegin + "\n10.0.0.1 nas.local\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := writeTestHosts(t, tt.initial)
+			removed, err := h.Remove()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wantRemoved := tt.want != tt.initial; removed != wantRemoved {
+				t.Errorf("Remove kaldırdı = %v, beklenen %v", removed, wantRemoved)
+			}
+			if got := readTestHosts(t, h); got != tt.want {
+				t.Fatalf("dosya:\n%q\nbeklenen:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Bölüm yazıldıktan sonra kullanıcının dosyada yaptığı değişiklikler unblock ile kaybolmaz.
+func TestUnblockKeepsUserEdits(t *testing.T) {
+	h := writeTestHosts(t, "127.0.0.1 localhost\n")
+	if _, err := h.Apply(testHostsEntries); err != nil {
+		t.Fatal(err)
+	}
+
+	// Kullanıcı bölümün önüne ve arkasına kendi kayıtlarını ekler
+	edited := strings.Replace(readTestHosts(t, h), "127.0.0.1 localhost\n",
+		"127.0.0.1 localhost\n10.0.0.1\tnas.local  # ev sunucusu\n", 1)
+	edited += "\n192.168.1.5 printer\n"
+	if err := os.WriteFile(h.path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runUnblock([]string{"-hosts", h.path}); code != 0 {
+		t.Fatalf("unblock çıkış kodu %d", code)
+	}
+	want := "127.0.0.1 localhost\n10.0.0.1\tnas.local  # ev sunucusu\n\n192.168.1.5 printer\n"
+	if got := readTestHosts(t, h); got != want {
+		t.Fatalf("dosya:\n%q\nbeklenen:\n%q", got, want)
+	}
+
+	// Bölüm kalmadığı için tekrar çalıştırmak dosyaya dokunmaz
+	if code := runUnblock([]string{"-hosts", h.path}); code != 0 {
+		t.Fatalf("ikinci unblock çıkış kodu %d", code)
+	}
+	if got := readTestHosts(t, h); got != want {
+		t.Fatalf("ikinci unblock sonrası dosya:\n%q\nbeklenen:\n%q", got, want)
+	}
+}
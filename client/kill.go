@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Engellenen uygulamalar process adı deseniyle (pkill -f) değil, eşleştiricinin
+// bulduğu PID'ler hedeflenerek kapatılır: önce nazik sinyal (SIGTERM /
+// taskkill), süre dolunca zorla (SIGKILL / taskkill /F). İstenirse alt
+// process'ler de kapatılır. Her PID için sonuç çağırana döner.
+
+const (
+	killOutcomeTerminated    = "terminated"     // nazik sinyalle kapandı
+	killOutcomeKilled        = "killed"         // zorla kapatıldı
+	killOutcomeAlreadyExited = "already_exited" // sinyalden önce kapanmıştı
+	killOutcomeFailed        = "failed"
+	killOutcomeSkipped       = "skipped" // korunan process
+
+	defaultKillGraceSeconds = 5
+	killPollInterval        = 100 * time.Millisecond
+	killForceWait           = 2 * time.Second
+)
+
+var errProcessGone = errors.New("process bulunamadı")
+
+type KillResult struct {
+	PID     int    `json:"pid"`
+	Name    string `json:"name"`
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (r KillResult) Success() bool {
+	switch r.Outcome {
+	case killOutcomeTerminated, killOutcomeKilled, killOutcomeAlreadyExited:
+		return true
+	}
+	return false
+}
+
+// killSucceeded tüm hedefler kapandıysa true döner.
+func killSucceeded(results []KillResult) bool {
+	for _, r := range results {
+		if !r.Success() {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
+type killOptions struct {
+	// SIGTERM sonrası SIGKILL'e kadar beklenecek süre
+	Grace time.Duration
+	// Alt process'leri de kapat
+	Tree bool
+}
+
+// terminateProcesses hedef process'leri kapatır ve her PID için sonucu döner.
+func terminateProcesses(targets []Process, opts killOptions) []KillResult {
+	if opts.Tree {
+		targets = withDescendants(targets)
+	}
+
+	results := make([]KillResult, len(targets))
+	var pending []int // nazik sinyal gönderilenlerin indeksleri
+
+	self := os.Getpid()
+	for i, p := range targets {
+		results[i] = KillResult{PID: p.PID, Name: p.Name}
+
+		if p.PID <= 1 || p.PID == self {
+			results[i].Outcome = killOutcomeSkipped
+			results[i].Error = "korunan process"
+			continue
+		}
+		// PID başka bir process'e geçmiş olabilir
+		if !sameProcess(p) {
+			results[i].Outcome = killOutcomeAlreadyExited
+			continue
+		}
+
+		if err := signalProcess(p.PID, false, opts.Tree); err != nil {
+			if errors.Is(err, errProcessGone) {
+				results[i].Outcome = killOutcomeAlreadyExited
+			} else {
+				results[i].Outcome = killOutcomeFailed
+				results[i].Error = err.Error()
+			}
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	pending = waitForExit(targets, results, pending, opts.Grace, killOutcomeTerminated)
+	if len(pending) == 0 {
+		return results
+	}
+
+	// Süre doldu, zorla kapat
+	var forced []int
+	for _, i := range pending {
+		if err := signalProcess(targets[i].PID, true, opts.Tree); err != nil {
+			if errors.Is(err, errProcessGone) {
+				results[i].Outcome = killOutcomeTerminated
+			} else {
+				results[i].Outcome = killOutcomeFailed
+				results[i].Error = err.Error()
+			}
+			continue
+		}
+		forced = append(forced, i)
+	}
+
+	for _, i := range waitForExit(targets, results, forced, killForceWait, killOutcomeKilled) {
+		results[i].Outcome = killOutcomeFailed
+		results[i].Error = fmt.Sprintf("zorla kapatmadan %s sonra hâlâ çalışıyor", killForceWait)
+	}
+	return results
+}
+
+// waitForExit process'lerin kapanmasını en fazla timeout kadar bekler,
+// kapananları outcome ile işaretler ve hâlâ çalışanları döner.
+func waitForExit(targets []Process, results []KillResult, pending []int, timeout time.Duration, outcome string) []int {
+	deadline := time.Now().Add(timeout)
+	for {
+		alive := pending[:0]
+		for _, i := range pending {
+			if processAlive(targets[i].PID) {
+				alive = append(alive, i)
+			} else {
+				results[i].Outcome = outcome
+			}
+		}
+		pending = alive
+
+		if len(pending) == 0 || time.Now().After(deadline) {
+			return pending
+		}
+		time.Sleep(killPollInterval)
+	}
+}
+
+// withDescendants hedeflere tüm alt process'leri ekler. Alt process'ler önce
+// gelir, böylece ebeveyn kapanınca yeniden başlatılmaları engellenir.
+func withDescendants(targets []Process) []Process {
+	all, err := listProcesses()
+	if err != nil {
+		return targets
+	}
+
+	children := make(map[int][]Process)
+	for _, p := range all {
+		if p.PPID > 0 {
+			children[p.PPID] = append(children[p.PPID], p)
+		}
+	}
+
+	seen := make(map[int]bool)
+	var ordered []Process
+	var visit func(p Process)
+	visit = func(p Process) {
+		if seen[p.PID] {
+			return
+		}
+		seen[p.PID] = true
+		for _, child := range children[p.PID] {
+			visit(child)
+		}
+		ordered = append(ordered, p)
+	}
+	for _, p := range targets {
+		visit(p)
+	}
+	return ordered
+}
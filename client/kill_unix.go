@@ -0,0 +1,22 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+// signalProcess SIGTERM (force ise SIGKILL) gönderir. Alt process'ler
+// withDescendants ile ayrıca hedeflendiği için tree burada kullanılmaz.
+func signalProcess(pid int, force, tree bool) error {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	err := syscall.Kill(pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return errProcessGone
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+//go:build !windows
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// startTestChild script'i çalıştıran bir alt process başlatır ve "ready"
+// satırını bekler. Process testin sonunda öldürülür ve biriktirilir; zombi
+// kalmasın diye Wait arka planda hemen çağrılır.
+func startTestChild(t *testing.T, script string) Process {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", script)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "ready\n" {
+		cmd.Process.Kill()
+		cmd.Wait()
+		t.Fatalf("alt process hazır değil: %q, %v", line, err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		<-exited
+	})
+	return Process{PID: cmd.Process.Pid, Name: "sh"}
+}
+
+func TestTerminateProcesses(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		outcome string
+		minWait time.Duration
+	}{
+		{
+			name:    "SIGTERM ile kapanan",
+			script:  "echo ready; while :; do sleep 0.05; done",
+			outcome: killOutcomeTerminated,
+		},
+		{
+			// SIGTERM yok sayılır, süre dolunca SIGKILL gönderilir
+			name:    "SIGTERM'i yok sayan",
+			script:  "trap '' TERM; echo ready; while :; do sleep 0.05; done",
+			outcome: killOutcomeKilled,
+			minWait: 300 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		child := startTestChild(t, tt.script)
+
+		start := time.Now()
+		results := terminateProcesses([]Process{child}, killOptions{Grace: 300 * time.Millisecond})
+		elapsed := time.Since(start)
+
+		if len(results) != 1 {
+			t.Fatalf("%s: %d sonuç, beklenen 1", tt.name, len(results))
+		}
+		r := results[0]
+		if r.PID != child.PID || r.Name != child.Name {
+			t.Errorf("%s: sonuç %+v, pid %d bekleniyordu", tt.name, r, child.PID)
+		}
+		if r.Outcome != tt.outcome || !r.Success() {
+			t.Errorf("%s: sonuç %q (%s), beklenen %q", tt.name, r.Outcome, r.Error, tt.outcome)
+		}
+		if elapsed < tt.minWait {
+			t.Errorf("%s: zorla kapatmadan önce %s beklenmeli, %s beklendi", tt.name, tt.minWait, elapsed)
+		}
+		if processAlive(child.PID) {
+			t.Errorf("%s: process hâlâ çalışıyor", tt.name)
+		}
+	}
+}
+
+func TestTerminateProcessesPerPIDResults(t *testing.T) {
+	stubborn := startTestChild(t, "trap '' TERM; echo ready; while :; do sleep 0.05; done")
+	polite := startTestChild(t, "echo ready; while :; do sleep 0.05; done")
+
+	// Biriktirilmiş bir process'in PID'i artık yoktur
+	gone := exec.Command("true")
+	if err := gone.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []Process{
+		stubborn,
+		{PID: os.Getpid(), Name: "self"},
+		polite,
+		{PID: gone.Process.Pid, Name: "true"},
+		{PID: 1, Name: "init"},
+	}
+	results := terminateProcesses(targets, killOptions{Grace: 200 * time.Millisecond})
+
+	want := []string{killOutcomeKilled, killOutcomeSkipped, killOutcomeTerminated, killOutcomeAlreadyExited, killOutcomeSkipped}
+	if len(results) != len(want) {
+		t.Fatalf("%d sonuç, beklenen %d", len(results), len(want))
+	}
+	for i, r := range results {
+		if r.PID != targets[i].PID || r.Outcome != want[i] {
+			t.Errorf("pid %d: sonuç %q (%s), beklenen pid %d %q", r.PID, r.Outcome, r.Error, targets[i].PID, want[i])
+		}
+	}
+	if killSucceeded(results) {
+		t.Error("korunan process'ler varken killSucceeded true dönmemeli")
+	}
+	if !killSucceeded(results[2:4]) {
+		t.Errorf("killSucceeded(%v) false", results[2:4])
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// signalProcess taskkill ile process'i kapatır: /F olmadan pencereye kapatma
+// mesajı gönderilir, force ise zorla sonlandırılır. tree ise /T ile alt
+// process'ler de kapatılır (tasklist PPID vermediği için).
+func signalProcess(pid int, force, tree bool) error {
+	args := []string{"/PID", strconv.Itoa(pid)}
+	if tree {
+		args = append(args, "/T")
+	}
+	if force {
+		args = append(args, "/F")
+	}
+
+	output, err := exec.Command("taskkill", args...).CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// 128: process bulunamadı
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 128 {
+			return errProcessGone
+		}
+		return fmt.Errorf("taskkill: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func processAlive(pid int) bool {
+	output, err := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/fo", "csv", "/nh").Output()
+	if err != nil {
+		return true
+	}
+	return strings.Contains(string(output), fmt.Sprintf("\"%d\"", pid))
+}
+
+// tasklist başlangıç zamanı vermediği için PID yeniden kullanımı kontrol edilemiyor
+func sameProcess(p Process) bool {
+	return processAlive(p.PID)
+}
@@ -2,44 +2,67 @@ package main
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"fmt"
 	"image"
-	"image/jpeg"
 	_ "image/png"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 type ScreenData struct {
 	Type      string `json:"type"`
-	Image     string `json:"image"`
+	Image     string `json:"image,omitempty"`
 	Timestamp int64  `json:"timestamp"`
 	ClientID  string `json:"clientId"`
+
+	// Delta kodlama alanları (screen_update keyframe'leri ve screen_delta)
+	Seq      uint64     `json:"seq,omitempty"`
+	Width    int        `json:"width,omitempty"`
+	Height   int        `json:"height,omitempty"`
+	Keyframe bool       `json:"keyframe,omitempty"`
+	TileSize int        `json:"tileSize,omitempty"`
+	Tiles    []TileData `json:"tiles,omitempty"`
+
+	// Bağlantı yokken diske biriktirilip sonradan gönderilen kare
+	Spooled bool `json:"spooled,omitempty"`
+
+	// Ham JPEG verisi; JSON formatında Image alanına base64 olarak yazılır
+	ImageData []byte `json:"-"`
 }
 
 type BlockedApp struct {
-	Name           string   `json:"name"`
-	Processes      []string `json:"processes"`
-	WarningMessage string   `json:"warning_message"`
+	Name string `json:"name"`
+	// Process adları (birebir) veya exe yolları; ayrıntılı kurallar için Match
+	Processes      []string    `json:"processes"`
+	Match          []MatchRule `json:"match,omitempty"`
+	WarningMessage string      `json:"warning_message"`
+	// Engellemenin geçerli olduğu zaman aralıkları (yoksa her zaman)
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// Günlük izin verilen kullanım; dolana kadar uyarı/kapatma yapılmaz (0: kota yok)
+	DailyQuotaMinutes int `json:"daily_quota_minutes,omitempty"`
+
+	compiled *appMatcher
 }
 
 type BlockedWebsite struct {
 	Name           string   `json:"name"`
 	URLs           []string `json:"urls"`
 	WarningMessage string   `json:"warning_message"`
+	// URL'lerin alan adlarına eklenen alt alan adları (ör. "music", "studio")
+	Subdomains []string `json:"subdomains,omitempty"`
 }
 
 type WebsiteBlockerConfig struct {
@@ -47,12 +70,28 @@ type WebsiteBlockerConfig struct {
 	Settings        struct {
 		WebsiteBlockerEnabled bool   `json:"website_blocker_enabled"`
 		BlockingMethod        string `json:"blocking_method"`
-		BackupHosts           bool   `json:"backup_hosts"`
+		BackupHosts           bool   `json:"backup_hosts"` // artık kullanılmıyor, eski config'ler için
 		ShowWarnings          bool   `json:"show_warnings"`
 		RedirectTo            string `json:"redirect_to"`
+		HostsFile             string `json:"hosts_file"`       // boşsa sistemin hosts dosyası
+		RedirectToIPv6        string `json:"redirect_to_ipv6"` // boşsa "::"
 		CheckIntervalSeconds  int    `json:"check_interval_seconds"`
 		CloseBrowserTabs      bool   `json:"close_browser_tabs"`
 		ShowBlockingMessage   bool   `json:"show_blocking_message"`
+
+		// Her alan adına eklenen önekler (yoksa www, m) ve "*.alan.com"
+		// desenlerinin hosts dosyasında genişletildiği alt alan adları
+		SubdomainPrefixes  []string `json:"subdomain_prefixes"`
+		WildcardSubdomains []string `json:"wildcard_subdomains"`
+
+		// dns_proxy yöntemi: yerel DNS sunucusunun adresi (yoksa 127.0.0.1:53)
+		// ve engellenmeyen sorguların iletildiği sunucular (yoksa 1.1.1.1, 8.8.8.8)
+		DNSListen    string   `json:"dns_listen"`
+		DNSUpstreams []string `json:"dns_upstreams"`
+
+		// redirect_to adresinde engelleme sayfası sunulur (port yoksa 80)
+		BlockPage     bool `json:"block_page"`
+		BlockPagePort int  `json:"block_page_port"`
 	} `json:"settings"`
 }
 
@@ -64,22 +103,63 @@ type AppBlockerConfig struct {
 		ShowWarnings         bool `json:"show_warnings"`
 		MaxWarnings          int  `json:"max_warnings"`
 		AppBlockerEnabled    bool `json:"app_blocker_enabled"`
+		// SIGTERM sonrası SIGKILL'e kadar beklenecek süre (0 ise 5 sn)
+		KillGraceSeconds int `json:"kill_grace_seconds"`
+		// Uygulamanın alt process'lerini de kapat
+		KillProcessTree bool `json:"kill_process_tree"`
+		// Günlük kotaların sıfırlandığı yerel saat ("HH:MM", varsayılan 00:00)
+		QuotaResetTime string `json:"quota_reset_time"`
 	} `json:"settings"`
 }
 
 type Client struct {
-	conn            *websocket.Conn
-	serverURL       string
-	clientID        string
-	isConnected     bool
-	useHTTP         bool // HTTP POST kullan (WebSocket yerine)
-	httpClient      *http.Client
-	appBlocker      *AppBlockerConfig
-	websiteBlocker  *WebsiteBlockerConfig
-	warningCounts   map[string]int
-	hostsBackupPath string
+	ws             *wsTransport
+	wsCancel       context.CancelFunc
+	serverURL      string
+	clientID       string
+	isConnected    atomic.Bool
+	useHTTP        bool // HTTP POST kullan (WebSocket yerine)
+	httpClient     *http.Client
+	blockerMu      sync.RWMutex // appBlocker/websiteBlocker değişimleri için
+	appBlocker     *AppBlockerConfig
+	websiteBlocker *WebsiteBlockerConfig
+	warningMu      sync.Mutex
+	warningCounts  map[string]int
+	hostsMu        sync.Mutex      // hosts dosyası işlemleri ve hostsApplied için
+	hostsApplied   map[string]bool // son yazılan hosts kayıtları
+	config         *ClientConfig
+	captureMu      sync.Mutex // capturer tek goroutine'den kullanılabilir
+	capturer       Capturer
+	masker         *screenMasker // hassas alanları encode öncesi karartır
+	encoder        *frameEncoder
+	frameFormat    atomic.Value // "json" veya "binary", Connect sırasında belirlenir
+	capturePaused  atomic.Bool  // sunucu komutuyla duraklatma
+	privacyPaused  atomic.Bool  // kullanıcının gizlilik molası
+	pause          *pauseState
+	frameInterval  atomic.Int64 // kareler arası süre (ns)
+	commands       chan ServerCommand
+	policies       *policyState // sunucudan gelen engelleme politikaları
+	spool          *frameSpool  // çevrimdışı kare biriktirme (kapalıysa nil)
+	lastFrame      atomic.Value // *capturedFrame, WebSocket kuyruğuna giren son kare
+	super          *supervisor
+	blockers       blockerRunner
+	usage          *appUsage       // günlük kota sayaçları
+	events         *eventQueue     // sunucuya raporlanacak olaylar (kapalıysa nil)
+	detectedApps   map[string]bool // şu an çalışırken tespit edilmiş uygulamalar (warningMu)
+	killing        map[string]bool // kapatılması süren uygulama/tarayıcılar (warningMu)
+	notifier       Notifier        // kullanıcıya gösterilen uyarılar
+	notifyFailed   atomic.Bool
+	startedAt      time.Time
+	lastCaptureAt  atomic.Int64     // son alınan karenin zamanı (unix ns)
+	consentGranted atomic.Bool      // izleme politikası onaylı mı
+	now            func() time.Time // zamanlama kontrolleri için saat (testte değiştirilebilir)
 }
 
+const (
+	appBlockerConfigFile     = "blocked_apps.json"
+	websiteBlockerConfigFile = "blocked_websites.json"
+)
+
 func generateClientID() string {
 	clientIDFile := "client_id.txt"
 
@@ -115,53 +195,173 @@ func generateClientID() string {
 
 func NewClient(serverURL string) *Client {
 	client := &Client{
-		serverURL:       serverURL,
-		clientID:        generateClientID(), // Kalıcı ID
-		useHTTP:         true,               // HTTP POST kullan
-		httpClient:      &http.Client{Timeout: 10 * time.Second},
-		warningCounts:   make(map[string]int),
-		hostsBackupPath: "/etc/hosts.backup",
+		serverURL:     serverURL,
+		clientID:      generateClientID(), // Kalıcı ID
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		warningCounts: make(map[string]int),
+		detectedApps:  make(map[string]bool),
+		config:        loadClientConfig(),
+		policies:      &policyState{policies: make(map[string]*cachedPolicy)},
+		super:         newSupervisor(),
+		now:           time.Now,
+		notifier:      newNotifier(),
+		startedAt:     time.Now(),
+		usage:         loadAppUsage(appUsageFile),
+	}
+	client.frameFormat.Store(frameFormatJSON)
+	client.encoder = newFrameEncoder(client.config.Encoding)
+	client.useHTTP = client.config.Transport.Mode != "websocket"
+	client.commands = make(chan ServerCommand, 16)
+	client.setFPS(client.config.Capture.FPS)
+
+	if client.config.Spool.Enabled {
+		spool, err := openFrameSpool(client.config.Spool)
+		if err != nil {
+			log.Printf("⚠️ Kare biriktirme dizini açılamadı: %v", err)
+		} else {
+			client.spool = spool
+		}
+	}
+	if client.config.Events.Enabled {
+		client.events = openEventQueue(client.config.Events)
+	}
+
+	client.pause = loadPauseState(client.config.Pause.StateFile)
+	client.masker = newScreenMasker(client.config.Privacy, client.config.Capture.Display)
+
+	// Ekran yakalama backend'ini seç
+	capturer, err := newCapturer(client.config.Capture)
+	if err != nil {
+		log.Printf("⚠️ Ekran yakalama backend'i başlatılamadı: %v", err)
+	} else {
+		log.Printf("🎥 Ekran yakalama backend'i: %s", capturer.Name())
+		client.capturer = capturer
 	}
 
 	// Konfigürasyonları yükle
 	client.loadAppBlockerConfig()
 	client.loadWebsiteBlockerConfig()
 
+	// Sunucu politikası önbellekte varsa yerel dosyaların önüne geçer
+	client.loadCachedPolicies()
+
 	return client
 }
 
 func (c *Client) loadAppBlockerConfig() {
-	configFile := "blocked_apps.json"
-	data, err := ioutil.ReadFile(configFile)
-	if err != nil {
-		log.Printf("⚠️ Uygulama engelleme config dosyası bulunamadı: %v", err)
+	// Yönetilen politika kontrolü ile uygulama arasında politika kurulamasın
+	c.policies.applyMu.Lock()
+	defer c.policies.applyMu.Unlock()
+
+	if c.policies.managed(policyKindApps) {
+		log.Println("ℹ️ Uygulama engelleme politikası sunucudan yönetiliyor, blocked_apps.json yok sayıldı")
 		return
 	}
 
-	err = json.Unmarshal(data, &c.appBlocker)
+	// Okunamayan veya geçersiz config mevcut (çalışan) config'i ezmemeli
+	cfg, err := readAppBlockerConfigFile()
 	if err != nil {
-		log.Printf("❌ Config dosyası parse edilemedi: %v", err)
+		log.Printf("⚠️ %v, önceki config kullanılmaya devam ediliyor", err)
 		return
 	}
 
-	log.Printf("✅ %d uygulama engelleme listesine eklendi", len(c.appBlocker.BlockedApplications))
+	c.setAppBlockerConfig(cfg)
+	log.Printf("✅ %d uygulama engelleme listesine eklendi", len(cfg.BlockedApplications))
 }
 
-func (c *Client) loadWebsiteBlockerConfig() {
-	configFile := "blocked_websites.json"
-	data, err := ioutil.ReadFile(configFile)
+// readAppBlockerConfigFile yerel blocked_apps.json dosyasını okur ve doğrular.
+func readAppBlockerConfigFile() (*AppBlockerConfig, error) {
+	data, err := ioutil.ReadFile(appBlockerConfigFile)
 	if err != nil {
-		log.Printf("⚠️ Website engelleme config dosyası bulunamadı: %v", err)
+		return nil, fmt.Errorf("uygulama engelleme config dosyası okunamadı: %v", err)
+	}
+	cfg, errs := parseAppBlockerConfig(data)
+	logValidation(appBlockerConfigFile, errs)
+	if errs.HasErrors() {
+		return nil, fmt.Errorf("%s geçersiz", appBlockerConfigFile)
+	}
+	return cfg, nil
+}
+
+func (c *Client) loadWebsiteBlockerConfig() {
+	// Yönetilen politika kontrolü ile uygulama arasında politika kurulamasın
+	c.policies.applyMu.Lock()
+	defer c.policies.applyMu.Unlock()
+
+	if c.policies.managed(policyKindWebsites) {
+		log.Println("ℹ️ Website engelleme politikası sunucudan yönetiliyor, blocked_websites.json yok sayıldı")
 		return
 	}
 
-	err = json.Unmarshal(data, &c.websiteBlocker)
+	cfg, err := readWebsiteBlockerConfigFile()
 	if err != nil {
-		log.Printf("❌ Website config dosyası parse edilemedi: %v", err)
+		log.Printf("⚠️ %v, önceki config kullanılmaya devam ediliyor", err)
 		return
 	}
 
-	log.Printf("✅ %d website engelleme listesine eklendi", len(c.websiteBlocker.BlockedWebsites))
+	c.setWebsiteBlockerConfig(cfg)
+	log.Printf("✅ %d website engelleme listesine eklendi", len(cfg.BlockedWebsites))
+}
+
+// readWebsiteBlockerConfigFile yerel blocked_websites.json dosyasını okur ve doğrular.
+func readWebsiteBlockerConfigFile() (*WebsiteBlockerConfig, error) {
+	data, err := ioutil.ReadFile(websiteBlockerConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("website engelleme config dosyası okunamadı: %v", err)
+	}
+	cfg, errs := parseWebsiteBlockerConfig(data)
+	logValidation(websiteBlockerConfigFile, errs)
+	if errs.HasErrors() {
+		return nil, fmt.Errorf("%s geçersiz", websiteBlockerConfigFile)
+	}
+	return cfg, nil
+}
+
+func (c *Client) appBlockerConfig() *AppBlockerConfig {
+	c.blockerMu.RLock()
+	defer c.blockerMu.RUnlock()
+	return c.appBlocker
+}
+
+// setAppBlockerConfig config'i değiştirir ve engelleyiciyi gerekirse
+// başlatır/durdurur.
+func (c *Client) setAppBlockerConfig(cfg *AppBlockerConfig) {
+	c.blockerMu.Lock()
+	c.appBlocker = cfg
+	c.blockerMu.Unlock()
+
+	c.updateBlockers()
+}
+
+func (c *Client) websiteBlockerConfig() *WebsiteBlockerConfig {
+	c.blockerMu.RLock()
+	defer c.blockerMu.RUnlock()
+	return c.websiteBlocker
+}
+
+func (c *Client) setWebsiteBlockerConfig(cfg *WebsiteBlockerConfig) {
+	c.blockerMu.Lock()
+	c.websiteBlocker = cfg
+	c.blockerMu.Unlock()
+
+	c.updateBlockers()
+}
+
+// reloadBlockerConfigs config dosyalarını ve sunucu politikalarını tekrar
+// okur. Çalışan engelleyiciler bir sonraki kontrolde yeni listeyi kullanır.
+func (c *Client) reloadBlockerConfigs() map[string]int {
+	c.loadAppBlockerConfig()
+	c.loadWebsiteBlockerConfig()
+	c.syncPolicies()
+
+	counts := map[string]int{"apps": 0, "websites": 0}
+	if cfg := c.appBlockerConfig(); cfg != nil {
+		counts["apps"] = len(cfg.BlockedApplications)
+	}
+	if cfg := c.websiteBlockerConfig(); cfg != nil {
+		counts["websites"] = len(cfg.BlockedWebsites)
+	}
+	return counts
 }
 
 func (c *Client) Connect() error {
@@ -174,78 +374,212 @@ func (c *Client) Connect() error {
 			return fmt.Errorf("sunucu erişilemez: %v", err)
 		}
 		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("sunucu erişilemez: HTTP %d", resp.StatusCode)
+		}
 
-		c.isConnected = true
-		log.Println("✅ HTTP sunucuya başarıyla bağlandı")
+		caps := c.fetchCapabilitiesHTTP()
+		c.frameFormat.Store(caps.frameFormat())
+		c.encoder.SetDelta(c.config.Encoding.deltaEnabled(caps.DeltaFrames))
+		c.isConnected.Store(true)
+		log.Printf("✅ HTTP sunucuya başarıyla bağlandı (kare formatı: %s, delta: %v)",
+			c.frameFormat.Load(), c.encoder.DeltaEnabled())
 		return nil
 	}
 
-	// WebSocket bağlantısı (fallback)
-	u, err := url.Parse(c.serverURL)
-	if err != nil {
-		return err
+	// WebSocket transport'u arka planda çalışır ve kendi içinde yeniden bağlanır
+	if c.ws == nil {
+		wsURL, err := webSocketURL(c.serverURL, c.config.Transport)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("WebSocket bağlantısı: %s", wsURL)
+
+		c.ws = newWSTransport(wsURL, c.config.Transport, c.clientID)
+		c.ws.onMessage = c.handleServerMessage
+		c.ws.onFrameLost = c.encoder.RequestKeyframe
+		c.ws.onFramesDropped = c.spoolDroppedFrames
+		c.ws.onRegistered = func(caps serverCapabilities) {
+			c.encoder.SetDelta(c.config.Encoding.deltaEnabled(caps.DeltaFrames))
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c.wsCancel = cancel
+		go c.ws.Run(ctx)
 	}
 
-	wsURL := fmt.Sprintf("ws://%s:8765", u.Hostname())
-	if u.Scheme == "https" {
-		wsURL = fmt.Sprintf("wss://%s:8765", u.Hostname())
+	c.isConnected.Store(true)
+	return nil
+}
+
+// handleServerMessage WebSocket üzerinden sunucudan gelen mesajları işler.
+func (c *Client) handleServerMessage(msgType string, data []byte) {
+	switch msgType {
+	case "command":
+		var cmd ServerCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			log.Printf("⚠️ Komut parse edilemedi: %v", err)
+			return
+		}
+		c.enqueueCommand(cmd)
+	default:
+		log.Printf("📨 Sunucu mesajı alındı: %s", msgType)
 	}
+}
 
-	log.Printf("WebSocket bağlantısı: %s", wsURL)
+func (c *Client) setFPS(fps int) {
+	c.frameInterval.Store(int64(time.Second / time.Duration(fps)))
+}
 
-	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
-	if err != nil {
-		return err
+// fps kullanılan kare hızını frameInterval'dan hesaplar.
+func (c *Client) fps() int {
+	interval := time.Duration(c.frameInterval.Load())
+	if interval <= 0 {
+		return 0
 	}
+	return int((time.Second + interval/2) / interval)
+}
 
-	c.conn = conn
-	c.isConnected = true
+func (c *Client) Disconnect() {
+	if c.wsCancel != nil {
+		c.wsCancel()
+	}
+	c.isConnected.Store(false)
 
-	// Client kaydını gönder
-	registerMsg := map[string]interface{}{
-		"type":      "client_register",
-		"client_id": c.clientID,
+	c.captureMu.Lock()
+	defer c.captureMu.Unlock()
+	if c.capturer != nil {
+		c.capturer.Close()
+		c.capturer = nil
 	}
+	c.masker.Close()
+}
 
-	if err := c.conn.WriteJSON(registerMsg); err != nil {
-		log.Printf("⚠️ Client kayıt hatası: %v", err)
+// Run sunucuya bağlanır ve tüm arka plan görevlerini başlatır. ctx iptal
+// edilene kadar bloklar; kapatma için ardından Shutdown çağrılmalıdır.
+func (c *Client) Run(ctx context.Context) {
+	// Kullanıcıya client'ın çalıştığını baştan göster
+	c.super.Start("status_reporter", c.runStatusReporter)
+	if c.config.Pause.Enabled {
+		c.super.Start(taskPauseControl, c.runPauseControl)
 	}
 
-	log.Println("✅ WebSocket sunucuya başarıyla bağlandı")
-	return nil
+	// Sunucuya bağlan
+	for {
+		err := c.Connect()
+		if err == nil {
+			break
+		}
+		log.Printf("❌ Bağlantı hatası: %v", err)
+		log.Println("🔄 3 saniye sonra tekrar denenecek...")
+		if !sleepCtx(ctx, 3*time.Second) {
+			return
+		}
+	}
+
+	// Engelleyiciler başlamadan önce güncel politikaları çek
+	c.syncPolicies()
+	c.super.Start("policy_sync", c.StartPolicySync)
+
+	// Uygulama ve website engelleyicilerini başlat, config dosyalarını izle
+	c.recoverHostsFile()
+	c.StartBlockers()
+	c.super.Start("config_watcher", c.StartConfigWatcher)
+
+	// Sunucu komutlarını dinle
+	c.StartCommandChannel()
+
+	// Ekran yakalamayı (onay gerekiyorsa onay alınınca) ve biriken karelerin gönderimini başlat
+	if c.config.Consent.Required {
+		c.super.Start(taskConsentGate, c.runConsentGate)
+	} else {
+		c.consentGranted.Store(true)
+		c.super.Start(taskCapture, c.StartScreenCapture)
+	}
+	if c.spool != nil {
+		c.super.Start("spool_replay", c.replaySpool)
+	}
+	if c.events != nil {
+		c.super.Start("event_sender", c.runEventSender)
+	}
+
+	<-ctx.Done()
 }
 
-func (c *Client) Disconnect() {
-	if c.conn != nil {
-		c.conn.Close()
+// Shutdown görevleri sırayla durdurur: önce ekran yakalama (gönderilmekte olan
+// kare tamamlanır ve kuyruktaki kareler gönderilir), sonra engelleyiciler
+// (hosts dosyası eski haline getirilir), en son diğer görevler ve bağlantı.
+func (c *Client) Shutdown(timeout time.Duration) {
+	c.super.Stop(taskConsentGate) // yakalamayı yeniden başlatmasın
+	c.super.Stop(taskCapture)
+
+	if !c.useHTTP && c.ws != nil && !c.ws.Flush(timeout) {
+		log.Println("⚠️ Kuyruktaki kareler gönderilemedi")
 	}
-	c.isConnected = false
+
+	c.StopBlockers()
+	c.super.StopAll()
+	c.Disconnect()
+	c.clearStatus()
 }
 
-func (c *Client) StartScreenCapture() {
-	ticker := time.NewTicker(33 * time.Millisecond) // 30 FPS
-	defer ticker.Stop()
+const (
+	taskCapture     = "capture"
+	shutdownTimeout = 10 * time.Second
+)
 
-	// Frame skipping için
-	lastFrameTime := time.Now()
-	frameSkipThreshold := 50 * time.Millisecond
+func (c *Client) StartScreenCapture(ctx context.Context) {
+	interval := time.Duration(c.frameInterval.Load())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
 	log.Println("🎥 Ekran yakalama başlatıldı...")
 
-	for range ticker.C {
-		// Frame skipping - eğer işlem çok uzun sürüyorsa atla
-		if time.Since(lastFrameTime) < frameSkipThreshold {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// FPS sunucu komutuyla değişmiş olabilir
+		if current := time.Duration(c.frameInterval.Load()); current != interval {
+			interval = current
+			ticker.Reset(interval)
+		}
+
+		if c.capturePaused.Load() || c.privacyPaused.Load() {
 			continue
 		}
-		lastFrameTime = time.Now()
 
-		if !c.isConnected {
+		online := true
+		if c.useHTTP && !c.isConnected.Load() {
 			log.Println("⚠️ Bağlantı kesildi, yeniden bağlanmaya çalışılıyor...")
 			if err := c.Connect(); err != nil {
 				log.Printf("❌ Yeniden bağlanma hatası: %v", err)
-				time.Sleep(3 * time.Second)
-				continue
+				online = false
+			}
+		}
+		// WebSocket transport'u kendi içinde yeniden bağlanır
+		if !c.useHTTP && !c.ws.Connected() {
+			online = false
+		}
+
+		// Çevrimdışıyken kareler (düşük hızda) diske biriktirilir
+		if !online {
+			if c.spool != nil && c.spool.due() {
+				if img, err := c.takeScreenshot(); err == nil {
+					c.lastCaptureAt.Store(time.Now().UnixNano())
+					c.spoolScreen(img)
+				} else {
+					log.Printf("⚠️ Ekran yakalama hatası: %v", err)
+				}
+			}
+			if c.useHTTP {
+				sleepCtx(ctx, 3*time.Second)
 			}
+			continue
 		}
 
 		// Ekran görüntüsü al
@@ -254,119 +588,59 @@ func (c *Client) StartScreenCapture() {
 			log.Printf("⚠️ Ekran yakalama hatası: %v", err)
 			continue
 		}
+		c.lastCaptureAt.Store(time.Now().UnixNano())
 
-		// JPEG'e çevir ve base64 encode et
-		imageData, err := c.imageToBase64(img)
+		// Küçült, değişen karoları bul ve JPEG/base64 olarak kodla
+		small := c.downscaleImage(img)
+		screenData, err := c.encoder.Encode(small, c.clientID)
 		if err != nil {
 			log.Printf("⚠️ Image encode hatası: %v", err)
 			continue
 		}
-
-		// Sunucuya gönder
-		screenData := ScreenData{
-			Type:      "screen_update",
-			Image:     imageData,
-			Timestamp: time.Now().Unix(),
-			ClientID:  c.clientID,
+		if screenData == nil {
+			continue // Ekranda değişiklik yok
 		}
 
 		if c.useHTTP {
 			// HTTP POST ile gönder
 			if err := c.sendScreenHTTP(screenData); err != nil {
 				log.Printf("⚠️ HTTP veri gönderme hatası: %v", err)
-				c.isConnected = false
+				c.isConnected.Store(false)
+				c.encoder.RequestKeyframe()
+				c.spoolScreen(img)
 				continue
 			}
 		} else {
-			// WebSocket ile gönder
-			if err := c.conn.WriteJSON(screenData); err != nil {
-				log.Printf("⚠️ WebSocket veri gönderme hatası: %v", err)
-				c.isConnected = false
-				continue
-			}
+			// Bağlantı koparsa kuyrukta kalan delta'ların yerine bu kare biriktirilir
+			c.lastFrame.Store(&capturedFrame{img: small, at: time.Now()})
+			// WebSocket kuyruğuna ekle (bloklamaz, ağ işlemi ayrı goroutine'de)
+			c.ws.SendFrame(screenData)
 		}
 	}
 }
 
 func (c *Client) takeScreenshot() (image.Image, error) {
-	switch runtime.GOOS {
-	case "darwin": // macOS
-		return c.takeScreenshotMacOS()
-	case "linux":
-		return c.takeScreenshotLinux()
-	case "windows":
-		return c.takeScreenshotWindows()
-	default:
-		return nil, fmt.Errorf("desteklenmeyen platform: %s", runtime.GOOS)
-	}
-}
-
-func (c *Client) takeScreenshotMacOS() (image.Image, error) {
-	tmpFile := "/tmp/screenshot_" + fmt.Sprintf("%d", time.Now().UnixNano()) + ".png"
-
-	cmd := exec.Command("screencapture", "-x", "-t", "png", tmpFile)
-	err := cmd.Run()
-	if err != nil {
-		return nil, err
-	}
+	c.captureMu.Lock()
+	defer c.captureMu.Unlock()
 
-	data, err := exec.Command("cat", tmpFile).Output()
-	if err != nil {
-		return nil, err
-	}
-
-	exec.Command("rm", tmpFile).Run()
-
-	img, _, err := image.Decode(bytes.NewReader(data))
-	return img, err
-}
-
-func (c *Client) takeScreenshotLinux() (image.Image, error) {
-	cmd := exec.Command("gnome-screenshot", "-f", "/dev/stdout")
-	output, err := cmd.Output()
-	if err != nil {
-		cmd = exec.Command("scrot", "-o", "/dev/stdout")
-		output, err = cmd.Output()
+	// Backend henüz yoksa (örn. X sunucusu açılmamıştı) tekrar dene
+	if c.capturer == nil {
+		capturer, err := newCapturer(c.config.Capture)
 		if err != nil {
 			return nil, err
 		}
+		log.Printf("🎥 Ekran yakalama backend'i: %s", capturer.Name())
+		c.capturer = capturer
 	}
 
-	img, _, err := image.Decode(bytes.NewReader(output))
-	return img, err
-}
-
-func (c *Client) takeScreenshotWindows() (image.Image, error) {
-	powershellScript := `
-	Add-Type -AssemblyName System.Windows.Forms
-	Add-Type -AssemblyName System.Drawing
-	$Screen = [System.Windows.Forms.SystemInformation]::VirtualScreen
-	$bitmap = New-Object System.Drawing.Bitmap $Screen.Width, $Screen.Height
-	$graphics = [System.Drawing.Graphics]::FromImage($bitmap)
-	$graphics.CopyFromScreen($Screen.Left, $Screen.Top, 0, 0, $bitmap.Size)
-	$stream = New-Object System.IO.MemoryStream
-	$bitmap.Save($stream, [System.Drawing.Imaging.ImageFormat]::Png)
-	[Convert]::ToBase64String($stream.ToArray())
-	`
-
-	cmd := exec.Command("powershell", "-Command", powershellScript)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	data, err := base64.StdEncoding.DecodeString(string(output))
+	img, err := c.capturer.Capture()
 	if err != nil {
 		return nil, err
 	}
-
-	img, _, err := image.Decode(bytes.NewReader(data))
-	return img, err
+	return c.masker.Apply(img), nil
 }
 
-func (c *Client) imageToBase64(img image.Image) (string, error) {
-	var buf bytes.Buffer
-
+func (c *Client) downscaleImage(img image.Image) image.Image {
 	// Görüntüyü küçült (performans için)
 	bounds := img.Bounds()
 	width := bounds.Dx()
@@ -380,24 +654,28 @@ func (c *Client) imageToBase64(img image.Image) (string, error) {
 		smallImg := image.NewRGBA(image.Rect(0, 0, width, height))
 		for y := 0; y < height; y++ {
 			for x := 0; x < width; x++ {
-				smallImg.Set(x, y, img.At(x*2, y*2))
+				smallImg.Set(x, y, img.At(bounds.Min.X+x*2, bounds.Min.Y+y*2))
 			}
 		}
 		img = smallImg
 	}
 
-	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 50})
-	if err != nil {
-		return "", err
-	}
-
-	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
-	return "data:image/jpeg;base64," + encoded, nil
+	return img
 }
 
-func (c *Client) sendScreenHTTP(screenData ScreenData) error {
-	// JSON payload hazırla
-	jsonData, err := json.Marshal(screenData)
+func (c *Client) sendScreenHTTP(screenData *ScreenData) error {
+	// Payload hazırla (binary veya eski sunucular için JSON)
+	var payload []byte
+	var contentType string
+	var err error
+	format := c.frameFormat.Load().(string)
+	if format == frameFormatBinary {
+		payload, err = marshalBinaryFrame(screenData)
+		contentType = frameContentType
+	} else {
+		payload, err = json.Marshal(jsonScreenData(screenData))
+		contentType = "application/json"
+	}
 	if err != nil {
 		return err
 	}
@@ -405,89 +683,182 @@ func (c *Client) sendScreenHTTP(screenData ScreenData) error {
 	// HTTP POST isteği gönder
 	resp, err := c.httpClient.Post(
 		c.serverURL+"/api/screen-update",
-		"application/json",
-		bytes.NewBuffer(jsonData),
+		contentType,
+		bytes.NewBuffer(payload),
 	)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	// Binary formatı kabul etmeyen sunucuda JSON'a geri dön
+	if resp.StatusCode == http.StatusUnsupportedMediaType && format == frameFormatBinary {
+		log.Println("⚠️ Sunucu binary kareleri kabul etmedi, JSON formatına geçiliyor")
+		c.frameFormat.Store(frameFormatJSON)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return &httpStatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return nil
 }
 
-func (c *Client) StartAppBlocker() {
-	if c.appBlocker == nil {
+func (c *Client) StartAppBlocker(ctx context.Context) {
+	cfg := c.appBlockerConfig()
+	if cfg == nil {
 		return
 	}
 
-	interval := time.Duration(c.appBlocker.Settings.CheckIntervalSeconds) * time.Second
+	interval := time.Duration(cfg.Settings.CheckIntervalSeconds) * time.Second
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	// Turlarda seyrek yazılan kullanım sayaçları dururken diske alınır
+	defer c.usage.save()
 
 	log.Println("🚫 Uygulama engelleyici başlatıldı...")
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Kontrol aralığı config yeniden yüklenince değişmiş olabilir
+		if cfg = c.appBlockerConfig(); cfg == nil {
+			continue
+		}
+		if current := time.Duration(cfg.Settings.CheckIntervalSeconds) * time.Second; current != interval {
+			interval = current
+			ticker.Reset(interval)
+		}
+
 		c.checkAndBlockApps()
 	}
 }
 
 func (c *Client) checkAndBlockApps() {
-	runningProcesses, err := c.getRunningProcesses()
+	cfg := c.appBlockerConfig()
+	if cfg == nil {
+		return
+	}
+
+	processes, err := listProcesses()
 	if err != nil {
 		log.Printf("⚠️ Process listesi alınamadı: %v", err)
 		return
 	}
 
-	for _, blockedApp := range c.appBlocker.BlockedApplications {
-		for _, process := range blockedApp.Processes {
-			if c.isProcessRunning(process, runningProcesses) {
-				log.Printf("🚫 Yasaklı uygulama tespit edildi: %s (%s)", blockedApp.Name, process)
-				c.handleBlockedApp(blockedApp, process)
-				break // Aynı app için sadece bir kez uyarı göster
+	now := c.now()
+	if c.usage.rollover(now, cfg.quotaResetMinute()) {
+		log.Printf("🔄 Günlük kullanım kotaları sıfırlandı")
+	}
+	defer c.usage.saveIfDue(now)
+
+	// Turlar arasında bundan uzun boşluk kullanım sayılmaz
+	maxGap := 2 * time.Duration(cfg.Settings.CheckIntervalSeconds) * time.Second
+
+	for _, blockedApp := range cfg.BlockedApplications {
+		// Zamanlama dışında uygulamaya izin verilir
+		if !blockedApp.Schedule.Active(now) {
+			c.usage.forget(blockedApp.Name)
+			c.clearDetected(blockedApp.Name)
+			continue
+		}
+		matched := blockedApp.matcher().Find(processes)
+		if len(matched) == 0 {
+			c.usage.forget(blockedApp.Name)
+			c.clearDetected(blockedApp.Name)
+			continue
+		}
+
+		if blockedApp.DailyQuotaMinutes > 0 {
+			quota := time.Duration(blockedApp.DailyQuotaMinutes) * time.Minute
+			before, used := c.usage.observe(blockedApp.Name, now, maxGap)
+			if used < quota {
+				c.clearDetected(blockedApp.Name)
+				continue
+			}
+			if before < quota {
+				log.Printf("⏱️ %s günlük kotası doldu (%d dk)", blockedApp.Name, blockedApp.DailyQuotaMinutes)
+				c.notify(Notification{
+					Key:   blockedApp.Name,
+					Title: "⏱️ " + blockedApp.Name,
+					Body:  fmt.Sprintf("Günlük %d dakikalık kullanım kotası doldu.", blockedApp.DailyQuotaMinutes),
+				})
 			}
 		}
+		// Önceki turdaki kapatma hâlâ sürüyorsa tekrar uyarılmaz
+		if !c.startKill(blockedApp.Name) {
+			continue
+		}
+		// Aynı app için sadece bir kez uyarı göster
+		log.Printf("🚫 Yasaklı uygulama tespit edildi: %s (%s)", blockedApp.Name, describeProcesses(matched))
+		if c.markDetected(blockedApp.Name) {
+			c.emitEvent(BlockerEvent{Type: eventAppDetected, App: blockedApp.Name, PIDs: processIDs(matched)})
+		}
+		// Kapatma kill_grace_seconds kadar sürebilir; kontrol turu beklemesin
+		go func(app BlockedApp, matched []Process) {
+			defer c.finishKill(app.Name)
+			c.handleBlockedApp(app, matched)
+		}(blockedApp, matched)
 	}
 }
 
-func (c *Client) getRunningProcesses() ([]string, error) {
-	var cmd *exec.Cmd
-
-	switch runtime.GOOS {
-	case "darwin": // macOS
-		cmd = exec.Command("ps", "aux")
-	case "linux":
-		cmd = exec.Command("ps", "aux")
-	case "windows":
-		cmd = exec.Command("tasklist", "/fo", "csv")
-	default:
-		return nil, fmt.Errorf("desteklenmeyen platform: %s", runtime.GOOS)
+// startKill key için süren bir uyarı/kapatma yoksa onu işaretler ve true döner.
+func (c *Client) startKill(key string) bool {
+	c.warningMu.Lock()
+	defer c.warningMu.Unlock()
+	if c.killing[key] {
+		return false
 	}
+	if c.killing == nil {
+		c.killing = make(map[string]bool)
+	}
+	c.killing[key] = true
+	return true
+}
 
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+func (c *Client) finishKill(key string) {
+	c.warningMu.Lock()
+	delete(c.killing, key)
+	c.warningMu.Unlock()
+}
+
+// markDetected uygulama yeni tespit edildiyse true döner (aynı çalışma
+// süresince tekrar app_detected olayı üretilmez).
+func (c *Client) markDetected(app string) bool {
+	c.warningMu.Lock()
+	defer c.warningMu.Unlock()
+	if c.detectedApps[app] {
+		return false
 	}
+	c.detectedApps[app] = true
+	return true
+}
 
-	lines := strings.Split(string(output), "\n")
-	return lines, nil
+func (c *Client) clearDetected(app string) {
+	c.warningMu.Lock()
+	delete(c.detectedApps, app)
+	c.warningMu.Unlock()
 }
 
-func (c *Client) isProcessRunning(processName string, runningProcesses []string) bool {
-	processLower := strings.ToLower(processName)
+func processIDs(processes []Process) []int {
+	pids := make([]int, len(processes))
+	for i, p := range processes {
+		pids[i] = p.PID
+	}
+	return pids
+}
 
-	for _, line := range runningProcesses {
-		lineLower := strings.ToLower(line)
-		if strings.Contains(lineLower, processLower) {
-			return true
-		}
+func describeProcesses(processes []Process) string {
+	parts := make([]string, len(processes))
+	for i, p := range processes {
+		parts[i] = p.String()
 	}
-	return false
+	return strings.Join(parts, ", ")
 }
 
 func min(a, b int) int {
@@ -497,223 +868,304 @@ func min(a, b int) int {
 	return b
 }
 
-func (c *Client) handleBlockedApp(app BlockedApp, process string) {
+// handleBlockedApp uyarı sayısını artırır, gerekirse uygulamayı kapatır ve
+// kapatma sonuçlarını döner (kapatılmadıysa nil).
+func (c *Client) handleBlockedApp(app BlockedApp, processes []Process) []KillResult {
+	cfg := c.appBlockerConfig()
+
 	// Uyarı sayısını artır
+	c.warningMu.Lock()
 	c.warningCounts[app.Name]++
+	warnings := c.warningCounts[app.Name]
+	kill := cfg.Settings.AutoKill || warnings >= cfg.Settings.MaxWarnings
+	if kill {
+		c.warningCounts[app.Name] = 0 // Sayacı sıfırla
+	}
+	c.warningMu.Unlock()
 
-	if c.appBlocker.Settings.ShowWarnings {
+	if cfg.Settings.ShowWarnings {
 		log.Printf("🚫 %s", app.WarningMessage)
-		log.Printf("📊 Uyarı: %d/%d", c.warningCounts[app.Name], c.appBlocker.Settings.MaxWarnings)
+		log.Printf("📊 Uyarı: %d/%d", warnings, cfg.Settings.MaxWarnings)
+		if !kill {
+			c.notify(Notification{
+				Key:     app.Name,
+				Title:   "🚫 " + app.Name,
+				Body:    fmt.Sprintf("%s\nUyarı %d/%d: %d. uyarıda uygulama kapatılacak.", app.WarningMessage, warnings, cfg.Settings.MaxWarnings, cfg.Settings.MaxWarnings),
+				Urgency: notifyUrgencyNormal,
+			})
+		}
 	}
 
 	// Maksimum uyarı sayısına ulaşıldıysa veya otomatik kapatma aktifse
-	if c.appBlocker.Settings.AutoKill || c.warningCounts[app.Name] >= c.appBlocker.Settings.MaxWarnings {
-		c.killProcess(process, app.Name)
-		c.warningCounts[app.Name] = 0 // Sayacı sıfırla
+	if !kill {
+		c.emitEvent(BlockerEvent{
+			Type:        eventAppWarned,
+			App:         app.Name,
+			PIDs:        processIDs(processes),
+			Warnings:    warnings,
+			MaxWarnings: cfg.Settings.MaxWarnings,
+			Message:     app.WarningMessage,
+		})
+		return nil
 	}
+	results := c.killProcesses(app.Name, processes, killOptions{
+		Grace: time.Duration(cfg.Settings.KillGraceSeconds) * time.Second,
+		Tree:  cfg.Settings.KillProcessTree,
+	})
+	c.emitKillEvent(app, results)
+	if cfg.Settings.ShowWarnings && killSucceeded(results) {
+		c.notify(Notification{
+			Key:     app.Name,
+			Title:   "🔧 " + app.Name + " kapatıldı",
+			Body:    app.WarningMessage,
+			Urgency: notifyUrgencyCritical,
+		})
+	}
+	return results
 }
 
-func (c *Client) killProcess(processName, appName string) {
+// killProcesses eşleşen process'leri PID ile kapatır ve sonuçları loglar.
+// Nazik kapatma süresi boyunca bloklar.
+func (c *Client) killProcesses(appName string, processes []Process, opts killOptions) []KillResult {
 	log.Printf("🔧 %s kapatılıyor...", appName)
 
-	switch runtime.GOOS {
-	case "darwin":
-		// macOS için özel app kapatma
-		success := c.killMacOSApp(processName, appName)
-		if !success {
-			// Fallback: Force kill
-			log.Printf("🔄 %s force kill deneniyor...", appName)
-			cmd := exec.Command("pkill", "-f", processName)
-			err := cmd.Run()
-			if err != nil {
-				log.Printf("⚠️ %s kapatılamadı: %v", appName, err)
-			} else {
-				log.Printf("✅ %s force kill ile kapatıldı", appName)
-			}
-		}
-	case "linux":
-		cmd := exec.Command("pkill", "-f", processName)
-		err := cmd.Run()
-		if err != nil {
-			log.Printf("⚠️ %s kapatılamadı: %v", appName, err)
-		} else {
-			log.Printf("✅ %s başarıyla kapatıldı", appName)
+	results := terminateProcesses(processes, opts)
+
+	for _, r := range results {
+		switch r.Outcome {
+		case killOutcomeTerminated:
+			log.Printf("✅ %s (pid %d) kapatıldı", r.Name, r.PID)
+		case killOutcomeKilled:
+			log.Printf("✅ %s (pid %d) zorla kapatıldı", r.Name, r.PID)
+		case killOutcomeAlreadyExited:
+			log.Printf("ℹ️ %s (pid %d) zaten kapanmış", r.Name, r.PID)
+		default:
+			log.Printf("⚠️ %s (pid %d) kapatılamadı: %s", r.Name, r.PID, r.Error)
 		}
-	case "windows":
-		cmd := exec.Command("taskkill", "/f", "/im", processName)
-		err := cmd.Run()
-		if err != nil {
-			log.Printf("⚠️ %s kapatılamadı: %v", appName, err)
-		} else {
-			log.Printf("✅ %s başarıyla kapatıldı", appName)
-		}
-	default:
-		log.Printf("❌ Process kapatma desteklenmiyor: %s", runtime.GOOS)
 	}
+	return results
 }
 
-func (c *Client) killMacOSApp(processName, appName string) bool {
-	// Farklı yöntemler dene
-	var cmds []*exec.Cmd
-
-	// 1. App name ile AppleScript
-	if appName == "Steam" {
-		cmds = append(cmds, exec.Command("osascript", "-e", "quit app \"Steam\""))
-	} else if appName == "Chrome" {
-		cmds = append(cmds, exec.Command("osascript", "-e", "quit app \"Google Chrome\""))
-	} else if appName == "Firefox" {
-		cmds = append(cmds, exec.Command("osascript", "-e", "quit app \"Firefox\""))
-	}
-
-	// 2. killall komutu
-	if strings.Contains(strings.ToLower(processName), "chrome") {
-		cmds = append(cmds, exec.Command("killall", "Google Chrome"))
-	} else if strings.Contains(strings.ToLower(processName), "firefox") {
-		cmds = append(cmds, exec.Command("killall", "firefox"))
-	} else if strings.Contains(strings.ToLower(processName), "steam") {
-		cmds = append(cmds, exec.Command("killall", "steam"))
+func (c *Client) StartWebsiteBlocker(ctx context.Context) {
+	cfg := c.websiteBlockerConfig()
+	if cfg == nil {
+		return
 	}
 
-	// 3. pkill
-	cmds = append(cmds, exec.Command("pkill", "-i", processName))
+	log.Println("🚫 Website engelleyici başlatıldı...")
 
-	// Sırayla dene
-	for _, cmd := range cmds {
-		err := cmd.Run()
-		if err == nil {
-			log.Printf("✅ %s başarıyla kapatıldı", appName)
-			return true
-		}
+	method := cfg.Settings.BlockingMethod
+	if method == "hosts" {
+		// Hosts dosyası yöntemi (root veya sudo gerektirir)
+		c.blockWebsites()
 	}
 
-	return false
-}
-
-func (c *Client) StartWebsiteBlocker() {
-	if c.websiteBlocker == nil {
-		return
+	// dns_proxy yöntemi seçiliyken çalışan DNS sunucusu; yöntem, dinleme adresi
+	// veya upstream'ler değişince durdurulur ve gerekirse yeniden başlatılır
+	var (
+		dnsKey  string
+		stopDNS func()
+	)
+	updateDNS := func(cfg *WebsiteBlockerConfig) {
+		key := cfg.dnsProxyKey()
+		if key == dnsKey {
+			return
+		}
+		if stopDNS != nil {
+			// Aynı adres tekrar dinlenebilsin diye soketlerin kapanması beklenir
+			stopDNS()
+			stopDNS = nil
+			log.Println("🌐 DNS proxy durduruldu")
+		}
+		dnsKey = key
+		if key != "" {
+			dnsCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				c.runDNSProxy(dnsCtx, cfg)
+			}()
+			stopDNS = func() {
+				cancel()
+				<-done
+			}
+		}
 	}
+	defer func() {
+		if stopDNS != nil {
+			stopDNS()
+		}
+	}()
+	updateDNS(cfg)
 
-	log.Println("🚫 Website engelleyici başlatıldı...")
-
-	if c.websiteBlocker.Settings.BlockingMethod == "hosts" {
-		// Hosts dosyası yöntemi (sudo gerektirir)
-		if c.websiteBlocker.Settings.BackupHosts {
-			c.backupHostsFile()
+	// Engelleme sayfası; adres (redirect_to, port) değişince yeniden başlatılır
+	var (
+		pageAddr string
+		pageWarn string
+		stopPage context.CancelFunc
+	)
+	updateBlockPage := func(cfg *WebsiteBlockerConfig) {
+		addr, err := cfg.blockPageAddr()
+		// Uyarı her kontrolde değil, neden değiştiğinde bir kez yazılır
+		warn := ""
+		if err != nil {
+			warn = err.Error()
+		}
+		if warn != pageWarn && warn != "" {
+			log.Printf("⚠️ %s", warn)
+		}
+		pageWarn = warn
+		if addr == pageAddr {
+			return
+		}
+		if stopPage != nil {
+			stopPage()
+			stopPage = nil
+			log.Printf("📄 Engelleme sayfası durduruldu: %s", pageAddr)
+		}
+		pageAddr = addr
+		if addr != "" {
+			var pageCtx context.Context
+			pageCtx, stopPage = context.WithCancel(ctx)
+			go c.runBlockPage(pageCtx, addr)
 		}
-		c.blockWebsites()
 	}
+	defer func() {
+		if stopPage != nil {
+			stopPage()
+		}
+	}()
+	updateBlockPage(cfg)
 
 	// Periyodik kontrol
-	interval := time.Duration(c.websiteBlocker.Settings.CheckIntervalSeconds) * time.Second
+	interval := time.Duration(cfg.Settings.CheckIntervalSeconds) * time.Second
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		if c.websiteBlocker.Settings.BlockingMethod == "browser_check" {
-			c.checkAndCloseBrowserTabs()
-		} else {
-			c.ensureWebsitesBlocked()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-	}
-}
 
-func (c *Client) backupHostsFile() {
-	if runtime.GOOS == "windows" {
-		return // Windows için şimdilik skip
-	}
+		// Config çalışırken yeniden yüklenmiş olabilir
+		if cfg = c.websiteBlockerConfig(); cfg == nil {
+			continue
+		}
+		if current := time.Duration(cfg.Settings.CheckIntervalSeconds) * time.Second; current != interval {
+			interval = current
+			ticker.Reset(interval)
+		}
 
-	// Hosts dosyasını backup'la
-	cmd := exec.Command("sudo", "cp", "/etc/hosts", c.hostsBackupPath)
-	err := cmd.Run()
-	if err != nil {
-		log.Printf("⚠️ Hosts dosyası backup edilemedi: %v", err)
-	} else {
-		log.Printf("✅ Hosts dosyası backup edildi: %s", c.hostsBackupPath)
+		// Yöntem hosts'tan değiştiyse hosts bölümü kaldırılır
+		if method == "hosts" && cfg.Settings.BlockingMethod != "hosts" {
+			c.unblockWebsites()
+		}
+		method = cfg.Settings.BlockingMethod
+		updateDNS(cfg)
+		updateBlockPage(cfg)
+
+		switch method {
+		case "browser_check":
+			c.checkAndCloseBrowserTabs()
+		case "dns_proxy":
+			// Sorgular geldikçe cevaplanır, periyodik iş yok
+		default:
+			c.ensureWebsitesBlocked()
+		}
 	}
 }
 
 func (c *Client) blockWebsites() {
-	if c.websiteBlocker == nil || !c.websiteBlocker.Settings.WebsiteBlockerEnabled {
+	cfg := c.websiteBlockerConfig()
+	if cfg == nil || !cfg.Settings.WebsiteBlockerEnabled {
 		return
 	}
 
-	log.Printf("🚫 %d website engelleniyor...", len(c.websiteBlocker.BlockedWebsites))
-
-	// Hosts dosyasına yasaklı siteleri ekle
-	var hostsEntries []string
-	hostsEntries = append(hostsEntries, "\n# Blocked websites by screen recorder client")
-
-	for _, website := range c.websiteBlocker.BlockedWebsites {
-		for _, url := range website.URLs {
-			entry := fmt.Sprintf("%s %s", c.websiteBlocker.Settings.RedirectTo, url)
-			hostsEntries = append(hostsEntries, entry)
-		}
-	}
-
-	hostsEntries = append(hostsEntries, "# End blocked websites\n")
-
-	// Hosts dosyasına append et
-	c.appendToHostsFile(strings.Join(hostsEntries, "\n"))
+	log.Printf("🚫 %d website engelleniyor...", len(cfg.BlockedWebsites))
+	c.applyHostsEntries(cfg)
 }
 
-func (c *Client) appendToHostsFile(content string) {
-	var hostsPath string
-	if runtime.GOOS == "windows" {
-		hostsPath = "C:\\Windows\\System32\\drivers\\etc\\hosts"
-	} else {
-		hostsPath = "/etc/hosts"
-	}
+// applyHostsEntries client'ın hosts bölümünü config'e göre günceller.
+func (c *Client) applyHostsEntries(cfg *WebsiteBlockerConfig) {
+	c.hostsMu.Lock()
+	defer c.hostsMu.Unlock()
+	c.applyHostsEntriesLocked(cfg)
+}
 
-	// sudo ile append et
-	cmd := exec.Command("sudo", "sh", "-c", fmt.Sprintf("echo '%s' >> %s", content, hostsPath))
-	err := cmd.Run()
+// applyHostsEntriesLocked hostsMu tutulurken çağrılmalıdır.
+func (c *Client) applyHostsEntriesLocked(cfg *WebsiteBlockerConfig) {
+	entries := cfg.hostsEntries()
+	changed, err := newHostsFile(cfg.Settings.HostsFile).Apply(entries)
 	if err != nil {
 		log.Printf("⚠️ Hosts dosyasına yazılamadı: %v", err)
-	} else {
-		log.Printf("✅ %d website hosts dosyasına eklendi", len(c.websiteBlocker.BlockedWebsites))
+		return
+	}
+	if changed {
+		log.Printf("✅ %d website hosts dosyasına eklendi", len(cfg.BlockedWebsites))
+	}
+
+	c.hostsApplied = make(map[string]bool, len(entries))
+	for _, e := range entries {
+		c.hostsApplied[e.IP+" "+e.Host] = true
 	}
 }
 
 func (c *Client) ensureWebsitesBlocked() {
 	// Hosts dosyasını kontrol et, eğer değiştirilmişse tekrar ekle
-	var hostsPath string
-	if runtime.GOOS == "windows" {
-		hostsPath = "C:\\Windows\\System32\\drivers\\etc\\hosts"
-	} else {
-		hostsPath = "/etc/hosts"
+	cfg := c.websiteBlockerConfig()
+	if cfg == nil || !cfg.Settings.WebsiteBlockerEnabled {
+		return
 	}
 
-	data, err := ioutil.ReadFile(hostsPath)
+	c.hostsMu.Lock()
+	defer c.hostsMu.Unlock()
+
+	current, err := newHostsFile(cfg.Settings.HostsFile).Entries()
 	if err != nil {
 		log.Printf("⚠️ Hosts dosyası okunamadı: %v", err)
 		return
 	}
+	present := make(map[string]bool, len(current))
+	for _, e := range current {
+		present[e.IP+" "+e.Host] = true
+	}
 
-	hostsContent := string(data)
-
-	// Kontrol et: yasaklı siteler hala var mı?
-	for _, website := range c.websiteBlocker.BlockedWebsites {
-		for _, url := range website.URLs {
-			if !strings.Contains(hostsContent, url) {
-				log.Printf("🔄 %s tekrar engelleniyor...", url)
-				c.blockWebsites()
-				return
-			}
+	// Daha önce yazılmış bir kayıt silindiyse dosya kurcalanmıştır;
+	// config'e yeni eklenen siteler sadece yazılır
+	for _, e := range cfg.hostsEntries() {
+		key := e.IP + " " + e.Host
+		if !present[key] && c.hostsApplied[key] {
+			log.Printf("🔄 %s tekrar engelleniyor...", e.Host)
+			c.emitEvent(BlockerEvent{Type: eventHostsTampered, Site: e.Site, URL: e.Host})
+			break
 		}
 	}
+
+	// Bölüm zaten güncelse dosyaya yazılmaz
+	c.applyHostsEntriesLocked(cfg)
 }
 
 func (c *Client) checkAndCloseBrowserTabs() {
 	// Browser process'lerini kontrol et
 	browsers := []string{"Google Chrome", "Firefox", "Safari", "Microsoft Edge"}
 
+	var processes []Process
+	if runtime.GOOS == "linux" {
+		var err error
+		if processes, err = listProcesses(); err != nil {
+			log.Printf("⚠️ Process listesi alınamadı: %v", err)
+			return
+		}
+	}
+
 	for _, browser := range browsers {
 		switch runtime.GOOS {
 		case "darwin":
 			c.checkMacOSBrowser(browser)
 		case "linux":
-			c.checkLinuxBrowser(browser)
+			c.checkLinuxBrowser(browser, processes)
 		case "windows":
 			c.checkWindowsBrowser(browser)
 		}
@@ -722,8 +1174,10 @@ func (c *Client) checkAndCloseBrowserTabs() {
 
 func (c *Client) checkMacOSBrowser(browserName string) {
 	// AppleScript ile browser tab'larını kontrol et
-	for _, website := range c.websiteBlocker.BlockedWebsites {
-		for _, url := range website.URLs {
+	cfg := c.websiteBlockerConfig()
+	for _, website := range cfg.BlockedWebsites {
+		// Normalize edilmiş alan adları script'e güvenle gömülebilir
+		for _, url := range website.domains() {
 			// Chrome için
 			if browserName == "Google Chrome" {
 				script := fmt.Sprintf(`
@@ -739,9 +1193,10 @@ func (c *Client) checkMacOSBrowser(browserName string) {
 						repeat with t in tabsToClose
 							close t
 						end repeat
+						return count of tabsToClose
 					end tell
 				`, url)
-				c.runAppleScript(script, website.WarningMessage)
+				c.runAppleScript(script, browserName, website, url)
 			}
 
 			// Firefox için
@@ -750,12 +1205,13 @@ func (c *Client) checkMacOSBrowser(browserName string) {
 				cmd := exec.Command("pgrep", "-f", "firefox")
 				output, err := cmd.Output()
 				if err == nil && len(output) > 0 {
-					if c.websiteBlocker.Settings.ShowWarnings {
+					if cfg.Settings.ShowWarnings {
 						log.Printf("🚫 %s", website.WarningMessage)
 					}
-					if c.websiteBlocker.Settings.CloseBrowserTabs {
+					if cfg.Settings.CloseBrowserTabs {
 						log.Printf("🔧 Firefox yasaklı site nedeniyle kapatılıyor...")
 						exec.Command("osascript", "-e", "quit app \"Firefox\"").Run()
+						c.emitEvent(BlockerEvent{Type: eventSiteBlocked, Site: website.Name, URL: url, Browser: browserName})
 					}
 				}
 			}
@@ -764,47 +1220,80 @@ func (c *Client) checkMacOSBrowser(browserName string) {
 			if browserName == "Safari" {
 				script := fmt.Sprintf(`
 					tell application "Safari"
+						set closedCount to 0
 						repeat with w in windows
 							repeat with t in tabs of w
 								if URL of t contains "%s" then
 									close t
+									set closedCount to closedCount + 1
 								end if
 							end repeat
 						end repeat
+						return closedCount
 					end tell
 				`, url)
-				c.runAppleScript(script, website.WarningMessage)
+				c.runAppleScript(script, browserName, website, url)
 			}
 		}
 	}
 }
 
-func (c *Client) runAppleScript(script, warningMessage string) {
-	cmd := exec.Command("osascript", "-e", script)
-	err := cmd.Run()
-	if err == nil {
-		if c.websiteBlocker.Settings.ShowWarnings {
-			log.Printf("🚫 %s", warningMessage)
-		}
-		log.Printf("✅ Yasaklı tab kapatıldı")
+// runAppleScript tab kapatma script'ini çalıştırır. Script kapatılan tab
+// sayısını döner; sadece tab kapatıldıysa uyarı ve olay üretilir.
+func (c *Client) runAppleScript(script, browserName string, website BlockedWebsite, url string) {
+	cfg := c.websiteBlockerConfig()
+
+	output, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		return
+	}
+	closed, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	if closed == 0 {
+		return
 	}
-}
 
-func (c *Client) checkLinuxBrowser(browserName string) {
-	// Linux için basit process kill
-	processNames := map[string]string{
-		"Google Chrome": "chrome",
-		"Firefox":       "firefox",
+	if cfg.Settings.ShowWarnings {
+		log.Printf("🚫 %s", website.WarningMessage)
+		c.notify(Notification{Key: website.Name, Title: "🚫 " + website.Name, Body: website.WarningMessage})
 	}
+	log.Printf("✅ Yasaklı tab kapatıldı (%s, %d tab)", browserName, closed)
+	c.emitEvent(BlockerEvent{
+		Type:    eventSiteBlocked,
+		Site:    website.Name,
+		URL:     url,
+		Browser: browserName,
+		Message: website.WarningMessage,
+	})
+}
 
-	if processName, exists := processNames[browserName]; exists {
-		cmd := exec.Command("pgrep", "-f", processName)
-		output, err := cmd.Output()
-		if err == nil && len(output) > 0 {
-			log.Printf("🚫 %s tespit edildi, kapatılıyor...", browserName)
-			exec.Command("pkill", "-f", processName).Run()
-		}
+// linuxBrowsers Linux'ta tarayıcıların process adları. Komut satırı deseni
+// (pkill -f) yerine adla eşleşilir; böylece argümanında "chrome" geçen başka
+// process'ler kapatılmaz.
+var linuxBrowsers = map[string]BlockedApp{
+	"Google Chrome": {Processes: []string{"chrome", "chromium", "chromium-browser"}},
+	"Firefox":       {Processes: []string{"firefox", "firefox-esr", "firefox-bin"}},
+}
+
+func (c *Client) checkLinuxBrowser(browserName string, processes []Process) {
+	browser, exists := linuxBrowsers[browserName]
+	if !exists {
+		return
+	}
+	matched := browser.matcher().Find(processes)
+	if len(matched) == 0 || !c.startKill("browser:"+browserName) {
+		return
 	}
+
+	log.Printf("🚫 %s tespit edildi, kapatılıyor...", browserName)
+	// Kapatma nazik kapatma süresi kadar sürebilir; website kontrol turu beklemesin.
+	// İçerik process'lerinin adı farklı olduğu için alt process'ler de kapatılır.
+	go func() {
+		defer c.finishKill("browser:" + browserName)
+		c.killProcesses(browserName, matched, killOptions{
+			Grace: defaultKillGraceSeconds * time.Second,
+			Tree:  true,
+		})
+	}()
 }
 
 func (c *Client) checkWindowsBrowser(browserName string) {
@@ -825,24 +1314,61 @@ func (c *Client) checkWindowsBrowser(browserName string) {
 	}
 }
 
+// unblockWebsites hosts dosyasından sadece client'ın bölümünü kaldırır;
+// dosyada sonradan yapılan diğer değişiklikler korunur.
 func (c *Client) unblockWebsites() {
-	if runtime.GOOS == "windows" {
-		return // Windows için şimdilik skip
+	path := ""
+	if cfg := c.websiteBlockerConfig(); cfg != nil {
+		path = cfg.Settings.HostsFile
 	}
 
-	// Backup'tan restore et
-	if c.websiteBlocker.Settings.BackupHosts {
-		cmd := exec.Command("sudo", "cp", c.hostsBackupPath, "/etc/hosts")
-		err := cmd.Run()
-		if err != nil {
-			log.Printf("⚠️ Hosts dosyası restore edilemedi: %v", err)
-		} else {
-			log.Printf("✅ Hosts dosyası restore edildi")
+	c.hostsMu.Lock()
+	defer c.hostsMu.Unlock()
+
+	removed, err := newHostsFile(path).Remove()
+	if err != nil {
+		log.Printf("⚠️ Hosts dosyasındaki engelleme bölümü kaldırılamadı: %v", err)
+		return
+	}
+	c.hostsApplied = nil
+	if removed {
+		log.Println("✅ Hosts dosyasındaki engelleme bölümü kaldırıldı")
+	}
+}
+
+// recoverHostsFile önceki çalışmadan (çökme, kill -9) kalan hosts bölümünü
+// temizler. Website engelleyici hosts yöntemiyle açıksa bölüm zaten baştan
+// yazılacağı için dokunulmaz.
+func (c *Client) recoverHostsFile() {
+	cfg := c.websiteBlockerConfig()
+	if cfg != nil && cfg.Settings.WebsiteBlockerEnabled && cfg.Settings.BlockingMethod == "hosts" {
+		return
+	}
+
+	path := ""
+	if cfg != nil {
+		path = cfg.Settings.HostsFile
+	}
+	removed, err := newHostsFile(path).Remove()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Hosts dosyası kontrol edilemedi: %v", err)
 		}
+		return
+	}
+	if removed {
+		log.Println("🧹 Önceki çalışmadan kalan hosts engelleme bölümü temizlendi")
 	}
 }
 
 func main() {
+	// Alt komutlar (validate-config, ...)
+	if len(os.Args) > 1 {
+		if code, ok := runSubcommand(os.Args[1], os.Args[2:]); ok {
+			os.Exit(code)
+		}
+	}
+
 	// Server URL'ini al (argument veya environment variable)
 	// Default olarak local server'ı kullan
 	serverURL := "http://127.0.0.1:5000"
@@ -866,38 +1392,25 @@ func main() {
 	// Client oluştur
 	client := NewClient(serverURL)
 
-	// Graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
-		fmt.Println("\n🛑 Kapatılıyor...")
-		client.Disconnect()
-		os.Exit(0)
-	}()
+	// Graceful shutdown: ilk sinyal düzenli kapatmayı başlatır, ikinci sinyal
+	// (varsayılan davranış geri geldiği için) programı hemen sonlandırır
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Sunucuya bağlan
-	for {
-		err := client.Connect()
-		if err != nil {
-			log.Printf("❌ Bağlantı hatası: %v", err)
-			log.Println("🔄 3 saniye sonra tekrar denenecek...")
-			time.Sleep(3 * time.Second)
-			continue
-		}
-		break
-	}
+	client.Run(ctx)
+	stop()
 
-	// Uygulama engelleyiciyi başlat
-	if client.appBlocker != nil && client.appBlocker.Settings.AppBlockerEnabled {
-		go client.StartAppBlocker()
-	}
-
-	// Website engelleyiciyi başlat
-	if client.websiteBlocker != nil && client.websiteBlocker.Settings.WebsiteBlockerEnabled {
-		go client.StartWebsiteBlocker()
+	fmt.Println("\n🛑 Kapatılıyor...")
+	done := make(chan struct{})
+	go func() {
+		client.Shutdown(shutdownTimeout)
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Println("👋 Client kapatıldı")
+	case <-time.After(2 * shutdownTimeout):
+		log.Println("⚠️ Kapatma zaman aşımına uğradı, çıkılıyor")
+		os.Exit(1)
 	}
-
-	// Ekran yakalamayı başlat
-	client.StartScreenCapture()
 }
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Kareler encode edilmeden önce hassas alanlar karartılır: ayarlardaki sabit
+// dikdörtgenler ve (Linux/X11'de) konumu X sunucusundan alınan seçili
+// uygulama pencereleri. Pencere kuralı varken pencereler listelenemezse kare
+// tamamen karartılır; hassas içerik maskelenmeden makineden çıkmaz.
+
+var errWindowMaskUnsupported = errors.New("pencere maskeleme bu platformda desteklenmiyor")
+
+// screenWindow ekrandaki bir uygulama penceresi.
+type screenWindow struct {
+	ID      uint32
+	Class   []string // WM_CLASS: instance ve class
+	Title   string
+	Process string
+}
+
+// windowSource ekrandaki uygulama pencerelerini ve konumlarını verir.
+type windowSource interface {
+	Windows(withTitles bool) ([]screenWindow, error)
+	// Bounds pencerenin çerçevesiyle birlikte ekrandaki alanı; pencere
+	// görünür değilse (simge durumunda, başka masaüstünde) boş döner.
+	Bounds(id uint32) (image.Rectangle, error)
+	Close() error
+}
+
+type windowRule struct {
+	class   string
+	title   *regexp.Regexp
+	process string
+}
+
+func (r windowRule) matches(w screenWindow) bool {
+	if r.class != "" {
+		found := false
+		for _, class := range w.Class {
+			if strings.EqualFold(class, r.class) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.process != "" && !strings.EqualFold(w.Process, r.process) {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(w.Title) {
+		return false
+	}
+	return true
+}
+
+type screenMasker struct {
+	mu      sync.Mutex
+	display string
+	rects   []image.Rectangle
+	rules   []windowRule
+	titles  bool // başlık kuralı var, başlıklar her karede okunur
+	broken  bool // geçersiz veya desteklenmeyen kural var, kareler tamamen karartılır
+	source  windowSource
+	failing bool // pencere listesi alınamıyor (bir kez loglanır)
+}
+
+func newScreenMasker(settings PrivacySettings, display string) *screenMasker {
+	m := &screenMasker{display: display}
+
+	for i, r := range settings.MaskRects {
+		if r.Width <= 0 || r.Height <= 0 {
+			log.Printf("⚠️ privacy.mask_rects[%d] geçersiz (genişlik ve yükseklik pozitif olmalı), atlandı", i)
+			continue
+		}
+		m.rects = append(m.rects, image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height))
+	}
+
+	for i, w := range settings.MaskWindows {
+		rule := windowRule{class: w.Class, process: w.Process}
+		if w.Title != "" {
+			re, err := regexp.Compile(w.Title)
+			if err != nil {
+				log.Printf("❌ privacy.mask_windows[%d].title geçersiz, kareler tamamen karartılacak: %v", i, err)
+				m.broken = true
+				continue
+			}
+			rule.title = re
+			m.titles = true
+		}
+		if rule.class == "" && rule.process == "" && rule.title == nil {
+			log.Printf("⚠️ privacy.mask_windows[%d] boş (class, title veya process gerekli), atlandı", i)
+			continue
+		}
+		m.rules = append(m.rules, rule)
+	}
+
+	if len(m.rules) > 0 {
+		source, err := newWindowSource(display)
+		switch {
+		case errors.Is(err, errWindowMaskUnsupported):
+			// Kurallar sessizce atlanırsa korunması istenen pencereler görünür kalır
+			log.Printf("❌ %v, mask_windows tanımlı olduğu için kareler tamamen karartılacak", err)
+			m.broken = true
+		case err != nil:
+			m.windowsFailed(err)
+		default:
+			m.source = source
+		}
+	}
+
+	if len(m.rects) > 0 || len(m.rules) > 0 {
+		log.Printf("🕶️ Gizlilik maskeleme aktif: %d alan, %d pencere kuralı", len(m.rects), len(m.rules))
+	}
+	return m
+}
+
+func (m *screenMasker) enabled() bool {
+	return len(m.rects) > 0 || len(m.rules) > 0 || m.broken
+}
+
+// Apply maskelenecek alanları karartılmış kareyi döner. Görüntü yerinde
+// değiştirilebilir.
+func (m *screenMasker) Apply(img image.Image) image.Image {
+	if m == nil || !m.enabled() {
+		return img
+	}
+	if m.broken {
+		return maskImage(img, []image.Rectangle{img.Bounds()})
+	}
+
+	rects := m.rects
+	if len(m.rules) > 0 {
+		windows, err := m.windowRects()
+		if err != nil {
+			return maskImage(img, []image.Rectangle{img.Bounds()})
+		}
+		rects = append(append([]image.Rectangle(nil), rects...), windows...)
+	}
+	return maskImage(img, rects)
+}
+
+// windowRects kurallara uyan görünür pencerelerin alanlarını döner. Hata
+// durumunda bağlantı kapatılır ve bir sonraki karede yeniden kurulur.
+func (m *screenMasker) windowRects() ([]image.Rectangle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.source == nil {
+		source, err := newWindowSource(m.display)
+		if err != nil {
+			m.windowsFailed(err)
+			return nil, err
+		}
+		m.source = source
+	}
+
+	rects, err := m.matchWindows()
+	if err != nil {
+		m.source.Close()
+		m.source = nil
+		m.windowsFailed(err)
+		return nil, err
+	}
+	if m.failing {
+		m.failing = false
+		log.Println("✅ Pencere maskeleme tekrar çalışıyor")
+	}
+	return rects, nil
+}
+
+func (m *screenMasker) matchWindows() ([]image.Rectangle, error) {
+	windows, err := m.source.Windows(m.titles)
+	if err != nil {
+		return nil, err
+	}
+
+	var rects []image.Rectangle
+	for _, w := range windows {
+		for _, rule := range m.rules {
+			if !rule.matches(w) {
+				continue
+			}
+			bounds, err := m.source.Bounds(w.ID)
+			if err != nil {
+				return nil, err
+			}
+			if !bounds.Empty() {
+				rects = append(rects, bounds)
+			}
+			break
+		}
+	}
+	return rects, nil
+}
+
+func (m *screenMasker) windowsFailed(err error) {
+	if !m.failing {
+		m.failing = true
+		log.Printf("⚠️ Pencere konumları alınamadı, kareler tamamen karartılıyor: %v", err)
+	}
+}
+
+func (m *screenMasker) Close() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.source != nil {
+		m.source.Close()
+		m.source = nil
+	}
+}
+
+// maskImage alanları siyaha boyar. Görüntü yazılabilir değilse (örn. JPEG'den
+// çözülmüş YCbCr) önce RGBA kopyası alınır.
+func maskImage(img image.Image, rects []image.Rectangle) image.Image {
+	bounds := img.Bounds()
+	var visible []image.Rectangle
+	for _, r := range rects {
+		if r = r.Intersect(bounds); !r.Empty() {
+			visible = append(visible, r)
+		}
+	}
+	if len(visible) == 0 {
+		return img
+	}
+
+	dst, ok := img.(draw.Image)
+	if !ok {
+		rgba := image.NewRGBA(bounds)
+		draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
+		dst = rgba
+	}
+	for _, r := range visible {
+		draw.Draw(dst, r, image.Black, image.Point{}, draw.Src)
+	}
+	return dst
+}
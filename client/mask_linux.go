@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"time"
+)
+
+// Önceden tanımlı X atomları
+const (
+	x11AtomCardinal = 6
+	x11AtomString   = 31
+	x11AtomWindow   = 33
+	x11AtomWMName   = 39
+	x11AtomWMClass  = 67
+)
+
+// x11WindowSource pencereleri pencere yöneticisinin _NET_CLIENT_LIST
+// listesinden okur. Sınıf ve process pencere ömrü boyunca değişmediği için
+// önbelleğe alınır; başlık ve konum her karede sorgulanır.
+type x11WindowSource struct {
+	x     *x11Conn
+	root  uint32
+	atoms map[string]uint32
+	cache map[uint32]screenWindow
+}
+
+func newWindowSource(display string) (windowSource, error) {
+	x, err := dialX11(display)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &x11WindowSource{
+		x:     x,
+		root:  x.defaultScreen().root,
+		atoms: make(map[string]uint32),
+		cache: make(map[uint32]screenWindow),
+	}
+	for _, name := range []string{"_NET_CLIENT_LIST", "_NET_WM_PID", "_NET_WM_NAME", "_NET_FRAME_EXTENTS", "UTF8_STRING"} {
+		atom, err := x.internAtom(name)
+		if err != nil {
+			x.Close()
+			return nil, err
+		}
+		s.atoms[name] = atom
+	}
+	if s.atoms["_NET_CLIENT_LIST"] == 0 {
+		x.Close()
+		return nil, errors.New("pencere yöneticisi _NET_CLIENT_LIST desteklemiyor")
+	}
+	return s, nil
+}
+
+func (s *x11WindowSource) Close() error {
+	return s.x.Close()
+}
+
+func (s *x11WindowSource) Windows(withTitles bool) ([]screenWindow, error) {
+	typ, _, value, err := s.x.getProperty(s.root, s.atoms["_NET_CLIENT_LIST"], x11AtomWindow, 1<<16)
+	if err != nil {
+		return nil, err
+	}
+	if typ == 0 {
+		return nil, errors.New("pencere yöneticisi pencere listesini yayınlamıyor")
+	}
+
+	seen := make(map[uint32]bool)
+	var windows []screenWindow
+	for i := 0; i+4 <= len(value); i += 4 {
+		id := x11Order.Uint32(value[i:])
+		seen[id] = true
+
+		w, ok := s.cache[id]
+		if !ok {
+			if w, err = s.describe(id); err != nil {
+				if isX11WindowGone(err) {
+					continue
+				}
+				return nil, err
+			}
+			s.cache[id] = w
+		}
+		if withTitles {
+			if w.Title, err = s.title(id); err != nil {
+				if isX11WindowGone(err) {
+					continue
+				}
+				return nil, err
+			}
+		}
+		windows = append(windows, w)
+	}
+
+	for id := range s.cache {
+		if !seen[id] {
+			delete(s.cache, id)
+		}
+	}
+	return windows, nil
+}
+
+func (s *x11WindowSource) describe(id uint32) (screenWindow, error) {
+	w := screenWindow{ID: id}
+
+	_, _, class, err := s.x.getProperty(id, x11AtomWMClass, x11AtomString, 256)
+	if err != nil {
+		return w, err
+	}
+	for _, part := range strings.Split(strings.TrimRight(string(class), "\x00"), "\x00") {
+		if part != "" {
+			w.Class = append(w.Class, part)
+		}
+	}
+
+	if atom := s.atoms["_NET_WM_PID"]; atom != 0 {
+		_, _, pid, err := s.x.getProperty(id, atom, x11AtomCardinal, 1)
+		if err != nil {
+			return w, err
+		}
+		if len(pid) == 4 {
+			if p, _ := readProcess(int(x11Order.Uint32(pid)), time.Time{}); p.Name != "" {
+				w.Process = p.Name
+			}
+		}
+	}
+	return w, nil
+}
+
+func (s *x11WindowSource) title(id uint32) (string, error) {
+	if atom := s.atoms["_NET_WM_NAME"]; atom != 0 {
+		typ, _, name, err := s.x.getProperty(id, atom, s.atoms["UTF8_STRING"], 1024)
+		if err != nil {
+			return "", err
+		}
+		if typ != 0 {
+			return string(name), nil
+		}
+	}
+	_, _, name, err := s.x.getProperty(id, x11AtomWMName, x11AtomString, 1024)
+	return string(name), err
+}
+
+func (s *x11WindowSource) Bounds(id uint32) (image.Rectangle, error) {
+	bounds, err := s.bounds(id)
+	if isX11WindowGone(err) {
+		return image.Rectangle{}, nil
+	}
+	return bounds, err
+}
+
+func (s *x11WindowSource) bounds(id uint32) (image.Rectangle, error) {
+	viewable, err := s.x.windowViewable(id)
+	if err != nil || !viewable {
+		return image.Rectangle{}, err
+	}
+
+	geom, err := s.x.getGeometry(id)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	px, py, err := s.x.translateCoordinates(id, s.root, 0, 0)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	bounds := image.Rect(int(px), int(py), int(px)+int(geom.width), int(py)+int(geom.height))
+
+	// Başlık çubuğu da pencere başlığını gösterir, çerçeve dahil edilir
+	if atom := s.atoms["_NET_FRAME_EXTENTS"]; atom != 0 {
+		_, _, extents, err := s.x.getProperty(id, atom, x11AtomCardinal, 4)
+		if err != nil {
+			return image.Rectangle{}, err
+		}
+		if len(extents) == 16 {
+			bounds.Min.X -= int(x11Order.Uint32(extents[0:]))
+			bounds.Max.X += int(x11Order.Uint32(extents[4:]))
+			bounds.Min.Y -= int(x11Order.Uint32(extents[8:]))
+			bounds.Max.Y += int(x11Order.Uint32(extents[12:]))
+		}
+	}
+	return bounds, nil
+}
+
+// isX11WindowGone pencerenin sorgu sırasında kapatıldığını (BadWindow,
+// BadDrawable) gösterir.
+func isX11WindowGone(err error) bool {
+	var xerr *x11Error
+	return errors.As(err, &xerr) && (xerr.code == 3 || xerr.code == 9)
+}
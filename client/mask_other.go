@@ -0,0 +1,7 @@
+//go:build !linux
+
+package main
+
+func newWindowSource(display string) (windowSource, error) {
+	return nil, errWindowMaskUnsupported
+}
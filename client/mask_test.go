@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"regexp"
+	"testing"
+)
+
+// fakeWindowSource sabit bir pencere listesi ve konumları döner.
+type fakeWindowSource struct {
+	windows    []screenWindow
+	bounds     map[uint32]image.Rectangle
+	err        error // Windows hatası
+	boundsErr  error
+	withTitles bool // son Windows çağrısında başlık istendi mi
+	closed     bool
+}
+
+func (f *fakeWindowSource) Windows(withTitles bool) ([]screenWindow, error) {
+	f.withTitles = withTitles
+	return f.windows, f.err
+}
+
+func (f *fakeWindowSource) Bounds(id uint32) (image.Rectangle, error) {
+	return f.bounds[id], f.boundsErr
+}
+
+func (f *fakeWindowSource) Close() error {
+	f.closed = true
+	return nil
+}
+
+// whiteFrame beyaz bir RGBA kare döner.
+func whiteFrame(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	return img
+}
+
+func isBlack(img image.Image, x, y int) bool {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return r == 0 && g == 0 && b == 0
+}
+
+// assertMasked karedeki her pikselin sadece rects içindeyse siyah olduğunu doğrular.
+func assertMasked(t *testing.T, name string, img image.Image, rects ...image.Rectangle) {
+	t.Helper()
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			want := false
+			for _, r := range rects {
+				if (image.Point{x, y}).In(r) {
+					want = true
+					break
+				}
+			}
+			if got := isBlack(img, x, y); got != want {
+				t.Errorf("%s: (%d,%d) siyah = %v, beklenen %v", name, x, y, got, want)
+				return
+			}
+		}
+	}
+}
+
+func TestWindowRuleMatches(t *testing.T) {
+	window := screenWindow{ID: 1, Class: []string{"keepassxc", "KeePassXC"}, Title: "Passwords - KeePassXC", Process: "keepassxc"}
+	tests := []struct {
+		name string
+		rule windowRule
+		want bool
+	}{
+		{"class instance", windowRule{class: "keepassxc"}, true},
+		{"class harf duyarsız", windowRule{class: "KEEPASSXC"}, true},
+		{"class eşleşmez", windowRule{class: "firefox"}, false},
+		{"process", windowRule{process: "KeePassXC"}, true},
+		{"process eşleşmez", windowRule{process: "firefox"}, false},
+		{"başlık", windowRule{title: regexp.MustCompile(`^Passwords`)}, true},
+		{"başlık harf duyarlı", windowRule{title: regexp.MustCompile(`^passwords`)}, false},
+		{"tüm alanlar", windowRule{class: "keepassxc", process: "keepassxc", title: regexp.MustCompile(`KeePass`)}, true},
+		{"alanlardan biri eşleşmez", windowRule{class: "keepassxc", process: "firefox"}, false},
+		{"başlık eşleşmez", windowRule{class: "keepassxc", title: regexp.MustCompile(`Bank`)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.matches(window); got != tt.want {
+			t.Errorf("%s: eşleşme %v, beklenen %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Sınıfı olmayan pencere sınıf kuralına uymaz
+	if (windowRule{class: "keepassxc"}).matches(screenWindow{Process: "keepassxc"}) {
+		t.Error("sınıfı olmayan pencere eşleşmemeli")
+	}
+}
+
+func TestMaskImage(t *testing.T) {
+	// RGBA yerinde boyanır, ekran dışındaki kısım kırpılır
+	img := whiteFrame(8, 6)
+	out := maskImage(img, []image.Rectangle{image.Rect(6, 4, 12, 10), image.Rect(-5, -5, 1, 1)})
+	if out != image.Image(img) {
+		t.Fatal("yazılabilir kare yerinde değiştirilmeli")
+	}
+	assertMasked(t, "kırpma", out, image.Rect(6, 4, 8, 6), image.Rect(0, 0, 1, 1))
+
+	// Tamamen ekran dışındaki alan kareye dokunmaz
+	img = whiteFrame(8, 6)
+	if out := maskImage(img, []image.Rectangle{image.Rect(20, 20, 30, 30), {}}); out != image.Image(img) {
+		t.Fatal("görünür alan yokken kare aynen dönmeli")
+	}
+	assertMasked(t, "ekran dışı", img)
+
+	// YCbCr yazılabilir değil: RGBA kopyası boyanır, kaynak değişmez
+	ycbcr := image.NewYCbCr(image.Rect(0, 0, 8, 6), image.YCbCrSubsampleRatio420)
+	for i := range ycbcr.Y {
+		ycbcr.Y[i] = 0xff
+	}
+	for i := range ycbcr.Cb {
+		ycbcr.Cb[i], ycbcr.Cr[i] = 0x80, 0x80
+	}
+	out = maskImage(ycbcr, []image.Rectangle{image.Rect(2, 2, 4, 4)})
+	rgba, ok := out.(*image.RGBA)
+	if !ok {
+		t.Fatalf("YCbCr kare için RGBA kopyası bekleniyordu, %T döndü", out)
+	}
+	if rgba.Bounds() != ycbcr.Bounds() {
+		t.Fatalf("kopyanın sınırları %v, beklenen %v", rgba.Bounds(), ycbcr.Bounds())
+	}
+	assertMasked(t, "YCbCr", rgba, image.Rect(2, 2, 4, 4))
+	if c := color.RGBAModel.Convert(rgba.At(0, 0)).(color.RGBA); c.R < 0xf0 || c.G < 0xf0 || c.B < 0xf0 {
+		t.Errorf("maskelenmeyen piksel kopyalanmadı: %v", c)
+	}
+	if isBlack(ycbcr, 2, 2) {
+		t.Error("kaynak YCbCr kare değiştirilmemeli")
+	}
+
+	// Sıfırdan başlamayan sınırlar
+	sub := whiteFrame(8, 6).SubImage(image.Rect(4, 2, 8, 6))
+	assertMasked(t, "alt görüntü", maskImage(sub, []image.Rectangle{image.Rect(0, 0, 5, 3)}), image.Rect(4, 2, 5, 3))
+}
+
+func testMasker(source windowSource, rects []image.Rectangle, rules ...windowRule) *screenMasker {
+	m := &screenMasker{rects: rects, rules: rules, source: source, display: "invalid-display:99"}
+	for _, r := range rules {
+		if r.title != nil {
+			m.titles = true
+		}
+	}
+	return m
+}
+
+func TestScreenMaskerApply(t *testing.T) {
+	source := &fakeWindowSource{
+		windows: []screenWindow{
+			{ID: 1, Class: []string{"keepassxc"}, Title: "Passwords"},
+			{ID: 2, Class: []string{"firefox"}, Title: "Bank - Firefox"},
+			{ID: 3, Class: []string{"firefox"}, Title: "News - Firefox"},
+			{ID: 4, Class: []string{"keepassxc"}, Title: "simge durumunda"},
+		},
+		bounds: map[uint32]image.Rectangle{
+			1: image.Rect(0, 0, 2, 2),
+			2: image.Rect(6, 4, 20, 20),
+			3: image.Rect(3, 3, 5, 5),
+			// 4: görünür değil, boş alan
+		},
+	}
+	m := testMasker(source, []image.Rectangle{image.Rect(3, 0, 4, 1)},
+		windowRule{class: "keepassxc"},
+		windowRule{class: "firefox", title: regexp.MustCompile(`^Bank`)},
+	)
+
+	out := m.Apply(whiteFrame(8, 6))
+	assertMasked(t, "pencereler", out, image.Rect(0, 0, 2, 2), image.Rect(6, 4, 8, 6), image.Rect(3, 0, 4, 1))
+	if !source.withTitles {
+		t.Error("başlık kuralı varken başlıklar istenmeli")
+	}
+	if len(m.rects) != 1 {
+		t.Errorf("sabit alanlar değiştirilmemeli: %v", m.rects)
+	}
+
+	// Pencere listesi alınamazsa kare tamamen karartılır ve bağlantı kapatılır
+	source.err = errors.New("X bağlantısı koptu")
+	out = m.Apply(whiteFrame(8, 6))
+	assertMasked(t, "liste hatası", out, out.Bounds())
+	if !source.closed || m.source != nil || !m.failing {
+		t.Errorf("hatadan sonra kaynak kapatılmalı: closed %v, source %v, failing %v", source.closed, m.source, m.failing)
+	}
+
+	// Bağlantı yeniden kurulamazken de kare karartılır
+	assertMasked(t, "bağlantı yok", m.Apply(whiteFrame(8, 6)), image.Rect(0, 0, 8, 6))
+
+	// Konum alınamazsa da kare karartılır
+	source = &fakeWindowSource{windows: source.windows, bounds: source.bounds, boundsErr: errors.New("pencere yok")}
+	m.source = source
+	assertMasked(t, "konum hatası", m.Apply(whiteFrame(8, 6)), image.Rect(0, 0, 8, 6))
+
+	// Kaynak düzelince normal maskelemeye dönülür
+	source = &fakeWindowSource{windows: source.windows, bounds: source.bounds}
+	m.source = source
+	assertMasked(t, "düzeldi", m.Apply(whiteFrame(8, 6)), image.Rect(0, 0, 2, 2), image.Rect(6, 4, 8, 6), image.Rect(3, 0, 4, 1))
+	if m.failing {
+		t.Error("kaynak düzelince failing sıfırlanmalı")
+	}
+}
+
+func TestScreenMaskerBroken(t *testing.T) {
+	// Geçersiz başlık kuralı: pencere listesine bakılmadan kare karartılır
+	m := newScreenMasker(PrivacySettings{MaskWindows: []MaskWindow{{Title: "("}}}, "invalid-display:99")
+	if !m.broken || !m.enabled() {
+		t.Fatalf("geçersiz kuralla maskeleme bozuk olmalı: broken %v", m.broken)
+	}
+	ycbcr := image.NewYCbCr(image.Rect(0, 0, 4, 4), image.YCbCrSubsampleRatio444)
+	assertMasked(t, "bozuk kural", m.Apply(ycbcr), ycbcr.Bounds())
+
+	source := &fakeWindowSource{}
+	m = testMasker(source, nil, windowRule{class: "x"})
+	m.broken = true
+	assertMasked(t, "broken", m.Apply(whiteFrame(4, 4)), image.Rect(0, 0, 4, 4))
+
+	// Kural yoksa kare olduğu gibi döner
+	img := whiteFrame(4, 4)
+	var nilMasker *screenMasker
+	for name, masker := range map[string]*screenMasker{"nil": nilMasker, "boş": newScreenMasker(PrivacySettings{}, "")} {
+		if out := masker.Apply(img); out != image.Image(img) {
+			t.Errorf("%s: kare değiştirilmemeli", name)
+		}
+	}
+	assertMasked(t, "kural yok", img)
+}
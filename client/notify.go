@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+)
+
+// Notifier uyarıları bilgisayarı kullanan kişiye gösterir. Linux'ta masaüstü
+// bildirimleri (D-Bus) kullanılır; desteklenmeyen sistemlerde ve testlerde
+// logNotifier sadece log'a yazar.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+const (
+	notifyUrgencyLow      = 0
+	notifyUrgencyNormal   = 1
+	notifyUrgencyCritical = 2
+)
+
+type Notification struct {
+	// Aynı anahtarlı önceki bildirimin yerine geçer (ör. uygulama adı)
+	Key     string
+	Title   string
+	Body    string
+	Urgency int
+	// Kullanıcı kapatana kadar ekranda kalır
+	Persistent bool
+}
+
+type logNotifier struct{}
+
+func (logNotifier) Notify(n Notification) error {
+	log.Printf("🔔 %s: %s", n.Title, n.Body)
+	return nil
+}
+
+// notify bildirimi gösterir. Bildirim sistemi çalışmıyorsa sadece ilk hata
+// loglanır; tekrar denemesi gereken çağıranlar için hata döner.
+func (c *Client) notify(n Notification) error {
+	if c.notifier == nil {
+		return nil
+	}
+	if err := c.notifier.Notify(n); err != nil {
+		if !c.notifyFailed.Swap(true) {
+			log.Printf("⚠️ Masaüstü bildirimi gösterilemedi: %v", err)
+		}
+		return err
+	}
+	c.notifyFailed.Store(false)
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sync"
+)
+
+// dbusNotifier org.freedesktop.Notifications servisine bildirim gönderir.
+// Bağlantı ilk bildirimde kurulur, koparsa bir sonrakinde yeniden denenir.
+type dbusNotifier struct {
+	mu   sync.Mutex
+	conn *dbusConn
+	ids  map[string]uint32 // anahtar -> son bildirim ID'si (replaces_id)
+}
+
+func newNotifier() Notifier {
+	return &dbusNotifier{ids: make(map[string]uint32)}
+}
+
+const notifyExpireMillis = 10000
+
+func (d *dbusNotifier) Notify(n Notification) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		conn, err := dialSessionBus()
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+	}
+
+	// Notify(app_name s, replaces_id u, app_icon s, summary s, body s,
+	//        actions as, hints a{sv}, expire_timeout i) -> id u
+	body := dbusEncoder{}
+	body.string("Screen Recorder")
+	body.uint32(d.ids[n.Key])
+	body.string("dialog-warning")
+	body.string(n.Title)
+	body.string(n.Body)
+	body.array(4, func() {})
+	body.array(8, func() {
+		body.align(8)
+		body.string("urgency")
+		body.signature("y")
+		body.byte(byte(n.Urgency))
+	})
+	expire := uint32(notifyExpireMillis)
+	if n.Persistent {
+		expire = 0
+	}
+	body.uint32(expire)
+
+	sig, reply, err := d.conn.call("org.freedesktop.Notifications", "/org/freedesktop/Notifications",
+		"org.freedesktop.Notifications", "Notify", "susssasa{sv}i", body.buf)
+	if err != nil {
+		d.conn.Close()
+		d.conn = nil
+		return err
+	}
+	if sig == "u" && n.Key != "" {
+		if id, err := reply.uint32(); err == nil {
+			d.ids[n.Key] = id
+		}
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package main
+
+func newNotifier() Notifier {
+	return logNotifier{}
+}
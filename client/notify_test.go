@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingNotifier gösterilen bildirimleri kaydeder.
+type recordingNotifier struct {
+	mu    sync.Mutex
+	sent  []Notification
+	fails int // sonraki bu kadar bildirim hata döner
+}
+
+func (r *recordingNotifier) Notify(n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fails > 0 {
+		r.fails--
+		return errors.New("bildirim servisi yok")
+	}
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func (r *recordingNotifier) take() []Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sent := r.sent
+	r.sent = nil
+	return sent
+}
+
+// exitedProcess çalışıp çıkmış (PID'i artık olmayan) bir process döner.
+func exitedProcess(t *testing.T) Process {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return Process{PID: cmd.Process.Pid, Name: "game"}
+}
+
+func TestHandleBlockedAppNotifications(t *testing.T) {
+	cfg, errs := parseAppBlockerConfig([]byte(`{
+		"blocked_applications": [{"name": "Game", "processes": ["game"], "warning_message": "Oyun yasak"}],
+		"settings": {"check_interval_seconds": 5, "max_warnings": 3, "show_warnings": true,
+			"kill_grace_seconds": 1, "app_blocker_enabled": true}
+	}`))
+	if err := errs.Err(); err != nil {
+		t.Fatal(err)
+	}
+	notifier := &recordingNotifier{}
+	c := &Client{
+		appBlocker:    cfg,
+		warningCounts: make(map[string]int),
+		notifier:      notifier,
+		now:           time.Now,
+	}
+	app := cfg.BlockedApplications[0]
+
+	for warning := 1; warning <= 2; warning++ {
+		if results := c.handleBlockedApp(app, []Process{exitedProcess(t)}); results != nil {
+			t.Fatalf("%d. uyarıda kapatılmamalı: %v", warning, results)
+		}
+		sent := notifier.take()
+		want := fmt.Sprintf("Uyarı %d/3", warning)
+		if len(sent) != 1 || !strings.Contains(sent[0].Body, want) || !strings.Contains(sent[0].Body, "Oyun yasak") {
+			t.Fatalf("%d. uyarı bildirimi %+v, %q bekleniyordu", warning, sent, want)
+		}
+		if sent[0].Key != "Game" || sent[0].Urgency != notifyUrgencyNormal {
+			t.Errorf("uyarı bildirimi anahtarı/önemi: %+v", sent[0])
+		}
+	}
+
+	// Son uyarıda uygulama kapatılır ve uyarı yerine kapatma bildirimi gösterilir
+	results := c.handleBlockedApp(app, []Process{exitedProcess(t)})
+	if !killSucceeded(results) {
+		t.Fatalf("kapatma sonuçları %v", results)
+	}
+	sent := notifier.take()
+	if len(sent) != 1 || sent[0].Title != "🔧 Game kapatıldı" || sent[0].Urgency != notifyUrgencyCritical || sent[0].Key != "Game" {
+		t.Fatalf("kapatma bildirimi %+v", sent)
+	}
+
+	// Sayaç sıfırlanır
+	c.handleBlockedApp(app, []Process{exitedProcess(t)})
+	if sent := notifier.take(); len(sent) != 1 || !strings.Contains(sent[0].Body, "Uyarı 1/3") {
+		t.Fatalf("sıfırlamadan sonraki bildirim %+v", sent)
+	}
+
+	// Kapatma başarısızsa kapatıldı bildirimi gösterilmez
+	c.warningCounts["Game"] = 2
+	results = c.handleBlockedApp(app, []Process{{PID: os.Getpid(), Name: "self"}})
+	if killSucceeded(results) {
+		t.Fatalf("client'ın kendisi kapatılmamalı: %v", results)
+	}
+	if sent := notifier.take(); len(sent) != 0 {
+		t.Fatalf("başarısız kapatmada bildirim gösterildi: %+v", sent)
+	}
+
+	// show_warnings kapalıyken bildirim gösterilmez
+	cfg.Settings.ShowWarnings = false
+	c.handleBlockedApp(app, []Process{exitedProcess(t)})
+	if sent := notifier.take(); len(sent) != 0 {
+		t.Fatalf("show_warnings kapalıyken bildirim gösterildi: %+v", sent)
+	}
+}
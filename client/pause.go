@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Gizlilik molası: izlenen kullanıcı kaydı kısa süreliğine duraklatabilir.
+// Molalar günlük bütçeden düşülür, nedeniyle birlikte sunucuya olay olarak
+// raporlanır ve süre dolunca kayıt kendiliğinden devam eder. Kontrol
+// "pause"/"resume" alt komutlarıyla (Unix soketi üzerinden) veya SIGUSR1
+// sinyaliyle (mola varsa bitirir, yoksa varsayılan süreyle başlatır) yapılır.
+
+const (
+	taskPauseControl   = "pause_control"
+	pauseCheckInterval = time.Second
+	pauseRequestWait   = 5 * time.Second
+)
+
+var errPrivacyPaused = errors.New("kullanıcı gizlilik molasında")
+
+// pauseState diske yazılır; yeniden başlatmada günlük bütçe ve süren mola korunur.
+// Mola süresi başlarken bütçeden düşülür, erken bitirilirse kalan kısmı iade edilir.
+// Mola başladığı günün bütçesine aittir: gece yarısını geçen molanın iadesi
+// yeni günün bütçesine yansımaz.
+type pauseState struct {
+	mu   sync.Mutex
+	path string
+
+	PeriodStart time.Time `json:"period_start"`
+	UsedSeconds float64   `json:"used_seconds"`
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+func loadPauseState(path string) *pauseState {
+	s := &pauseState{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Mola durumu okunamadı: %v", err)
+		}
+		return s
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		log.Printf("⚠️ Mola durumu bozuk, sıfırdan başlanıyor: %v", err)
+		*s = pauseState{path: path}
+	}
+	return s
+}
+
+func (s *pauseState) rolloverLocked(now time.Time) {
+	if start := quotaPeriodStart(now, 0); !s.PeriodStart.Equal(start) {
+		s.PeriodStart = start
+		s.UsedSeconds = 0
+	}
+}
+
+// budgetLeft bugün kullanılabilecek mola süresini döner.
+func (s *pauseState) budgetLeft(now time.Time, budget time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rolloverLocked(now)
+	return s.budgetLeftLocked(budget)
+}
+
+func (s *pauseState) budgetLeftLocked(budget time.Duration) time.Duration {
+	left := budget - time.Duration(s.UsedSeconds*float64(time.Second))
+	if left < 0 {
+		return 0
+	}
+	return left.Truncate(time.Second)
+}
+
+// active süren bir mola varsa bitiş zamanını döner.
+func (s *pauseState) active(now time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Until, !s.Until.IsZero() && now.Before(s.Until)
+}
+
+// start istenen süreyi tek mola ve günlük bütçe sınırlarına göre kısaltarak molayı başlatır.
+func (s *pauseState) start(now time.Time, requested time.Duration, reason string, settings PauseSettings) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.Until.IsZero() && now.Before(s.Until) {
+		return s.Until, fmt.Errorf("zaten molada (%s'e kadar)", s.Until.Format("15:04"))
+	}
+	s.rolloverLocked(now)
+
+	d := requested
+	if limit := time.Duration(settings.MaxPauseMinutes) * time.Minute; d > limit {
+		d = limit
+	}
+	if left := s.budgetLeftLocked(time.Duration(settings.DailyBudgetMinutes) * time.Minute); d > left {
+		d = left
+	}
+	if d < time.Minute {
+		return time.Time{}, errors.New("bugünkü mola hakkı doldu")
+	}
+
+	s.UsedSeconds += d.Seconds()
+	s.Since = now
+	s.Until = now.Add(d)
+	s.Reason = reason
+	s.saveLocked()
+	return s.Until, nil
+}
+
+// stop molayı bitirir, kullanılmayan süreyi bütçeye iade eder ve mola süresini döner.
+func (s *pauseState) stop(now time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Until.IsZero() {
+		return 0, false
+	}
+	s.rolloverLocked(now)
+	if now.Before(s.Until) {
+		// Gün değiştiyse molanın düşüldüğü bütçe artık geçerli değil
+		if s.PeriodStart.Equal(quotaPeriodStart(s.Since, 0)) {
+			if s.UsedSeconds -= s.Until.Sub(now).Seconds(); s.UsedSeconds < 0 {
+				s.UsedSeconds = 0
+			}
+		}
+	} else {
+		now = s.Until
+	}
+	elapsed := now.Sub(s.Since)
+	s.Since, s.Until, s.Reason = time.Time{}, time.Time{}, ""
+	s.saveLocked()
+	return elapsed, true
+}
+
+func (s *pauseState) saveLocked() {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomic(s.path, data, 0600); err != nil {
+		log.Printf("⚠️ Mola durumu yazılamadı: %v", err)
+	}
+}
+
+// pauseRequest/pauseReply kontrol soketinde satır başına bir JSON mesajıdır.
+type pauseRequest struct {
+	Command string `json:"command"` // pause, resume, status
+	Minutes int    `json:"minutes,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+
+	source string // neden taşıyamayan istekte (sinyal) neden olarak kaydedilen kaynak
+}
+
+type pauseReply struct {
+	OK                bool      `json:"ok"`
+	Error             string    `json:"error,omitempty"`
+	Paused            bool      `json:"paused"`
+	Until             time.Time `json:"until"`
+	BudgetLeftSeconds int       `json:"budget_left_seconds"`
+}
+
+type pauseCall struct {
+	req   pauseRequest
+	reply chan pauseReply
+}
+
+// runPauseControl kontrol soketini ve sinyali dinler, süresi dolan molayı bitirir.
+func (c *Client) runPauseControl(ctx context.Context) {
+	settings := c.config.Pause
+
+	// Client molanın ortasında yeniden başlatıldıysa mola sürer
+	if until, ok := c.pause.active(c.now()); ok {
+		c.privacyPaused.Store(true)
+		log.Printf("⏸️ Gizlilik molası sürüyor (%s'e kadar)", until.Format("15:04:05"))
+	} else {
+		c.endPause("süre doldu")
+	}
+
+	calls := make(chan pauseCall)
+	if ln, err := listenControlSocket(settings.Socket, settings.SocketUser); err != nil {
+		log.Printf("⚠️ Mola kontrol soketi açılamadı: %v", err)
+	} else {
+		defer os.Remove(settings.Socket)
+		defer ln.Close()
+		go serveControlSocket(ctx, ln, calls)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	if len(pauseSignals) > 0 {
+		signal.Notify(sigs, pauseSignals...)
+		defer signal.Stop(sigs)
+	}
+
+	ticker := time.NewTicker(pauseCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case call := <-calls:
+			call.reply <- c.handlePauseRequest(call.req)
+		case <-sigs:
+			// Sinyal de soketle aynı neden ve süre kontrollerinden geçer
+			req := pauseRequest{Command: "pause", source: "sinyal (SIGUSR1)"}
+			if c.privacyPaused.Load() {
+				req.Command = "resume"
+			}
+			if reply := c.handlePauseRequest(req); !reply.OK {
+				log.Printf("⚠️ SIGUSR1 ile gizlilik molası isteği reddedildi: %s", reply.Error)
+			}
+		case <-ticker.C:
+			if _, ok := c.pause.active(c.now()); !ok && c.privacyPaused.Load() {
+				c.endPause("süre doldu")
+			}
+		}
+	}
+}
+
+func (c *Client) handlePauseRequest(req pauseRequest) pauseReply {
+	var err error
+	switch req.Command {
+	case "pause":
+		minutes := req.Minutes
+		if minutes <= 0 {
+			minutes = c.config.Pause.DefaultMinutes
+		}
+		// Sinyal neden taşıyamaz; neden olarak kaynağı raporlanır
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" {
+			reason = req.source
+		}
+		if reason == "" && c.config.Pause.RequireReason {
+			err = errors.New("mola nedeni gerekli")
+			break
+		}
+		err = c.beginPause(time.Duration(minutes)*time.Minute, reason)
+	case "resume":
+		if !c.endPause("kullanıcı bitirdi") {
+			err = errors.New("mola yok")
+		}
+	case "status":
+	default:
+		err = fmt.Errorf("bilinmeyen komut: %q", req.Command)
+	}
+
+	now := c.now()
+	reply := pauseReply{
+		OK:                err == nil,
+		BudgetLeftSeconds: int(c.pause.budgetLeft(now, time.Duration(c.config.Pause.DailyBudgetMinutes)*time.Minute).Seconds()),
+	}
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	reply.Until, reply.Paused = c.pause.active(now)
+	return reply
+}
+
+func (c *Client) beginPause(d time.Duration, reason string) error {
+	now := c.now()
+	until, err := c.pause.start(now, d, reason, c.config.Pause)
+	if err != nil {
+		return err
+	}
+	c.privacyPaused.Store(true)
+
+	granted := until.Sub(now)
+	log.Printf("⏸️ Gizlilik molası: ekran kaydı %s'e kadar duraklatıldı (%s)", until.Format("15:04:05"), reason)
+	c.emitEvent(BlockerEvent{
+		Type:            eventCapturePaused,
+		Reason:          reason,
+		DurationSeconds: int(granted.Seconds()),
+		Message:         fmt.Sprintf("%s'e kadar", until.Format(time.RFC3339)),
+	})
+	c.notify(Notification{
+		Key:   "privacy_pause",
+		Title: "Gizlilik molası",
+		Body:  fmt.Sprintf("⏸️ Ekran kaydı %s'e kadar duraklatıldı", until.Format("15:04")),
+	})
+	return nil
+}
+
+// endPause molayı bitirir ve kaydı devam ettirir; mola yoksa false döner.
+func (c *Client) endPause(cause string) bool {
+	elapsed, ok := c.pause.stop(c.now())
+	c.privacyPaused.Store(false)
+	if !ok {
+		return false
+	}
+
+	c.encoder.RequestKeyframe()
+	log.Printf("▶️ Gizlilik molası bitti (%s, %s), ekran kaydı devam ediyor", cause, elapsed.Truncate(time.Second))
+	c.emitEvent(BlockerEvent{
+		Type:            eventCaptureResumed,
+		Reason:          cause,
+		DurationSeconds: int(elapsed.Seconds()),
+	})
+	c.notify(Notification{
+		Key:   "privacy_pause",
+		Title: "Gizlilik molası",
+		Body:  "▶️ Mola bitti, ekran kaydı devam ediyor",
+	})
+	return true
+}
+
+// listenControlSocket kontrol soketini açar. Dosya kalmışsa ve dinleyen yoksa
+// (client çökmüş) silinir; başka bir client dinliyorsa hata döner. Soket
+// sadece sahibine açıktır; owner verilirse sahip o kullanıcı yapılır.
+func listenControlSocket(path, owner string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s başka bir client tarafından kullanılıyor", path)
+	}
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	// Client root olarak çalışabilir; mola isteyen masaüstü kullanıcısıdır
+	if owner != "" {
+		if err := chownToUser(path, owner); err != nil {
+			log.Printf("⚠️ Kontrol soketi %s kullanıcısına verilemedi, sadece client'ın kullanıcısı erişebilir: %v", owner, err)
+		}
+	}
+	return ln, nil
+}
+
+func chownToUser(path, name string) error {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return err
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return fmt.Errorf("%s kullanıcısının uid'i sayısal değil", name)
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		gid = -1
+	}
+	return os.Chown(path, uid, gid)
+}
+
+func serveControlSocket(ctx context.Context, ln net.Listener, calls chan<- pauseCall) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(pauseRequestWait))
+
+			var reply pauseReply
+			line, err := bufio.NewReader(conn).ReadBytes('\n')
+			var req pauseRequest
+			if err == nil {
+				err = json.Unmarshal(line, &req)
+			}
+			if err != nil {
+				reply.Error = fmt.Sprintf("geçersiz istek: %v", err)
+			} else {
+				call := pauseCall{req: req, reply: make(chan pauseReply, 1)}
+				select {
+				case calls <- call:
+					reply = <-call.reply
+				case <-ctx.Done():
+					return
+				}
+			}
+			json.NewEncoder(conn).Encode(reply)
+		}()
+	}
+}
+
+// sendPauseRequest çalışan client'a kontrol soketi üzerinden istek gönderir.
+func sendPauseRequest(path string, req pauseRequest) (pauseReply, error) {
+	var reply pauseReply
+	conn, err := net.DialTimeout("unix", path, pauseRequestWait)
+	if err != nil {
+		return reply, fmt.Errorf("client'a bağlanılamadı (çalışıyor mu?): %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(pauseRequestWait))
+
+	data, _ := json.Marshal(req)
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return reply, err
+	}
+	if err := json.NewDecoder(conn).Decode(&reply); err != nil {
+		return reply, err
+	}
+	return reply, nil
+}
+
+// runPause gizlilik molası başlatır: client pause [-minutes N] -reason "..."
+func runPause(args []string) int {
+	settings := loadClientConfig().Pause
+	fs := flag.NewFlagSet("pause", flag.ContinueOnError)
+	minutes := fs.Int("minutes", settings.DefaultMinutes, "mola süresi (dakika)")
+	reason := fs.String("reason", "", "mola nedeni (sunucuya raporlanır)")
+	show := fs.Bool("status", false, "sadece mola durumunu ve kalan hakkı göster")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	req := pauseRequest{Command: "pause", Minutes: *minutes, Reason: *reason}
+	if *show {
+		req = pauseRequest{Command: "status"}
+	}
+	return printPauseReply(sendPauseRequest(settings.Socket, req))
+}
+
+// runResume süren gizlilik molasını erken bitirir.
+func runResume(args []string) int {
+	settings := loadClientConfig().Pause
+	fs := flag.NewFlagSet("resume", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	return printPauseReply(sendPauseRequest(settings.Socket, pauseRequest{Command: "resume"}))
+}
+
+func printPauseReply(reply pauseReply, err error) int {
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 1
+	}
+	if reply.Error != "" {
+		fmt.Printf("❌ %s\n", reply.Error)
+	}
+	if reply.Paused {
+		fmt.Printf("⏸️ Ekran kaydı %s'e kadar duraklatıldı\n", reply.Until.Local().Format("15:04:05"))
+	} else {
+		fmt.Println("🔴 Ekran kaydı aktif (mola yok)")
+	}
+	fmt.Printf("Bugün kalan mola hakkı: %s\n", time.Duration(reply.BudgetLeftSeconds)*time.Second)
+	if !reply.OK {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testPauseSettings = PauseSettings{DailyBudgetMinutes: 30, MaxPauseMinutes: 15, DefaultMinutes: 10}
+
+// pauseStep bir mola işlemi ve sonrasında beklenen durum.
+type pauseStep struct {
+	at      string        // "2006-01-02 15:04[:05]"
+	action  string        // start, stop, budget (sadece kalan hakkı sorgular)
+	minutes int           // start için istenen süre
+	granted time.Duration // start: verilen süre; stop: molanın süresi
+	err     string        // beklenen hata (içerir)
+	left    time.Duration // işlemden sonra kalan günlük hak
+}
+
+func TestPauseStateBudget(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []pauseStep
+	}{
+		{
+			name: "tek mola sınırı",
+			steps: []pauseStep{
+				{at: "2024-03-10 10:00", action: "start", minutes: 60, granted: 15 * time.Minute, left: 15 * time.Minute},
+			},
+		},
+		{
+			name: "günlük bütçe sınırı",
+			steps: []pauseStep{
+				{at: "2024-03-10 10:00", action: "start", minutes: 12, granted: 12 * time.Minute, left: 18 * time.Minute},
+				{at: "2024-03-10 10:12", action: "stop", granted: 12 * time.Minute, left: 18 * time.Minute},
+				{at: "2024-03-10 11:00", action: "start", minutes: 15, granted: 15 * time.Minute, left: 3 * time.Minute},
+				{at: "2024-03-10 11:15", action: "stop", granted: 15 * time.Minute, left: 3 * time.Minute},
+				{at: "2024-03-10 12:00", action: "start", minutes: 10, granted: 3 * time.Minute, left: 0},
+				{at: "2024-03-10 12:03", action: "stop", granted: 3 * time.Minute, left: 0},
+				{at: "2024-03-10 13:00", action: "start", minutes: 10, err: "mola hakkı doldu", left: 0},
+			},
+		},
+		{
+			name: "bir dakikadan az hak kalınca mola verilmez",
+			steps: []pauseStep{
+				{at: "2024-03-10 10:00", action: "start", minutes: 15, granted: 15 * time.Minute, left: 15 * time.Minute},
+				{at: "2024-03-10 10:15", action: "stop", granted: 15 * time.Minute, left: 15 * time.Minute},
+				{at: "2024-03-10 11:00", action: "start", minutes: 15, granted: 15 * time.Minute, left: 0},
+				{at: "2024-03-10 11:14:30", action: "stop", granted: 14*time.Minute + 30*time.Second, left: 30 * time.Second},
+				{at: "2024-03-10 12:00", action: "start", minutes: 5, err: "mola hakkı doldu", left: 30 * time.Second},
+			},
+		},
+		{
+			name: "erken bitirilen mola iade edilir",
+			steps: []pauseStep{
+				{at: "2024-03-10 10:00", action: "start", minutes: 10, granted: 10 * time.Minute, left: 20 * time.Minute},
+				{at: "2024-03-10 10:04", action: "stop", granted: 4 * time.Minute, left: 26 * time.Minute},
+			},
+		},
+		{
+			name: "molada iken tekrar başlatılamaz",
+			steps: []pauseStep{
+				{at: "2024-03-10 10:00", action: "start", minutes: 10, granted: 10 * time.Minute, left: 20 * time.Minute},
+				{at: "2024-03-10 10:05", action: "start", minutes: 10, err: "zaten molada", left: 20 * time.Minute},
+				{at: "2024-03-10 10:20", action: "start", minutes: 5, granted: 5 * time.Minute, left: 15 * time.Minute},
+			},
+		},
+		{
+			name: "süresi dolmuş mola iade edilmez",
+			steps: []pauseStep{
+				{at: "2024-03-10 10:00", action: "start", minutes: 10, granted: 10 * time.Minute, left: 20 * time.Minute},
+				{at: "2024-03-10 10:30", action: "stop", granted: 10 * time.Minute, left: 20 * time.Minute},
+				{at: "2024-03-10 10:31", action: "stop", err: "mola yok", left: 20 * time.Minute},
+			},
+		},
+		{
+			name: "bütçe gece yarısı yenilenir",
+			steps: []pauseStep{
+				{at: "2024-03-10 22:00", action: "start", minutes: 15, granted: 15 * time.Minute, left: 15 * time.Minute},
+				{at: "2024-03-10 22:15", action: "stop", granted: 15 * time.Minute, left: 15 * time.Minute},
+				{at: "2024-03-10 23:00", action: "start", minutes: 15, granted: 15 * time.Minute, left: 0},
+				{at: "2024-03-10 23:15", action: "stop", granted: 15 * time.Minute, left: 0},
+				{at: "2024-03-11 00:00", action: "start", minutes: 10, granted: 10 * time.Minute, left: 20 * time.Minute},
+			},
+		},
+		{
+			// Mola dünün bütçesinden düşüldü; iadesi yeni günün bütçesini artırmaz
+			name: "gece yarısını geçen mola",
+			steps: []pauseStep{
+				{at: "2024-03-10 23:55", action: "start", minutes: 15, granted: 15 * time.Minute, left: 15 * time.Minute},
+				{at: "2024-03-11 00:02", action: "stop", granted: 7 * time.Minute, left: 30 * time.Minute},
+				{at: "2024-03-11 09:00", action: "start", minutes: 15, granted: 15 * time.Minute, left: 15 * time.Minute},
+				{at: "2024-03-11 09:15", action: "stop", granted: 15 * time.Minute, left: 15 * time.Minute},
+			},
+		},
+		{
+			// Gece yarısından sonra bütçe sorgulanmış olsa da (status) iade yapılmaz
+			name: "gece yarısını geçen mola, arada sorgu",
+			steps: []pauseStep{
+				{at: "2024-03-10 10:00", action: "start", minutes: 15, granted: 15 * time.Minute, left: 15 * time.Minute},
+				{at: "2024-03-10 10:15", action: "stop", granted: 15 * time.Minute, left: 15 * time.Minute},
+				{at: "2024-03-10 23:55", action: "start", minutes: 15, granted: 15 * time.Minute, left: 0},
+				{at: "2024-03-11 00:01", action: "budget", left: 30 * time.Minute},
+				{at: "2024-03-11 00:05", action: "start", minutes: 5, err: "zaten molada", left: 30 * time.Minute},
+				{at: "2024-03-11 00:06", action: "stop", granted: 11 * time.Minute, left: 30 * time.Minute},
+			},
+		},
+	}
+
+	budget := time.Duration(testPauseSettings.DailyBudgetMinutes) * time.Minute
+	for _, tt := range tests {
+		s := loadPauseState(filepath.Join(t.TempDir(), "pause.json"))
+		for i, step := range tt.steps {
+			now, err := time.ParseInLocation("2006-01-02 15:04:05", step.at, time.UTC)
+			if err != nil {
+				now = scheduleTime(t, time.UTC, step.at)
+			}
+			var (
+				granted time.Duration
+				msg     string
+			)
+			switch step.action {
+			case "start":
+				until, e := s.start(now, time.Duration(step.minutes)*time.Minute, "test", testPauseSettings)
+				if e != nil {
+					msg = e.Error()
+				} else {
+					granted = until.Sub(now)
+				}
+			case "stop":
+				elapsed, ok := s.stop(now)
+				if !ok {
+					msg = "mola yok"
+				}
+				granted = elapsed
+			}
+
+			if step.err != "" && !strings.Contains(msg, step.err) || step.err == "" && msg != "" {
+				t.Errorf("%s [%d] %s %s: hata %q, beklenen %q", tt.name, i, step.at, step.action, msg, step.err)
+			}
+			if step.err == "" && granted != step.granted {
+				t.Errorf("%s [%d] %s %s: süre %s, beklenen %s", tt.name, i, step.at, step.action, granted, step.granted)
+			}
+			if left := s.budgetLeft(now, budget); left != step.left {
+				t.Errorf("%s [%d] %s %s: kalan hak %s, beklenen %s", tt.name, i, step.at, step.action, left, step.left)
+			}
+		}
+	}
+}
+
+func TestPauseStatePersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pause.json")
+	s := loadPauseState(path)
+	now := scheduleTime(t, time.UTC, "2024-03-10 10:00")
+	if _, err := s.start(now, 10*time.Minute, "doktor", testPauseSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Yeniden başlatmada süren mola ve kullanılan hak korunur
+	reloaded := loadPauseState(path)
+	until, ok := reloaded.active(now.Add(5 * time.Minute))
+	if !ok || !until.Equal(now.Add(10*time.Minute)) || reloaded.Reason != "doktor" {
+		t.Fatalf("okunan mola: %v, %v, %q", until, ok, reloaded.Reason)
+	}
+	if left := reloaded.budgetLeft(now, 30*time.Minute); left != 20*time.Minute {
+		t.Fatalf("okunan kalan hak %s", left)
+	}
+	if _, ok := reloaded.active(now.Add(10 * time.Minute)); ok {
+		t.Fatal("süresi dolan mola aktif görünmemeli")
+	}
+}
+
+func testPauseClient(t *testing.T, requireReason bool) (*Client, *time.Time) {
+	t.Helper()
+	clock := scheduleTime(t, time.UTC, "2024-03-10 10:00")
+	c := &Client{
+		config:  defaultClientConfig(),
+		encoder: newFrameEncoder(defaultClientConfig().Encoding),
+		pause:   loadPauseState(filepath.Join(t.TempDir(), "pause.json")),
+		now:     func() time.Time { return clock },
+	}
+	c.config.Pause = testPauseSettings
+	c.config.Pause.RequireReason = requireReason
+	return c, &clock
+}
+
+func TestHandlePauseRequestReason(t *testing.T) {
+	signal := pauseRequest{Command: "pause", source: "sinyal (SIGUSR1)"}
+	tests := []struct {
+		name          string
+		requireReason bool
+		req           pauseRequest
+		ok            bool
+		reason        string
+	}{
+		{"nedenli istek", true, pauseRequest{Command: "pause", Minutes: 5, Reason: " doktor "}, true, "doktor"},
+		{"nedensiz istek", true, pauseRequest{Command: "pause", Reason: "  "}, false, ""},
+		{"neden zorunlu değil", false, pauseRequest{Command: "pause"}, true, ""},
+		{"sinyal kaynağı neden olur", true, signal, true, "sinyal (SIGUSR1)"},
+		{"sinyal, neden zorunlu değil", false, signal, true, "sinyal (SIGUSR1)"},
+	}
+
+	for _, tt := range tests {
+		c, _ := testPauseClient(t, tt.requireReason)
+		reply := c.handlePauseRequest(tt.req)
+		if reply.OK != tt.ok || reply.Paused != tt.ok || c.privacyPaused.Load() != tt.ok {
+			t.Errorf("%s: cevap %+v, başarı beklenen %v", tt.name, reply, tt.ok)
+			continue
+		}
+		if !tt.ok {
+			if !strings.Contains(reply.Error, "neden") {
+				t.Errorf("%s: hata %q", tt.name, reply.Error)
+			}
+			continue
+		}
+		if c.pause.Reason != tt.reason {
+			t.Errorf("%s: kaydedilen neden %q, beklenen %q", tt.name, c.pause.Reason, tt.reason)
+		}
+	}
+}
+
+func TestHandlePauseRequestSignalToggle(t *testing.T) {
+	c, clock := testPauseClient(t, true)
+
+	// Süre verilmeyen istek varsayılan süreyi alır
+	reply := c.handlePauseRequest(pauseRequest{Command: "pause", source: "sinyal (SIGUSR1)"})
+	if !reply.OK || !reply.Until.Equal(clock.Add(10*time.Minute)) || reply.BudgetLeftSeconds != 20*60 {
+		t.Fatalf("mola cevabı %+v", reply)
+	}
+
+	*clock = clock.Add(4 * time.Minute)
+	reply = c.handlePauseRequest(pauseRequest{Command: "resume", source: "sinyal (SIGUSR1)"})
+	if !reply.OK || reply.Paused || c.privacyPaused.Load() || reply.BudgetLeftSeconds != 26*60 {
+		t.Fatalf("bitirme cevabı %+v", reply)
+	}
+
+	if reply := c.handlePauseRequest(pauseRequest{Command: "resume"}); reply.OK || reply.Error != "mola yok" {
+		t.Fatalf("mola yokken bitirme cevabı %+v", reply)
+	}
+	if reply := c.handlePauseRequest(pauseRequest{Command: "sleep"}); reply.OK {
+		t.Fatalf("bilinmeyen komut kabul edildi: %+v", reply)
+	}
+}
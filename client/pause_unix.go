@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+var pauseSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,6 @@
+package main
+
+import "os"
+
+// Windows'ta SIGUSR1 yok; mola sadece "pause" alt komutuyla kontrol edilir.
+var pauseSignals []os.Signal
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Politika senkronizasyonu: engelleme config'leri sunucudan versiyonlu
+// olarak çekilir (ETag ile), doğrulanır, çalışan engelleyicilere atomik
+// olarak uygulanır ve çevrimdışı başlangıç için diske önbelleklenir.
+// Sunucuda politika tanımlıysa yerel blocked_*.json dosyalarının önüne geçer.
+
+const (
+	policyKindApps     = "apps"
+	policyKindWebsites = "websites"
+)
+
+type policyDocument struct {
+	Version int64           `json:"version"`
+	Config  json.RawMessage `json:"config"`
+}
+
+type cachedPolicy struct {
+	ETag      string          `json:"etag"`
+	Version   int64           `json:"version"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Config    json.RawMessage `json:"config"`
+}
+
+type policyState struct {
+	mu       sync.Mutex
+	policies map[string]*cachedPolicy // kind -> uygulanmış politika
+
+	// applyMu yönetilen politika kontrolü ile engelleyici config'inin
+	// değiştirilmesini atomik yapar; böylece config watcher'ın yerel dosya
+	// yüklemesi sunucu politikasının uygulanmasıyla yarışıp onu ezemez.
+	applyMu sync.Mutex
+}
+
+// managed sunucudan gelen bir politikanın aktif olup olmadığını döner.
+func (ps *policyState) managed(kind string) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.policies[kind] != nil
+}
+
+func (ps *policyState) get(kind string) *cachedPolicy {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.policies[kind]
+}
+
+func (ps *policyState) set(kind string, policy *cachedPolicy) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if policy == nil {
+		delete(ps.policies, kind)
+		return
+	}
+	ps.policies[kind] = policy
+}
+
+func (c *Client) policyCachePath(kind string) string {
+	return filepath.Join(c.config.Policy.CacheDir, kind+".json")
+}
+
+// loadCachedPolicies son geçerli politikaları diskten yükler, böylece sunucuya
+// ulaşılamasa da client yönetilen politikayla başlar.
+func (c *Client) loadCachedPolicies() {
+	if !c.config.Policy.Enabled {
+		return
+	}
+
+	for _, kind := range []string{policyKindApps, policyKindWebsites} {
+		data, err := os.ReadFile(c.policyCachePath(kind))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("⚠️ Politika önbelleği okunamadı (%s): %v", kind, err)
+			}
+			continue
+		}
+
+		var cached cachedPolicy
+		if err := json.Unmarshal(data, &cached); err != nil {
+			log.Printf("⚠️ Politika önbelleği bozuk (%s): %v", kind, err)
+			continue
+		}
+		if err := c.installPolicy(kind, &cached); err != nil {
+			log.Printf("⚠️ Önbellekteki politika geçersiz (%s): %v", kind, err)
+			continue
+		}
+		log.Printf("📦 Önbellekteki %s politikası yüklendi (versiyon %d)", kind, cached.Version)
+	}
+}
+
+// StartPolicySync politikaları periyodik olarak sunucudan çeker.
+func (c *Client) StartPolicySync(ctx context.Context) {
+	if !c.config.Policy.Enabled {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(c.config.Policy.SyncIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.syncPolicies()
+		}
+	}
+}
+
+func (c *Client) syncPolicies() {
+	if !c.config.Policy.Enabled {
+		return
+	}
+
+	for _, kind := range []string{policyKindApps, policyKindWebsites} {
+		if err := c.fetchPolicy(kind); err != nil {
+			log.Printf("⚠️ %s politikası senkronize edilemedi: %v", kind, err)
+		}
+	}
+}
+
+func (c *Client) fetchPolicy(kind string) error {
+	policyURL := fmt.Sprintf("%s/api/policy/%s?clientId=%s", c.serverURL, kind, url.QueryEscape(c.clientID))
+	req, err := http.NewRequest(http.MethodGet, policyURL, nil)
+	if err != nil {
+		return err
+	}
+
+	current := c.policies.get(kind)
+	if current != nil && current.ETag != "" {
+		req.Header.Set("If-None-Match", current.ETag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil
+	case http.StatusNotFound:
+		// Politika hiç tanımlanmamış (veya eski sunucu). Geçici bir 404 yönetilen
+		// politikayı düşürmemeli: kaldırma yalnızca 410 ile bildirilir.
+		if current != nil {
+			return fmt.Errorf("sunucu politikayı döndürmedi (HTTP 404), son politika (v%d) korunuyor", current.Version)
+		}
+		return nil
+	case http.StatusGone:
+		// Politika sunucudan açıkça kaldırıldı, yerel config'e dön
+		if current != nil {
+			c.dropPolicy(kind)
+		}
+		return nil
+	case http.StatusOK:
+	default:
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+
+	var doc policyDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("politika parse edilemedi: %v", err)
+	}
+	if len(doc.Config) == 0 {
+		return errors.New("politika boş")
+	}
+	if current != nil && doc.Version < current.Version {
+		return fmt.Errorf("sunucudaki politika (v%d) uygulanandan (v%d) eski, yok sayıldı", doc.Version, current.Version)
+	}
+
+	policy := &cachedPolicy{
+		ETag:      resp.Header.Get("ETag"),
+		Version:   doc.Version,
+		FetchedAt: time.Now(),
+		Config:    doc.Config,
+	}
+	if err := c.installPolicy(kind, policy); err != nil {
+		return fmt.Errorf("politika v%d reddedildi: %v", doc.Version, err)
+	}
+	log.Printf("✅ %s politikası uygulandı (versiyon %d)", kind, doc.Version)
+
+	if err := c.cachePolicy(kind, policy); err != nil {
+		log.Printf("⚠️ Politika önbelleğe yazılamadı: %v", err)
+	}
+	return nil
+}
+
+// installPolicy politikayı yönetilen olarak işaretler ve engelleyiciye
+// uygular. İşaretleme uygulamadan önce ve aynı kilit altında yapılır, böylece
+// araya giren bir yerel config yüklemesi politikayı ezemez. Politika
+// reddedilirse önceki durum geri yüklenir.
+func (c *Client) installPolicy(kind string, policy *cachedPolicy) error {
+	c.policies.applyMu.Lock()
+	defer c.policies.applyMu.Unlock()
+
+	prev := c.policies.get(kind)
+	c.policies.set(kind, policy)
+	if err := c.applyPolicy(kind, policy.Config); err != nil {
+		c.policies.set(kind, prev)
+		return err
+	}
+	return nil
+}
+
+// dropPolicy sunucudan kaldırılan politikayı bırakır ve yerel dosyaya döner.
+// Yerel dosya yoksa veya geçersizse engelleyici config'siz kalır ve durur;
+// kaldırılan politika uygulanmaya devam etmez.
+func (c *Client) dropPolicy(kind string) {
+	c.policies.applyMu.Lock()
+	defer c.policies.applyMu.Unlock()
+	c.policies.set(kind, nil)
+
+	if err := os.Remove(c.policyCachePath(kind)); err != nil && !os.IsNotExist(err) {
+		log.Printf("⚠️ Politika önbelleği silinemedi: %v", err)
+	}
+
+	switch kind {
+	case policyKindApps:
+		cfg, err := readAppBlockerConfigFile()
+		if err != nil {
+			log.Printf("ℹ️ Sunucudaki %s politikası kaldırıldı, yerel config kullanılamıyor (%v), uygulama engelleme kapatıldı", kind, err)
+			cfg = nil
+		} else {
+			log.Printf("ℹ️ Sunucudaki %s politikası kaldırıldı, yerel config kullanılıyor", kind)
+		}
+		c.setAppBlockerConfig(cfg)
+	case policyKindWebsites:
+		cfg, err := readWebsiteBlockerConfigFile()
+		if err != nil {
+			log.Printf("ℹ️ Sunucudaki %s politikası kaldırıldı, yerel config kullanılamıyor (%v), website engelleme kapatıldı", kind, err)
+			cfg = nil
+		} else {
+			log.Printf("ℹ️ Sunucudaki %s politikası kaldırıldı, yerel config kullanılıyor", kind)
+		}
+		c.setWebsiteBlockerConfig(cfg)
+	}
+}
+
+// applyPolicy politikayı çözer, doğrular ve engelleyiciye atomik olarak uygular.
+func (c *Client) applyPolicy(kind string, raw json.RawMessage) error {
+	switch kind {
+	case policyKindApps:
+		cfg, errs := parseAppBlockerConfig(raw)
+		logValidation(kind+" politikası", errs)
+		if err := errs.Err(); err != nil {
+			return err
+		}
+		c.setAppBlockerConfig(cfg)
+	case policyKindWebsites:
+		cfg, errs := parseWebsiteBlockerConfig(raw)
+		logValidation(kind+" politikası", errs)
+		if err := errs.Err(); err != nil {
+			return err
+		}
+		c.setWebsiteBlockerConfig(cfg)
+	default:
+		return fmt.Errorf("bilinmeyen politika türü: %s", kind)
+	}
+	return nil
+}
+
+func (c *Client) cachePolicy(kind string, policy *cachedPolicy) error {
+	if err := os.MkdirAll(c.config.Policy.CacheDir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.policyCachePath(kind), data, 0600)
+}
+
+// writeFileAtomic dosyayı aynı dizindeki geçici bir dosyaya yazıp rename eder,
+// böylece okuyucular asla yarım yazılmış içerik görmez.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testAppPolicy = `{
+		"blocked_applications": [{"name": "Steam", "processes": ["steam"]}],
+		"settings": {"check_interval_seconds": 5, "max_warnings": 3, "app_blocker_enabled": true}
+	}`
+	testWebsitePolicy = `{
+		"blocked_websites": [{"name": "YouTube", "urls": ["youtube.com"]}],
+		"settings": {"check_interval_seconds": 5, "blocking_method": "hosts", "redirect_to": "0.0.0.0", "website_blocker_enabled": true}
+	}`
+)
+
+// chdirTemp testi yerel config dosyalarının okunduğu boş bir dizinde çalıştırır.
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+// Sunucu politikayı 410 ile kaldırınca engelleyiciler yerel config'e döner;
+// yerel dosya yoksa veya geçersizse kaldırılan politika uygulanmaya devam etmez.
+func TestPolicyRevoked(t *testing.T) {
+	tests := []struct {
+		name  string
+		local string // boşsa yerel dosya yok
+		want  string // beklenen engelleme listesindeki ad; boşsa config temizlenir
+	}{
+		{name: "yerel dosya yok"},
+		{name: "yerel dosya geçersiz", local: "{bozuk"},
+		{name: "yerel dosya geçerli", local: "yerel", want: "Yerel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t)
+			if tt.local != "" {
+				apps, websites := tt.local, tt.local
+				if tt.local == "yerel" {
+					apps = `{"blocked_applications": [{"name": "Yerel", "processes": ["yerel"]}],
+						"settings": {"check_interval_seconds": 5, "max_warnings": 3, "app_blocker_enabled": true}}`
+					websites = `{"blocked_websites": [{"name": "Yerel", "urls": ["yerel.test"]}],
+						"settings": {"check_interval_seconds": 5, "blocking_method": "hosts", "redirect_to": "0.0.0.0", "website_blocker_enabled": true}}`
+				}
+				if err := os.WriteFile(appBlockerConfigFile, []byte(apps), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(websiteBlockerConfigFile, []byte(websites), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			revoked := false
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if revoked {
+					w.WriteHeader(http.StatusGone)
+					return
+				}
+				w.Header().Set("ETag", `"v1"`)
+				switch r.URL.Path {
+				case "/api/policy/apps":
+					w.Write([]byte(`{"version": 1, "config": ` + testAppPolicy + `}`))
+				case "/api/policy/websites":
+					w.Write([]byte(`{"version": 1, "config": ` + testWebsitePolicy + `}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			c := &Client{
+				serverURL:  srv.URL,
+				clientID:   "test",
+				httpClient: srv.Client(),
+				config:     defaultClientConfig(),
+				policies:   &policyState{policies: make(map[string]*cachedPolicy)},
+			}
+			c.config.Policy.CacheDir = filepath.Join(dir, "policy_cache")
+
+			c.syncPolicies()
+			if cfg := c.appBlockerConfig(); cfg == nil || cfg.BlockedApplications[0].Name != "Steam" {
+				t.Fatalf("uygulama politikası uygulanmadı: %+v", cfg)
+			}
+			if cfg := c.websiteBlockerConfig(); cfg == nil || cfg.BlockedWebsites[0].Name != "YouTube" {
+				t.Fatalf("website politikası uygulanmadı: %+v", cfg)
+			}
+
+			revoked = true
+			c.syncPolicies()
+
+			for _, kind := range []string{policyKindApps, policyKindWebsites} {
+				if c.policies.managed(kind) {
+					t.Errorf("%s: politika hâlâ yönetiliyor", kind)
+				}
+				if _, err := os.Stat(c.policyCachePath(kind)); !os.IsNotExist(err) {
+					t.Errorf("%s: politika önbelleği silinmedi: %v", kind, err)
+				}
+			}
+
+			appCfg, webCfg := c.appBlockerConfig(), c.websiteBlockerConfig()
+			if tt.want == "" {
+				if appCfg != nil || webCfg != nil {
+					t.Fatalf("kaldırılan politika uygulanmaya devam ediyor: %+v, %+v", appCfg, webCfg)
+				}
+				return
+			}
+			if appCfg == nil || appCfg.BlockedApplications[0].Name != tt.want {
+				t.Errorf("uygulama config'i %+v, beklenen yerel config", appCfg)
+			}
+			if webCfg == nil || webCfg.BlockedWebsites[0].Name != tt.want {
+				t.Errorf("website config'i %+v, beklenen yerel config", webCfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Process çalışan bir process'in platformdan bağımsız kaydı. Platformun
+// sağlayamadığı alanlar boş kalır (örn. Windows'ta PPID ve Cmdline).
+type Process struct {
+	PID       int
+	PPID      int
+	Name      string
+	Exe       string
+	Cmdline   []string
+	User      string
+	StartTime time.Time
+}
+
+func (p Process) String() string {
+	return fmt.Sprintf("%s (pid %d)", p.Name, p.PID)
+}
+
+// MatchRule bir BlockedApp için process eşleştirme kuralı. Sadece bir alan dolu
+// olmalıdır:
+//
+//	name:  process adı birebir (büyük/küçük harf duyarsız)
+//	exe:   çalıştırılabilir dosyanın tam yolu birebir
+//	glob:  process adı veya exe yolu glob deseniyle, büyük/küçük harf duyarsız
+//	       (örn. "steam*", "/opt/*/bin/game")
+//	regex: process adı, exe yolu veya tam komut satırı düzenli ifadeyle; harf
+//	       duyarsızlığı için "(?i)" kullanılmalı
+type MatchRule struct {
+	Name  string `json:"name,omitempty"`
+	Exe   string `json:"exe,omitempty"`
+	Glob  string `json:"glob,omitempty"`
+	Regex string `json:"regex,omitempty"`
+}
+
+type compiledRule struct {
+	rule  MatchRule
+	glob  string // küçük harfe çevrilmiş glob
+	regex *regexp.Regexp
+}
+
+// appMatcher bir BlockedApp'in tüm kurallarını derlenmiş halde tutar.
+type appMatcher struct {
+	rules []compiledRule
+}
+
+// legacyRule eski "processes" girdilerini kurala çevirir: yol içerenler exe
+// yolu, diğerleri process adı ile birebir eşleşir.
+func legacyRule(process string) MatchRule {
+	if strings.ContainsAny(process, `/\`) {
+		return MatchRule{Exe: process}
+	}
+	return MatchRule{Name: process}
+}
+
+func (r MatchRule) validate() error {
+	set := 0
+	for _, v := range []string{r.Name, r.Exe, r.Glob, r.Regex} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("name, exe, glob veya regex alanlarından tam olarak biri dolu olmalı")
+	}
+	if r.Glob != "" {
+		if _, err := filepath.Match(r.Glob, ""); err != nil {
+			return fmt.Errorf("geçersiz glob %q: %v", r.Glob, err)
+		}
+	}
+	if r.Regex != "" {
+		if _, err := regexp.Compile(r.Regex); err != nil {
+			return fmt.Errorf("geçersiz regex %q: %v", r.Regex, err)
+		}
+	}
+	return nil
+}
+
+func newAppMatcher(app BlockedApp) (*appMatcher, error) {
+	m := &appMatcher{}
+	rules := make([]MatchRule, 0, len(app.Processes)+len(app.Match))
+	for _, process := range app.Processes {
+		rules = append(rules, legacyRule(process))
+	}
+	rules = append(rules, app.Match...)
+
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+		compiled := compiledRule{rule: rule, glob: strings.ToLower(rule.Glob)}
+		if rule.Regex != "" {
+			compiled.regex = regexp.MustCompile(rule.Regex)
+		}
+		m.rules = append(m.rules, compiled)
+	}
+	return m, nil
+}
+
+func (m *appMatcher) matches(p Process) bool {
+	for _, r := range m.rules {
+		switch {
+		case r.rule.Name != "":
+			if strings.EqualFold(p.Name, r.rule.Name) {
+				return true
+			}
+		case r.rule.Exe != "":
+			if p.Exe != "" && p.Exe == r.rule.Exe {
+				return true
+			}
+		case r.rule.Glob != "":
+			// Name kuralı gibi harf duyarsız: "Discord*" discord'u da yakalar
+			if ok, _ := filepath.Match(r.glob, strings.ToLower(p.Name)); ok {
+				return true
+			}
+			if p.Exe != "" {
+				if ok, _ := filepath.Match(r.glob, strings.ToLower(p.Exe)); ok {
+					return true
+				}
+			}
+		case r.regex != nil:
+			if r.regex.MatchString(p.Name) || (p.Exe != "" && r.regex.MatchString(p.Exe)) ||
+				(len(p.Cmdline) > 0 && r.regex.MatchString(strings.Join(p.Cmdline, " "))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Find kurallara uyan process'leri döner. Client'ın kendisi asla eşleşmez.
+func (m *appMatcher) Find(processes []Process) []Process {
+	self := os.Getpid()
+	var found []Process
+	for _, p := range processes {
+		if p.PID != self && m.matches(p) {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// matcher config yüklenirken derlenmiş eşleştiriciyi, yoksa yenisini döner.
+func (app *BlockedApp) matcher() *appMatcher {
+	if app.compiled != nil {
+		return app.compiled
+	}
+	m, err := newAppMatcher(*app)
+	if err != nil {
+		return &appMatcher{}
+	}
+	return m
+}
+
+// compileMatchers doğrulanmış config'teki kuralları bir kez derler.
+func (cfg *AppBlockerConfig) compileMatchers() {
+	for i := range cfg.BlockedApplications {
+		app := &cfg.BlockedApplications[i]
+		if m, err := newAppMatcher(*app); err == nil {
+			app.compiled = m
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Linux'ta process listesi /proc'tan okunur: comm (ad), cmdline, exe (sembolik
+// link), status (PPid, Uid) ve stat (başlangıç zamanı).
+
+// Linux'ta USER_HZ pratikte her zaman 100'dür (sysconf cgo gerektirir)
+const clockTicksPerSecond = 100
+
+var (
+	userNamesMu sync.Mutex
+	userNames   = make(map[string]string) // uid -> kullanıcı adı
+)
+
+func listProcesses() ([]Process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	bootTime := readBootTime()
+
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		// Process okuma sırasında bitmiş olabilir
+		p, err := readProcess(pid, bootTime)
+		if err != nil {
+			continue
+		}
+		processes = append(processes, p)
+	}
+	return processes, nil
+}
+
+func readProcess(pid int, bootTime time.Time) (Process, error) {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	p := Process{PID: pid}
+
+	comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+	if err != nil {
+		return p, err
+	}
+	p.Name = strings.TrimSuffix(string(comm), "\n")
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		for _, arg := range bytes.Split(bytes.TrimRight(cmdline, "\x00"), []byte{0}) {
+			if len(arg) > 0 {
+				p.Cmdline = append(p.Cmdline, string(arg))
+			}
+		}
+	}
+
+	// Başka kullanıcıların process'lerinde yetki gerektirebilir
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		p.Exe = strings.TrimSuffix(exe, " (deleted)")
+	}
+
+	// comm 15 karakterle sınırlı; kesilmişse exe veya argv[0] adından tamamla
+	if len(p.Name) == 15 {
+		for _, candidate := range []string{p.Exe, firstArg(p.Cmdline)} {
+			base := filepath.Base(candidate)
+			if candidate != "" && len(base) > len(p.Name) && strings.HasPrefix(base, p.Name) {
+				p.Name = base
+				break
+			}
+		}
+	}
+
+	status, err := os.Open(filepath.Join(dir, "status"))
+	if err != nil {
+		return p, err
+	}
+	scanner := bufio.NewScanner(status)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "PPid":
+			p.PPID, _ = strconv.Atoi(fields[0])
+		case "Uid":
+			p.User = lookupUserName(fields[0])
+		}
+	}
+	status.Close()
+
+	if stat, err := os.ReadFile(filepath.Join(dir, "stat")); err == nil && !bootTime.IsZero() {
+		p.StartTime = parseStartTime(stat, bootTime)
+	}
+
+	return p, nil
+}
+
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// parseStartTime /proc/<pid>/stat'taki 22. alandan (boot'tan beri geçen
+// tick) başlangıç zamanını hesaplar. Ad alanı boşluk/parantez içerebileceği
+// için son ')' sonrasından ayrıştırılır.
+func parseStartTime(stat []byte, bootTime time.Time) time.Time {
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return time.Time{}
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	// fields[0] 3. alan (state), başlangıç zamanı 22. alan
+	if len(fields) < 20 {
+		return time.Time{}
+	}
+	ticks, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return bootTime.Add(time.Duration(ticks) * time.Second / clockTicksPerSecond)
+}
+
+func readBootTime() time.Time {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			secs, err := strconv.ParseInt(strings.TrimSpace(line[len("btime "):]), 10, 64)
+			if err == nil {
+				return time.Unix(secs, 0)
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func lookupUserName(uid string) string {
+	userNamesMu.Lock()
+	defer userNamesMu.Unlock()
+
+	if name, ok := userNames[uid]; ok {
+		return name
+	}
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	userNames[uid] = name
+	return name
+}
+
+// processAlive zombie process'leri (çıkmış ama ebeveyni beklememiş) kapanmış sayar.
+func processAlive(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 || end+2 >= len(stat) {
+		return true
+	}
+	return stat[end+2] != 'Z'
+}
+
+// sameProcess PID'in hâlâ aynı process'e ait olduğunu başlangıç zamanıyla
+// doğrular (PID'ler yeniden kullanılabilir).
+func sameProcess(p Process) bool {
+	if !processAlive(p.PID) {
+		return false
+	}
+	if p.StartTime.IsZero() {
+		return true
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(p.PID), "stat"))
+	if err != nil {
+		return false
+	}
+	return parseStartTime(stat, readBootTime()).Equal(p.StartTime)
+}
@@ -0,0 +1,72 @@
+//go:build !linux && !windows
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// macOS ve diğer Unix'lerde process listesi ps ile alınır. comm ve args
+// boşluk içerebildiği için ayrı ayrı sorgulanıp PID ile birleştirilir.
+func listProcesses() ([]Process, error) {
+	output, err := exec.Command("ps", "-axww", "-o", "pid=", "-o", "ppid=", "-o", "user=", "-o", "comm=").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []Process
+	index := make(map[int]int)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		comm := strings.Join(fields[3:], " ")
+
+		p := Process{PID: pid, PPID: ppid, User: fields[2], Name: filepath.Base(comm)}
+		if filepath.IsAbs(comm) {
+			p.Exe = comm
+		}
+		index[pid] = len(processes)
+		processes = append(processes, p)
+	}
+
+	// Komut satırları (alınamazsa sadece ad ve exe ile devam edilir)
+	if output, err := exec.Command("ps", "-axww", "-o", "pid=", "-o", "args=").Output(); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 2 {
+				continue
+			}
+			pid, err := strconv.Atoi(fields[0])
+			if i, ok := index[pid]; err == nil && ok {
+				processes[i].Cmdline = fields[1:]
+			}
+		}
+	}
+
+	return processes, nil
+}
+
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// ps başlangıç zamanını saniye hassasiyetinde verdiği için sadece varlık kontrol edilir
+func sameProcess(p Process) bool {
+	return processAlive(p.PID)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestAppMatcher(t *testing.T) {
+	discord := Process{PID: 100, Name: "discord", Exe: "/opt/Discord/Discord", Cmdline: []string{"/opt/Discord/Discord", "--type=renderer"}}
+	steam := Process{PID: 101, Name: "steam.exe", Exe: `C:\Program Files\Steam\steam.exe`}
+	java := Process{PID: 102, Name: "java", Exe: "/usr/bin/java", Cmdline: []string{"java", "-jar", "minecraft.jar"}}
+	noExe := Process{PID: 103, Name: "Game"}
+
+	tests := []struct {
+		name    string
+		app     BlockedApp
+		process Process
+		want    bool
+	}{
+		{"eski ad kuralı harf duyarsız", BlockedApp{Processes: []string{"Discord"}}, discord, true},
+		{"eski ad kuralı birebir", BlockedApp{Processes: []string{"disc"}}, discord, false},
+		{"eski yol kuralı exe olur", BlockedApp{Processes: []string{"/opt/Discord/Discord"}}, discord, true},
+		{"exe birebir ve harf duyarlı", BlockedApp{Match: []MatchRule{{Exe: "/opt/discord/discord"}}}, discord, false},
+		{"exe'si olmayan process", BlockedApp{Match: []MatchRule{{Exe: "/usr/bin/game"}}}, noExe, false},
+		{"glob ad", BlockedApp{Match: []MatchRule{{Glob: "disc*"}}}, discord, true},
+		{"glob ad harf duyarsız", BlockedApp{Match: []MatchRule{{Glob: "Discord*"}}}, discord, true},
+		{"glob ad büyük harfli process", BlockedApp{Match: []MatchRule{{Glob: "game"}}}, noExe, true},
+		{"glob exe yolu", BlockedApp{Match: []MatchRule{{Glob: "/opt/*/discord"}}}, discord, true},
+		{"glob eşleşmez", BlockedApp{Match: []MatchRule{{Glob: "steam*"}}}, discord, false},
+		{"glob windows adı", BlockedApp{Match: []MatchRule{{Glob: "Steam*.EXE"}}}, steam, true},
+		{"regex komut satırı", BlockedApp{Match: []MatchRule{{Regex: "java .*minecraft"}}}, java, true},
+		{"regex harf duyarlı", BlockedApp{Match: []MatchRule{{Regex: "^Java$"}}}, java, false},
+		{"regex (?i)", BlockedApp{Match: []MatchRule{{Regex: "(?i)^Java$"}}}, java, true},
+		{"regex exe", BlockedApp{Match: []MatchRule{{Regex: "Discord$"}}}, discord, true},
+		{"kurallardan biri yeter", BlockedApp{Processes: []string{"steam"}, Match: []MatchRule{{Regex: "renderer"}}}, discord, true},
+	}
+
+	for _, tt := range tests {
+		m, err := newAppMatcher(tt.app)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := m.matches(tt.process); got != tt.want {
+			t.Errorf("%s: eşleşme %v, beklenen %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule  MatchRule
+		valid bool
+	}{
+		{MatchRule{Name: "a"}, true},
+		{MatchRule{Glob: "a*"}, true},
+		{MatchRule{}, false},
+		{MatchRule{Name: "a", Exe: "/a"}, false},
+		{MatchRule{Glob: "["}, false},
+		{MatchRule{Regex: "("}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.rule.validate(); (err == nil) != tt.valid {
+			t.Errorf("%+v: hata %v, geçerli olması beklenen %v", tt.rule, err, tt.valid)
+		}
+		if _, err := newAppMatcher(BlockedApp{Match: []MatchRule{tt.rule}}); (err == nil) != tt.valid {
+			t.Errorf("%+v: newAppMatcher hatası %v", tt.rule, err)
+		}
+	}
+}
+
+func TestAppMatcherFindSkipsSelf(t *testing.T) {
+	m, err := newAppMatcher(BlockedApp{Match: []MatchRule{{Glob: "*"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := m.Find([]Process{
+		{PID: os.Getpid(), Name: "screenrecord-client"},
+		{PID: 1, Name: "init"},
+	})
+	if len(found) != 1 || found[0].PID != 1 {
+		t.Fatalf("bulunan process'ler %v, client'ın kendisi eşleşmemeli", found)
+	}
+}
+
+// Tarayıcılar komut satırı deseniyle değil adla bulunur.
+func TestLinuxBrowserMatch(t *testing.T) {
+	processes := []Process{
+		{PID: 10, Name: "chrome", Exe: "/opt/google/chrome/chrome"},
+		{PID: 11, Name: "vim", Cmdline: []string{"vim", "chrome-notes.txt"}},
+		{PID: 12, Name: "firefox-esr"},
+		{PID: 13, Name: "grep", Cmdline: []string{"grep", "firefox"}},
+	}
+	for browser, want := range map[string][]int{"Google Chrome": {10}, "Firefox": {12}} {
+		app := linuxBrowsers[browser]
+		found := app.matcher().Find(processes)
+		if got := processIDs(found); len(got) != len(want) || got[0] != want[0] {
+			t.Errorf("%s: bulunan %v, beklenen %v", browser, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/csv"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Windows'ta process listesi tasklist çıktısından okunur. PPID, exe yolu ve
+// komut satırı tasklist'te bulunmadığı için boş kalır.
+func listProcesses() ([]Process, error) {
+	output, err := exec.Command("tasklist", "/v", "/fo", "csv", "/nh").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sütunlar: Image Name, PID, Session Name, Session#, Mem Usage, Status,
+	// User Name, CPU Time, Window Title
+	records, err := csv.NewReader(strings.NewReader(string(output))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []Process
+	for _, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(record[1])
+		if err != nil {
+			continue
+		}
+		p := Process{PID: pid, Name: record[0]}
+		if len(record) >= 7 && record[6] != "N/A" {
+			p.User = record[6]
+		}
+		processes = append(processes, p)
+	}
+	return processes, nil
+}
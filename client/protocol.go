@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+// Binary kare formatı (tüm sayılar big-endian):
+//
+//	"SRF1"            4 bayt magic
+//	header uzunluğu   uint32
+//	header            JSON (ScreenData, image alanları boş)
+//	payload'lar       her biri uint32 uzunluk + ham JPEG
+//
+// screen_update için tek payload (tam kare), screen_delta için header'daki
+// karo sırasıyla karo başına bir payload bulunur.
+
+const (
+	frameMagic       = "SRF1"
+	frameContentType = "application/x-screenrecord-frame"
+
+	frameFormatJSON   = "json"
+	frameFormatBinary = "binary"
+
+	// Header ve karo sayısı için makul üst sınırlar
+	maxFrameHeaderSize  = 1 << 20
+	maxFramePayloadSize = 64 << 20
+)
+
+type serverCapabilities struct {
+	FrameFormats []string `json:"frame_formats"`
+	// Sunucu screen_delta mesajlarından kareyi yeniden oluşturabiliyor mu
+	DeltaFrames bool `json:"delta_frames"`
+}
+
+func (sc *serverCapabilities) supports(format string) bool {
+	for _, f := range sc.FrameFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalBinaryFrame ScreenData'yı binary kare formatına çevirir.
+func marshalBinaryFrame(screenData *ScreenData) ([]byte, error) {
+	header := *screenData
+	header.Image = ""
+	header.ImageData = nil
+
+	var payloads [][]byte
+	switch screenData.Type {
+	case "screen_update":
+		if len(screenData.ImageData) == 0 {
+			return nil, errors.New("kare verisi boş")
+		}
+		payloads = append(payloads, screenData.ImageData)
+	case "screen_delta":
+		header.Tiles = make([]TileData, len(screenData.Tiles))
+		for i, tile := range screenData.Tiles {
+			header.Tiles[i] = TileData{X: tile.X, Y: tile.Y, W: tile.W, H: tile.H}
+			payloads = append(payloads, tile.Data)
+		}
+	default:
+		return nil, fmt.Errorf("bilinmeyen mesaj tipi: %s", screenData.Type)
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(frameMagic) + 4 + len(headerJSON)
+	for _, p := range payloads {
+		size += 4 + len(p)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	buf.WriteString(frameMagic)
+	binary.Write(buf, binary.BigEndian, uint32(len(headerJSON)))
+	buf.Write(headerJSON)
+	for _, p := range payloads {
+		binary.Write(buf, binary.BigEndian, uint32(len(p)))
+		buf.Write(p)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseBinaryFrame binary kareyi çözer; ham JPEG'ler ImageData/Data
+// alanlarına yerleştirilir.
+func parseBinaryFrame(data []byte) (*ScreenData, error) {
+	rd := bytes.NewReader(data)
+
+	magic := make([]byte, len(frameMagic))
+	if _, err := io.ReadFull(rd, magic); err != nil || string(magic) != frameMagic {
+		return nil, errors.New("geçersiz kare başlığı")
+	}
+
+	readChunk := func(limit uint32) ([]byte, error) {
+		var n uint32
+		if err := binary.Read(rd, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		if n > limit || int64(n) > int64(rd.Len()) {
+			return nil, fmt.Errorf("geçersiz parça uzunluğu: %d", n)
+		}
+		chunk := make([]byte, n)
+		_, err := io.ReadFull(rd, chunk)
+		return chunk, err
+	}
+
+	headerJSON, err := readChunk(maxFrameHeaderSize)
+	if err != nil {
+		return nil, fmt.Errorf("kare header'ı okunamadı: %v", err)
+	}
+	screenData := &ScreenData{}
+	if err := json.Unmarshal(headerJSON, screenData); err != nil {
+		return nil, fmt.Errorf("kare header'ı parse edilemedi: %v", err)
+	}
+
+	switch screenData.Type {
+	case "screen_update":
+		if screenData.ImageData, err = readChunk(maxFramePayloadSize); err != nil {
+			return nil, fmt.Errorf("kare verisi okunamadı: %v", err)
+		}
+	case "screen_delta":
+		for i := range screenData.Tiles {
+			if screenData.Tiles[i].Data, err = readChunk(maxFramePayloadSize); err != nil {
+				return nil, fmt.Errorf("karo %d okunamadı: %v", i, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("bilinmeyen mesaj tipi: %s", screenData.Type)
+	}
+
+	if rd.Len() != 0 {
+		return nil, fmt.Errorf("karenin sonunda %d fazla bayt var", rd.Len())
+	}
+	return screenData, nil
+}
+
+// jsonScreenData eski sunucular için ham JPEG'leri data URL'e çevirir.
+func jsonScreenData(screenData *ScreenData) *ScreenData {
+	out := *screenData
+	if len(out.ImageData) > 0 {
+		out.Image = jpegDataURL(out.ImageData)
+	}
+	if len(out.Tiles) > 0 {
+		out.Tiles = make([]TileData, len(screenData.Tiles))
+		for i, tile := range screenData.Tiles {
+			out.Tiles[i] = tile
+			if len(tile.Data) > 0 {
+				out.Tiles[i].Image = jpegDataURL(tile.Data)
+			}
+		}
+	}
+	return &out
+}
+
+// frameFormat sunucunun desteklediği en verimli kare formatı.
+func (sc *serverCapabilities) frameFormat() string {
+	if sc.supports(frameFormatBinary) {
+		return frameFormatBinary
+	}
+	return frameFormatJSON
+}
+
+// fetchCapabilitiesHTTP sunucunun desteklediği kare formatlarını ve delta
+// karelerini sorar. Endpoint'i olmayan eski sunucularda JSON tam karelere düşülür.
+func (c *Client) fetchCapabilitiesHTTP() serverCapabilities {
+	var caps serverCapabilities
+	resp, err := c.httpClient.Get(c.serverURL + "/api/capabilities")
+	if err != nil {
+		return caps
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return caps
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&caps); err != nil {
+		log.Printf("⚠️ Sunucu yetenekleri okunamadı: %v", err)
+		return serverCapabilities{}
+	}
+	return caps
+}
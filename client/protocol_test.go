@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBinaryFrameRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame ScreenData
+	}{
+		{
+			name: "keyframe",
+			frame: ScreenData{
+				Type: "screen_update", Timestamp: 1700000000, ClientID: "client-1",
+				Seq: 7, Width: 1920, Height: 1080, Keyframe: true,
+				ImageData: []byte{0xff, 0xd8, 0x01, 0x02, 0xff, 0xd9},
+			},
+		},
+		{
+			name: "biriktirilmiş kare",
+			frame: ScreenData{
+				Type: "screen_update", Timestamp: 1700000001, ClientID: "client-1",
+				Spooled: true, ImageData: []byte("jpeg"),
+			},
+		},
+		{
+			name: "delta",
+			frame: ScreenData{
+				Type: "screen_delta", Timestamp: 1700000002, ClientID: "client-2",
+				Seq: 8, Width: 100, Height: 70, TileSize: 64,
+				Tiles: []TileData{
+					{X: 0, Y: 0, W: 64, H: 64, Data: []byte("karo-1")},
+					{X: 64, Y: 64, W: 36, H: 6, Data: []byte("k2")},
+				},
+			},
+		},
+		{
+			name: "boş delta",
+			frame: ScreenData{
+				Type: "screen_delta", ClientID: "client-2", Seq: 9, Width: 100, Height: 70, TileSize: 64,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := marshalBinaryFrame(&tt.frame)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Sunucu formatı baytı baytına okur: magic, big-endian header uzunluğu, header JSON
+			if !bytes.HasPrefix(data, []byte(frameMagic)) {
+				t.Fatalf("magic eksik: %q", data[:4])
+			}
+			headerLen := binary.BigEndian.Uint32(data[4:8])
+			var header map[string]interface{}
+			if err := json.Unmarshal(data[8:8+headerLen], &header); err != nil {
+				t.Fatalf("header JSON değil: %v", err)
+			}
+			if header["type"] != tt.frame.Type || header["clientId"] != tt.frame.ClientID {
+				t.Errorf("header alanları: %v", header)
+			}
+			if _, ok := header["image"]; ok {
+				t.Error("header'da image alanı olmamalı")
+			}
+
+			got, err := parseBinaryFrame(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.frame) {
+				t.Errorf("çözülen kare:\n%+v\nbeklenen:\n%+v", *got, tt.frame)
+			}
+		})
+	}
+}
+
+func TestBinaryFramePayloadOrder(t *testing.T) {
+	frame := &ScreenData{Type: "screen_delta", Tiles: []TileData{
+		{X: 0, W: 1, H: 1, Data: []byte("AA")},
+		{X: 1, W: 1, H: 1, Data: []byte("BBB")},
+	}}
+	data, err := marshalBinaryFrame(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerLen := binary.BigEndian.Uint32(data[4:8])
+	payloads := data[8+headerLen:]
+	want := []byte{0, 0, 0, 2, 'A', 'A', 0, 0, 0, 3, 'B', 'B', 'B'}
+	if !bytes.Equal(payloads, want) {
+		t.Errorf("payload'lar %v, beklenen %v", payloads, want)
+	}
+}
+
+func TestParseBinaryFrameMalformed(t *testing.T) {
+	chunk := func(b []byte) []byte {
+		out := binary.BigEndian.AppendUint32(nil, uint32(len(b)))
+		return append(out, b...)
+	}
+	frame := func(parts ...[]byte) []byte {
+		return bytes.Join(append([][]byte{[]byte(frameMagic)}, parts...), nil)
+	}
+	update := chunk([]byte(`{"type":"screen_update","clientId":"c"}`))
+	delta := chunk([]byte(`{"type":"screen_delta","tiles":[{"x":0,"y":0,"w":1,"h":1},{"x":1,"y":0,"w":1,"h":1}]}`))
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"boş", nil, "geçersiz kare başlığı"},
+		{"hatalı magic", append([]byte("SRF2"), update...), "geçersiz kare başlığı"},
+		{"kısa magic", []byte("SR"), "geçersiz kare başlığı"},
+		{"header uzunluğu eksik", frame([]byte{0, 0}), "header'ı okunamadı"},
+		{"header kesik", frame(update[:10]), "geçersiz parça uzunluğu"},
+		{"header çok büyük", frame([]byte{0xff, 0xff, 0xff, 0xff}), "geçersiz parça uzunluğu"},
+		{"header JSON değil", frame(chunk([]byte("{bozuk"))), "parse edilemedi"},
+		{"bilinmeyen tip", frame(chunk([]byte(`{"type":"hello"}`))), "bilinmeyen mesaj tipi"},
+		{"kare verisi yok", frame(update), "kare verisi okunamadı"},
+		{"kare verisi kesik", frame(update, chunk([]byte("jpeg"))[:6]), "kare verisi okunamadı"},
+		{"karo eksik", frame(delta, chunk([]byte("a"))), "karo 1 okunamadı"},
+		{"fazla bayt", frame(update, chunk([]byte("jpeg")), []byte{0}), "fazla bayt"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseBinaryFrame(tt.data)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: hata %v, beklenen %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestMarshalBinaryFrameInvalid(t *testing.T) {
+	for _, frame := range []*ScreenData{
+		{Type: "screen_update"},
+		{Type: "hello", ImageData: []byte("jpeg")},
+	} {
+		if _, err := marshalBinaryFrame(frame); err == nil {
+			t.Errorf("%s: hata bekleniyordu", frame.Type)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Windows'ta sistem saat dilimi veritabanı olmayabilir
+)
+
+// Schedule bir uygulamanın hangi zaman aralıklarında engelleneceğini belirler.
+// Schedule yoksa uygulama her zaman engellenir. windows boşsa tüm gün
+// engellenir; exempt aralıkları (ör. öğle arası) engellemeden muaftır.
+//
+//	"schedule": {
+//	  "timezone": "Europe/Istanbul",
+//	  "windows": [{"days": ["weekdays"], "start": "09:00", "end": "17:00"}],
+//	  "exempt":  [{"days": ["weekdays"], "start": "12:00", "end": "13:00"}]
+//	}
+type Schedule struct {
+	Timezone string       `json:"timezone,omitempty"`
+	Windows  []TimeWindow `json:"windows,omitempty"`
+	Exempt   []TimeWindow `json:"exempt,omitempty"`
+
+	loc      *time.Location
+	compiled bool
+}
+
+// TimeWindow gün içi bir aralıktır. end, start'tan küçükse aralık gece
+// yarısını geçer ve start'ın günü sayılır (ör. cuma 22:00 - cumartesi 02:00).
+type TimeWindow struct {
+	Days  []string `json:"days,omitempty"` // boşsa her gün
+	Start string   `json:"start"`          // "HH:MM"
+	End   string   `json:"end"`            // "HH:MM", gün sonu için "24:00"
+
+	days     [7]bool // time.Weekday indeksli
+	startMin int
+	endMin   int
+}
+
+var weekdayNames = map[string][]time.Weekday{
+	"sun": {time.Sunday}, "sunday": {time.Sunday},
+	"mon": {time.Monday}, "monday": {time.Monday},
+	"tue": {time.Tuesday}, "tuesday": {time.Tuesday},
+	"wed": {time.Wednesday}, "wednesday": {time.Wednesday},
+	"thu": {time.Thursday}, "thursday": {time.Thursday},
+	"fri": {time.Friday}, "friday": {time.Friday},
+	"sat": {time.Saturday}, "saturday": {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekend":  {time.Saturday, time.Sunday},
+	"daily":    {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+}
+
+// Active verilen anda engellemenin geçerli olup olmadığını döner.
+func (s *Schedule) Active(now time.Time) bool {
+	if s == nil {
+		return true
+	}
+	if !s.compiled {
+		// Doğrulanmamış config (ör. elle oluşturulmuş); kopyayı derle
+		c := Schedule{
+			Timezone: s.Timezone,
+			Windows:  append([]TimeWindow(nil), s.Windows...),
+			Exempt:   append([]TimeWindow(nil), s.Exempt...),
+		}
+		if c.compile() != nil {
+			return true
+		}
+		s = &c
+	}
+
+	now = now.In(s.loc)
+	if len(s.Windows) > 0 && !anyWindowContains(s.Windows, now) {
+		return false
+	}
+	return !anyWindowContains(s.Exempt, now)
+}
+
+func anyWindowContains(windows []TimeWindow, now time.Time) bool {
+	for i := range windows {
+		if windows[i].contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *TimeWindow) contains(now time.Time) bool {
+	minute := now.Hour()*60 + now.Minute()
+	day := now.Weekday()
+
+	if w.startMin < w.endMin {
+		return w.days[day] && minute >= w.startMin && minute < w.endMin
+	}
+	// Gece yarısını geçen aralık: başlangıç günü akşamı veya ertesi sabah
+	yesterday := (day + 6) % 7
+	return (w.days[day] && minute >= w.startMin) || (w.days[yesterday] && minute < w.endMin)
+}
+
+// compile saat dilimini yükler ve aralıkları ayrıştırır.
+func (s *Schedule) compile() error {
+	loc, err := loadScheduleLocation(s.Timezone)
+	if err != nil {
+		return err
+	}
+	s.loc = loc
+
+	for _, windows := range [][]TimeWindow{s.Windows, s.Exempt} {
+		for i := range windows {
+			if err := windows[i].compile(); err != nil {
+				return err
+			}
+		}
+	}
+	s.compiled = true
+	return nil
+}
+
+func (w *TimeWindow) compile() error {
+	var err error
+	if w.startMin, err = parseClock(w.Start); err != nil {
+		return fmt.Errorf("start: %v", err)
+	}
+	if w.endMin, err = parseClock(w.End); err != nil {
+		return fmt.Errorf("end: %v", err)
+	}
+	if w.startMin == w.endMin {
+		return fmt.Errorf("start ve end aynı (%s)", w.Start)
+	}
+	if w.startMin == 24*60 {
+		return fmt.Errorf("start: 24:00 olamaz")
+	}
+
+	w.days = [7]bool{}
+	if len(w.Days) == 0 {
+		w.days = [7]bool{true, true, true, true, true, true, true}
+	}
+	for _, name := range w.Days {
+		days, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return fmt.Errorf("geçersiz gün %q", name)
+		}
+		for _, d := range days {
+			w.days[d] = true
+		}
+	}
+	return nil
+}
+
+func loadScheduleLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("geçersiz saat dilimi %q", name)
+	}
+	return loc, nil
+}
+
+// parseClock "HH:MM" biçimini gece yarısından itibaren dakikaya çevirir.
+func parseClock(value string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok || len(mm) != 2 {
+		return 0, fmt.Errorf("%q HH:MM biçiminde olmalı", value)
+	}
+	hour, err1 := strconv.Atoi(hh)
+	minute, err2 := strconv.Atoi(mm)
+	if err1 != nil || err2 != nil || hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("%q geçerli bir saat değil", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// validate her hatayı kendi JSON yoluyla raporlar.
+func (s *Schedule) validate(path string, errs *ValidationErrors) {
+	if _, err := loadScheduleLocation(s.Timezone); err != nil {
+		errs.add(path+".timezone", "%v", err)
+	}
+	check := func(field string, windows []TimeWindow) {
+		for i := range windows {
+			w := windows[i] // derlenmiş alanlar asıl config'e yazılmasın
+			if err := w.compile(); err != nil {
+				errs.add(fmt.Sprintf("%s.%s[%d]", path, field, i), "%v", err)
+			}
+		}
+	}
+	check("windows", s.Windows)
+	check("exempt", s.Exempt)
+	if len(s.Windows) == 0 && len(s.Exempt) == 0 {
+		errs.warn(path, "windows ve exempt boş; uygulama her zaman engellenir")
+	}
+}
+
+// compileSchedules doğrulanmış config'teki zamanlamaları bir kez derler.
+func (cfg *AppBlockerConfig) compileSchedules() {
+	for i := range cfg.BlockedApplications {
+		if s := cfg.BlockedApplications[i].Schedule; s != nil {
+			s.compile()
+		}
+	}
+}
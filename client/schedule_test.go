@@ -0,0 +1,215 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// 2024-01-01 pazartesidir; testlerdeki günler buna göre seçildi.
+func scheduleTime(t *testing.T, loc *time.Location, value string) time.Time {
+	t.Helper()
+	tm, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+func compiledSchedule(t *testing.T, s Schedule) *Schedule {
+	t.Helper()
+	var errs ValidationErrors
+	s.validate("$.schedule", &errs)
+	if err := errs.Err(); err != nil {
+		t.Fatalf("geçersiz zamanlama: %v", err)
+	}
+	if err := s.compile(); err != nil {
+		t.Fatal(err)
+	}
+	return &s
+}
+
+func TestScheduleActive(t *testing.T) {
+	utc := time.UTC
+	// Client'ın yerel saatinden bağımsız olduğunu göstermek için UTC-5'te verilen anlar
+	remote := time.FixedZone("UTC-5", -5*60*60)
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		loc      *time.Location
+		active   map[string]bool // "2006-01-02 15:04" -> beklenen
+	}{
+		{
+			name: "gece yarısını geçen aralık",
+			schedule: Schedule{Timezone: "UTC", Windows: []TimeWindow{
+				{Days: []string{"fri"}, Start: "22:00", End: "06:00"},
+			}},
+			loc: utc,
+			active: map[string]bool{
+				"2024-01-05 21:59": false, // cuma, başlamadan önce
+				"2024-01-05 22:00": true,
+				"2024-01-06 00:00": true, // cumartesi sabahı cuma aralığına sayılır
+				"2024-01-06 05:59": true,
+				"2024-01-06 06:00": false,
+				"2024-01-06 22:30": false, // cumartesi akşamı aralık yok
+				"2024-01-05 03:00": false, // perşembe gecesi aralık yok
+			},
+		},
+		{
+			name: "hafta içi geceleri",
+			schedule: Schedule{Timezone: "UTC", Windows: []TimeWindow{
+				{Days: []string{"weekdays"}, Start: "22:00", End: "06:00"},
+			}},
+			loc: utc,
+			active: map[string]bool{
+				"2024-01-06 05:00": true,  // cumartesi sabahı, cuma gecesinden
+				"2024-01-07 05:00": false, // pazar sabahı, cumartesi gecesi yok
+				"2024-01-08 05:00": false, // pazartesi sabahı, pazar gecesi yok
+				"2024-01-08 22:30": true,
+			},
+		},
+		{
+			name: "muaf aralık",
+			schedule: Schedule{
+				Timezone: "UTC",
+				Windows:  []TimeWindow{{Days: []string{"weekdays"}, Start: "09:00", End: "17:00"}},
+				Exempt:   []TimeWindow{{Days: []string{"weekdays"}, Start: "12:00", End: "13:00"}},
+			},
+			loc: utc,
+			active: map[string]bool{
+				"2024-01-01 11:59": true,
+				"2024-01-01 12:00": false,
+				"2024-01-01 12:59": false,
+				"2024-01-01 13:00": true,
+				"2024-01-01 17:00": false,
+			},
+		},
+		{
+			name: "sadece muaf aralık",
+			schedule: Schedule{Timezone: "UTC", Exempt: []TimeWindow{
+				{Start: "23:00", End: "07:00"},
+			}},
+			loc: utc,
+			active: map[string]bool{
+				"2024-01-03 22:59": true,
+				"2024-01-03 23:00": false,
+				"2024-01-04 06:59": false,
+				"2024-01-04 07:00": true,
+			},
+		},
+		{
+			name: "hafta sonu sınırı",
+			schedule: Schedule{Timezone: "UTC", Windows: []TimeWindow{
+				{Days: []string{"weekdays"}, Start: "09:00", End: "24:00"},
+			}},
+			loc: utc,
+			active: map[string]bool{
+				"2024-01-05 23:59": true, // cuma gün sonu
+				"2024-01-06 00:00": false,
+				"2024-01-07 23:59": false,
+				"2024-01-08 00:00": false, // pazartesi, aralık 09:00'da başlar
+				"2024-01-08 08:59": false,
+				"2024-01-08 09:00": true,
+			},
+		},
+		{
+			name: "farklı saat dilimi",
+			schedule: Schedule{Timezone: "Asia/Tokyo", Windows: []TimeWindow{
+				{Days: []string{"weekdays"}, Start: "09:00", End: "17:00"},
+			}},
+			loc: remote,
+			active: map[string]bool{
+				"2024-01-07 18:59": false, // Tokyo'da pazartesi 08:59
+				"2024-01-07 19:00": true,  // Tokyo'da pazartesi 09:00 (UTC-5'te hâlâ pazar)
+				"2024-01-05 02:59": true,  // Tokyo'da cuma 16:59
+				"2024-01-05 03:00": false,
+				"2024-01-05 19:00": false, // Tokyo'da cumartesi 09:00
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := compiledSchedule(t, tt.schedule)
+			for value, want := range tt.active {
+				if got := s.Active(scheduleTime(t, tt.loc, value)); got != want {
+					t.Errorf("%s: Active = %v, beklenen %v", value, got, want)
+				}
+			}
+		})
+	}
+
+	var none *Schedule
+	if !none.Active(time.Now()) {
+		t.Error("zamanlaması olmayan uygulama her zaman engellenmeli")
+	}
+}
+
+// Engelleyici zamanlamayı c.now saatine göre uygular.
+func TestCheckAndBlockAppsUsesClock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep komutu yok")
+	}
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep bulunamadı")
+	}
+	cmd := exec.Command(sleep, "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	cfg, errs := parseAppBlockerConfig([]byte(`{
+		"blocked_applications": [{
+			"name": "Sleep",
+			"processes": ["sleep"],
+			"schedule": {
+				"timezone": "UTC",
+				"windows": [{"days": ["mon"], "start": "22:00", "end": "06:00"}]
+			}
+		}],
+		"settings": {
+			"check_interval_seconds": 3,
+			"auto_kill": false,
+			"show_warnings": false,
+			"max_warnings": 1000,
+			"app_blocker_enabled": true
+		}
+	}`))
+	if err := errs.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var clock time.Time
+	c := &Client{
+		appBlocker:    cfg,
+		warningCounts: make(map[string]int),
+		detectedApps:  make(map[string]bool),
+		usage:         loadAppUsage(filepath.Join(t.TempDir(), "usage.json")),
+		now:           func() time.Time { return clock },
+	}
+
+	for _, step := range []struct {
+		at       string
+		detected bool
+	}{
+		{"2024-01-01 21:59", false},
+		{"2024-01-01 22:00", true},
+		{"2024-01-02 05:59", true},
+		{"2024-01-02 06:00", false},
+		{"2024-01-02 22:00", false}, // salı gecesi aralık yok
+	} {
+		clock = scheduleTime(t, time.UTC, step.at)
+		c.checkAndBlockApps()
+		if got := c.detectedApps["Sleep"]; got != step.detected {
+			t.Errorf("%s: tespit = %v, beklenen %v", step.at, got, step.detected)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Çevrimdışı kare biriktirme: sunucuya ulaşılamazken düşük hızda tam kareler
+// (binary kare formatında) diske yazılır. Bağlantı geri gelince en eskiden
+// başlayarak orijinal zaman damgalarıyla sunucuya gönderilir. Boyut/yaş
+// limitleri aşılınca en eski kareler silinir.
+
+const (
+	spoolFileExt        = ".srf"
+	spoolReplayInterval = 2 * time.Second
+)
+
+type frameSpool struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+	interval time.Duration
+	now      func() time.Time // testte değiştirilebilir
+
+	mu         sync.Mutex
+	lastStored time.Time
+	counter    uint64
+}
+
+type spoolEntry struct {
+	path    string
+	size    int64
+	created time.Time
+}
+
+func openFrameSpool(settings SpoolSettings) (*frameSpool, error) {
+	if err := os.MkdirAll(settings.Dir, 0700); err != nil {
+		return nil, err
+	}
+	s := &frameSpool{
+		dir:      settings.Dir,
+		maxBytes: int64(settings.MaxSizeMB) << 20,
+		maxAge:   time.Duration(settings.MaxAgeHours) * time.Hour,
+		interval: time.Duration(settings.IntervalSeconds) * time.Second,
+		now:      time.Now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entries, err := s.pruneLocked(); err == nil && len(entries) > 0 {
+		log.Printf("📼 Diskte gönderilmeyi bekleyen %d kare var", len(entries))
+	}
+	return s, nil
+}
+
+// due son saklanan kareden bu yana yeterli süre geçtiyse true döner.
+func (s *frameSpool) due() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now().Sub(s.lastStored) >= s.interval
+}
+
+// Store kareyi diske yazar ve limitleri uygular.
+func (s *frameSpool) Store(screenData *ScreenData) error {
+	payload, err := marshalBinaryFrame(screenData)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.counter++
+	// Dosya adı oluşturulma zamanına göre sıralanabilir
+	name := fmt.Sprintf("%020d-%06d%s", now.UnixNano(), s.counter%1000000, spoolFileExt)
+	if err := writeFileAtomic(filepath.Join(s.dir, name), payload, 0600); err != nil {
+		return err
+	}
+	s.lastStored = now
+
+	_, err = s.pruneLocked()
+	return err
+}
+
+// entriesLocked kareleri en eskiden en yeniye sıralı döner.
+func (s *frameSpool) entriesLocked() ([]spoolEntry, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []spoolEntry
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasSuffix(name, spoolFileExt) {
+			continue
+		}
+		nanos, err := strconv.ParseInt(strings.SplitN(name, "-", 2)[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, spoolEntry{
+			path:    filepath.Join(s.dir, name),
+			size:    info.Size(),
+			created: time.Unix(0, nanos),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+	return entries, nil
+}
+
+// pruneLocked yaş ve boyut limitlerini aşan en eski kareleri siler.
+func (s *frameSpool) pruneLocked() ([]spoolEntry, error) {
+	entries, err := s.entriesLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+
+	dropped := 0
+	for len(entries) > 0 {
+		oldest := entries[0]
+		if s.now().Sub(oldest.created) <= s.maxAge && total <= s.maxBytes {
+			break
+		}
+		if err := os.Remove(oldest.path); err != nil && !os.IsNotExist(err) {
+			return entries, err
+		}
+		total -= oldest.size
+		entries = entries[1:]
+		dropped++
+	}
+	if dropped > 0 {
+		log.Printf("🗑️ Kare biriktirme limiti aşıldı, en eski %d kare silindi", dropped)
+	}
+	return entries, nil
+}
+
+// Oldest en eski kareyi okur. Biriken kare yoksa path boş döner.
+func (s *frameSpool) Oldest() (string, *ScreenData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.entriesLocked()
+	if err != nil || len(entries) == 0 {
+		return "", nil, err
+	}
+
+	path := entries[0].path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path, nil, err
+	}
+	screenData, err := parseBinaryFrame(data)
+	return path, screenData, err
+}
+
+func (s *frameSpool) Remove(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("⚠️ Biriktirilmiş kare silinemedi: %v", err)
+	}
+}
+
+// capturedFrame kodlanmış bir karenin kaynağı ve yakalanma zamanı.
+type capturedFrame struct {
+	img image.Image
+	at  time.Time
+}
+
+// spoolScreen sunucuya gönderilemeyen ekranı (düşük hızda) diske yazar.
+// Delta'lar tek başına anlamsız olduğu için her zaman tam kare saklanır.
+func (c *Client) spoolScreen(img image.Image) {
+	if c.spool == nil || !c.spool.due() {
+		return
+	}
+	if err := c.spoolImage(img, time.Now()); err != nil {
+		log.Printf("⚠️ Kare diske yazılamadı: %v", err)
+	}
+}
+
+// spoolDroppedFrames WebSocket bağlantısı koparken gönderilemeyen kareleri
+// biriktirme hızından bağımsız olarak diske yazar. Tam kareler olduğu gibi
+// saklanır; atılan delta'lar yerine kuyruğa giren son ekran tam kare olarak saklanır.
+func (c *Client) spoolDroppedFrames(frames []*ScreenData) {
+	if c.spool == nil {
+		return
+	}
+
+	stored, deltas := 0, false
+	for _, screenData := range frames {
+		if screenData.Type != "screen_update" {
+			deltas = true
+			continue
+		}
+		spooled := *screenData
+		spooled.Spooled = true
+		if err := c.spool.Store(&spooled); err != nil {
+			log.Printf("⚠️ Kare diske yazılamadı: %v", err)
+			continue
+		}
+		stored++
+	}
+	if last, _ := c.lastFrame.Load().(*capturedFrame); deltas && last != nil {
+		if err := c.spoolImage(last.img, last.at); err != nil {
+			log.Printf("⚠️ Kare diske yazılamadı: %v", err)
+		} else {
+			stored++
+		}
+	}
+	if stored > 0 {
+		log.Printf("📼 Bağlantı koparken gönderilemeyen kareler diske biriktirildi (%d kare)", stored)
+	}
+}
+
+// spoolImage ekranı at zaman damgasıyla tam kare olarak diske yazar.
+func (c *Client) spoolImage(img image.Image, at time.Time) error {
+	small := c.downscaleImage(img)
+	encoded, err := encodeJPEG(small, c.encoder.Quality())
+	if err != nil {
+		return fmt.Errorf("kare kodlanamadı: %v", err)
+	}
+
+	screenData := &ScreenData{
+		Type:      "screen_update",
+		Timestamp: at.Unix(),
+		ClientID:  c.clientID,
+		Width:     small.Bounds().Dx(),
+		Height:    small.Bounds().Dy(),
+		Keyframe:  true,
+		Spooled:   true,
+		ImageData: encoded,
+	}
+	return c.spool.Store(screenData)
+}
+
+func (c *Client) online() bool {
+	if c.useHTTP {
+		return c.isConnected.Load()
+	}
+	return c.ws != nil && c.ws.Connected()
+}
+
+// replaySpool bağlantı varken biriken kareleri sırayla sunucuya gönderir.
+func (c *Client) replaySpool(ctx context.Context) {
+	ticker := time.NewTicker(spoolReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !c.online() {
+			continue
+		}
+		if sent := c.replaySpoolOnce(ctx); sent > 0 {
+			log.Printf("📼 Biriktirilmiş %d kare sunucuya gönderildi", sent)
+		}
+	}
+}
+
+// replaySpoolOnce biriken kareleri en eskiden başlayarak gönderir; gönderim
+// hatasında durur ve kalan kareler bir sonraki turda gönderilir.
+func (c *Client) replaySpoolOnce(ctx context.Context) int {
+	sent := 0
+	for ctx.Err() == nil {
+		path, screenData, err := c.spool.Oldest()
+		if path == "" {
+			if err != nil {
+				log.Printf("⚠️ Kare biriktirme dizini okunamadı: %v", err)
+			}
+			break
+		}
+		if err != nil {
+			log.Printf("⚠️ Bozuk biriktirilmiş kare atlandı (%s): %v", filepath.Base(path), err)
+			c.spool.Remove(path)
+			continue
+		}
+
+		if err := c.sendSpooledFrame(ctx, screenData); err != nil {
+			var statusErr *httpStatusError
+			if errors.As(err, &statusErr) && statusErr.permanent() {
+				log.Printf("⚠️ Sunucu biriktirilmiş kareyi reddetti, atlandı: %v", err)
+				c.spool.Remove(path)
+				continue
+			}
+			// Tekrar bağlantı sorunu, sonraki turda devam edilir
+			break
+		}
+		c.spool.Remove(path)
+		sent++
+	}
+	return sent
+}
+
+// sendSpooledFrame biriktirilmiş kareyi kullanılan transport üzerinden
+// gönderir. WebSocket'te canlı kare kuyruğu atlanır ve yazım beklenir, böylece
+// kare bağlantıya yazılmadan diskten silinmez.
+func (c *Client) sendSpooledFrame(ctx context.Context, screenData *ScreenData) error {
+	if c.useHTTP {
+		return c.sendScreenHTTP(screenData)
+	}
+	return c.ws.SendFrameWait(ctx, screenData)
+}
+
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// permanent aynı isteğin tekrar denenmesinin anlamsız olduğu durumlar.
+func (e *httpStatusError) permanent() bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
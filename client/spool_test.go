@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testSpool saati elle ilerletilen, limitleri bayt cinsinden verilen bir spool açar.
+func testSpool(t *testing.T, maxBytes int64, maxAge time.Duration) (*frameSpool, *time.Time) {
+	t.Helper()
+	s, err := openFrameSpool(SpoolSettings{Dir: t.TempDir(), MaxSizeMB: 1, MaxAgeHours: 1, IntervalSeconds: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return clock }
+	s.maxBytes = maxBytes
+	s.maxAge = maxAge
+	return s, &clock
+}
+
+func spooledFrame(ts int64) *ScreenData {
+	return &ScreenData{Type: "screen_update", Timestamp: ts, ClientID: "c", Spooled: true, ImageData: []byte("jpeg")}
+}
+
+// spooledTimestamps spool'daki karelerin zaman damgalarını en eskiden başlayarak döner.
+func spooledTimestamps(t *testing.T, s *frameSpool) []int64 {
+	t.Helper()
+	s.mu.Lock()
+	entries, err := s.entriesLocked()
+	s.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var timestamps []int64
+	for _, e := range entries {
+		data, err := os.ReadFile(e.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sd, err := parseBinaryFrame(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		timestamps = append(timestamps, sd.Timestamp)
+	}
+	return timestamps
+}
+
+func equalInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFrameSpoolSizeLimit(t *testing.T) {
+	payload, err := marshalBinaryFrame(spooledFrame(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Üç kare sığar, fazlası en eskiden başlayarak silinir
+	s, clock := testSpool(t, int64(3*len(payload)), time.Hour)
+
+	for ts := int64(1); ts <= 5; ts++ {
+		if err := s.Store(spooledFrame(ts)); err != nil {
+			t.Fatal(err)
+		}
+		*clock = clock.Add(time.Second)
+	}
+	if got := spooledTimestamps(t, s); !equalInt64s(got, []int64{3, 4, 5}) {
+		t.Fatalf("kalan kareler %v, beklenen [3 4 5]", got)
+	}
+}
+
+func TestFrameSpoolAgeLimit(t *testing.T) {
+	s, clock := testSpool(t, 1<<20, time.Hour)
+
+	for ts := int64(1); ts <= 3; ts++ {
+		if err := s.Store(spooledFrame(ts)); err != nil {
+			t.Fatal(err)
+		}
+		*clock = clock.Add(20 * time.Minute)
+	}
+	// İlk kare 60, ikincisi 40 dakikalık; sınırda olan silinmez
+	if err := s.Store(spooledFrame(4)); err != nil {
+		t.Fatal(err)
+	}
+	if got := spooledTimestamps(t, s); !equalInt64s(got, []int64{1, 2, 3, 4}) {
+		t.Fatalf("kalan kareler %v, beklenen [1 2 3 4]", got)
+	}
+
+	*clock = clock.Add(30 * time.Minute)
+	if err := s.Store(spooledFrame(5)); err != nil {
+		t.Fatal(err)
+	}
+	if got := spooledTimestamps(t, s); !equalInt64s(got, []int64{3, 4, 5}) {
+		t.Fatalf("kalan kareler %v, beklenen [3 4 5]", got)
+	}
+
+	// Yeniden açılınca süresi dolmuş kareler silinir
+	reopened, err := openFrameSpool(SpoolSettings{Dir: s.dir, MaxSizeMB: 1, MaxAgeHours: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := spooledTimestamps(t, reopened); len(got) != 0 {
+		t.Fatalf("eski kareler açılışta silinmedi: %v", got)
+	}
+}
+
+func TestFrameSpoolOldestOrder(t *testing.T) {
+	s, clock := testSpool(t, 1<<20, time.Hour)
+
+	// Aynı saniyede saklanan kareler de sırasını korur
+	for _, ts := range []int64{10, 11, 12} {
+		if err := s.Store(spooledFrame(ts)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	*clock = clock.Add(time.Nanosecond)
+	if err := s.Store(spooledFrame(13)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []int64{10, 11, 12, 13} {
+		path, sd, err := s.Oldest()
+		if err != nil || path == "" {
+			t.Fatalf("Oldest: %q, %v", path, err)
+		}
+		if sd.Timestamp != want || !sd.Spooled {
+			t.Fatalf("Oldest zaman damgası %d (spooled %v), beklenen %d", sd.Timestamp, sd.Spooled, want)
+		}
+		s.Remove(path)
+	}
+	if path, _, err := s.Oldest(); path != "" || err != nil {
+		t.Fatalf("boş spool: %q, %v", path, err)
+	}
+}
+
+// testSpoolClient HTTP modunda, kareleri verilen sunucuya gönderen bir client.
+func testSpoolClient(t *testing.T, serverURL string) *Client {
+	t.Helper()
+	s, _ := testSpool(t, 1<<20, time.Hour)
+	c := &Client{
+		serverURL:  serverURL,
+		clientID:   "c",
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		useHTTP:    true,
+		spool:      s,
+		encoder:    newFrameEncoder(defaultClientConfig().Encoding),
+	}
+	c.frameFormat.Store(frameFormatBinary)
+	return c
+}
+
+func TestReplaySpoolHTTP(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []int64
+		fail     = map[int64]int{3: http.StatusBadRequest, 4: http.StatusServiceUnavailable}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sd, err := parseBinaryFrame(body)
+		if err != nil || !sd.Spooled {
+			http.Error(w, "geçersiz", http.StatusTeapot)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if status := fail[sd.Timestamp]; status != 0 {
+			delete(fail, sd.Timestamp)
+			w.WriteHeader(status)
+			return
+		}
+		received = append(received, sd.Timestamp)
+	}))
+	defer srv.Close()
+
+	c := testSpoolClient(t, srv.URL)
+	for ts := int64(1); ts <= 5; ts++ {
+		if err := c.spool.Store(spooledFrame(ts)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 3 kalıcı olarak reddedilir ve atlanır; 4'te bağlantı sorunu olduğu için durulur
+	if sent := c.replaySpoolOnce(context.Background()); sent != 2 {
+		t.Fatalf("ilk turda %d kare gönderildi, beklenen 2", sent)
+	}
+	if got := spooledTimestamps(t, c.spool); !equalInt64s(got, []int64{4, 5}) {
+		t.Fatalf("spool'da kalan kareler %v, beklenen [4 5]", got)
+	}
+
+	if sent := c.replaySpoolOnce(context.Background()); sent != 2 {
+		t.Fatalf("ikinci turda %d kare gönderildi, beklenen 2", sent)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !equalInt64s(received, []int64{1, 2, 4, 5}) {
+		t.Fatalf("sunucuya gelen sıra %v, beklenen [1 2 4 5]", received)
+	}
+}
+
+func TestReplaySpoolWebSocket(t *testing.T) {
+	frames := make(chan *ScreenData, 16)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msgType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType == websocket.TextMessage && strings.Contains(string(data), "client_register") {
+				conn.WriteJSON(map[string]interface{}{"type": "register_ack", "frame_format": "binary"})
+				continue
+			}
+			if sd, err := parseBinaryFrame(data); err == nil {
+				frames <- sd
+			}
+		}
+	}))
+	defer srv.Close()
+
+	// HTTP endpoint'i olmayan sunucu: kareler sadece WebSocket'ten gitmeli
+	c := testSpoolClient(t, "http://127.0.0.1:1")
+	c.useHTTP = false
+	settings := defaultClientConfig().Transport
+	c.ws = newWSTransport("ws"+strings.TrimPrefix(srv.URL, "http"), settings, c.clientID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.ws.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.ws.FrameFormat() != frameFormatBinary {
+		if time.Now().After(deadline) {
+			t.Fatal("WebSocket bağlanmadı")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for ts := int64(1); ts <= 3; ts++ {
+		if err := c.spool.Store(spooledFrame(ts)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sent := c.replaySpoolOnce(ctx); sent != 3 {
+		t.Fatalf("%d kare gönderildi, beklenen 3", sent)
+	}
+	for want := int64(1); want <= 3; want++ {
+		select {
+		case sd := <-frames:
+			if sd.Timestamp != want || !sd.Spooled {
+				t.Fatalf("sunucuya gelen kare %d (spooled %v), beklenen %d", sd.Timestamp, sd.Spooled, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%d. kare sunucuya ulaşmadı", want)
+		}
+	}
+	if got := spooledTimestamps(t, c.spool); len(got) != 0 {
+		t.Fatalf("gönderilen kareler spool'dan silinmedi: %v", got)
+	}
+}
+
+// Bağlantı koparken WebSocket kuyruğunda kalan kareler diske yazılır.
+func TestSpoolDroppedFrames(t *testing.T) {
+	c := testSpoolClient(t, "")
+	at := time.Unix(1700000100, 0)
+	c.lastFrame.Store(&capturedFrame{img: grayFrame(32, 32), at: at})
+
+	c.spoolDroppedFrames([]*ScreenData{
+		{Type: "screen_update", Timestamp: 1700000000, ClientID: "c", Keyframe: true, ImageData: []byte("jpeg")},
+		{Type: "screen_delta", Timestamp: 1700000050, ClientID: "c"},
+		{Type: "screen_delta", Timestamp: 1700000100, ClientID: "c"},
+	})
+
+	// Tam kare olduğu gibi, delta'ların yerine son kare tam kare olarak saklanır
+	if got := spooledTimestamps(t, c.spool); !equalInt64s(got, []int64{1700000000, at.Unix()}) {
+		t.Fatalf("biriktirilen kareler %v", got)
+	}
+	for {
+		path, sd, err := c.spool.Oldest()
+		if path == "" || err != nil {
+			break
+		}
+		if sd.Type != "screen_update" || !sd.Spooled || len(sd.ImageData) == 0 {
+			t.Errorf("biriktirilen kare tam kare değil: %s, spooled %v", sd.Type, sd.Spooled)
+		}
+		c.spool.Remove(path)
+	}
+}
+
+func TestWebSocketDisconnectHandsOverQueuedFrames(t *testing.T) {
+	tr := newWSTransport("ws://127.0.0.1:1/ws", TransportSettings{QueueSize: 4}, "c")
+	var dropped []*ScreenData
+	tr.onFramesDropped = func(frames []*ScreenData) { dropped = append(dropped, frames...) }
+
+	for ts := int64(1); ts <= 3; ts++ {
+		tr.SendFrame(&ScreenData{Type: "screen_delta", Timestamp: ts})
+	}
+	tr.framesDropped(tr.drainFrames())
+	if len(dropped) != 3 || dropped[0].Timestamp != 1 || dropped[2].Timestamp != 3 {
+		t.Fatalf("devredilen kareler %v", dropped)
+	}
+
+	// Bağlı değilken bekleyen gönderim hemen hata döner
+	if err := tr.SendFrameWait(context.Background(), spooledFrame(1)); err == nil {
+		t.Fatal("bağlantı yokken hata bekleniyordu")
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Kayıt yapıldığı kullanıcıdan gizlenmez: çalışan client durumunu kalıcı bir
+// masaüstü bildirimiyle gösterir ve durum dosyasına yazar. "status" alt
+// komutu aynı bilgiyi bu dosyadan okuyup yazdırır.
+
+const statusUpdateInterval = 5 * time.Second
+
+type ClientStatus struct {
+	PID            int       `json:"pid"`
+	ClientID       string    `json:"client_id"`
+	Server         string    `json:"server"`
+	Transport      string    `json:"transport"`
+	Connected      bool      `json:"connected"`
+	Recording      bool      `json:"recording"`
+	Paused         bool      `json:"paused"`
+	PrivacyPause   bool      `json:"privacy_pause"`
+	PauseUntil     time.Time `json:"pause_until"`
+	ConsentPending bool      `json:"consent_pending"`
+	AppBlocker     bool      `json:"app_blocker"`
+	WebsiteBlocker bool      `json:"website_blocker"`
+	StartedAt      time.Time `json:"started_at"`
+	LastCaptureAt  time.Time `json:"last_capture_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (c *Client) currentStatus() ClientStatus {
+	st := ClientStatus{
+		PID:            os.Getpid(),
+		ClientID:       c.clientID,
+		Server:         c.serverURL,
+		Transport:      c.config.Transport.Mode,
+		Connected:      c.online(),
+		Recording:      c.super.Running(taskCapture) && !c.capturePaused.Load() && !c.privacyPaused.Load(),
+		Paused:         c.capturePaused.Load(),
+		PrivacyPause:   c.privacyPaused.Load(),
+		ConsentPending: !c.consentGranted.Load(),
+		AppBlocker:     c.super.Running(taskAppBlocker),
+		WebsiteBlocker: c.super.Running(taskWebsiteBlocker),
+		StartedAt:      c.startedAt,
+		UpdatedAt:      time.Now(),
+	}
+	if st.PrivacyPause {
+		st.PauseUntil, _ = c.pause.active(st.UpdatedAt)
+	}
+	if ns := c.lastCaptureAt.Load(); ns != 0 {
+		st.LastCaptureAt = time.Unix(0, ns)
+	}
+	return st
+}
+
+// summary bildirimde ve status çıktısında kullanılan kısa açıklama.
+func (st ClientStatus) summary() string {
+	var lines []string
+	switch {
+	case st.Recording:
+		lines = append(lines, "🔴 Ekran kaydı aktif")
+	case st.PrivacyPause:
+		lines = append(lines, fmt.Sprintf("⏸️ Gizlilik molası (%s'e kadar)", st.PauseUntil.Local().Format("15:04")))
+	case st.Paused:
+		lines = append(lines, "⏸️ Ekran kaydı duraklatıldı")
+	case st.ConsentPending:
+		lines = append(lines, "🔒 Ekran kaydı onay bekliyor (client consent)")
+	default:
+		lines = append(lines, "⏹️ Ekran kaydı kapalı")
+	}
+
+	connection := "bağlı"
+	if !st.Connected {
+		connection = "bağlantı yok"
+	}
+	lines = append(lines, fmt.Sprintf("Sunucu: %s (%s)", st.Server, connection))
+	lines = append(lines, fmt.Sprintf("Uygulama engelleme: %s", onOff(st.AppBlocker)))
+	lines = append(lines, fmt.Sprintf("Website engelleme: %s", onOff(st.WebsiteBlocker)))
+	return strings.Join(lines, "\n")
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "açık"
+	}
+	return "kapalı"
+}
+
+// runStatusReporter durum dosyasını periyodik olarak günceller ve durum
+// değiştiğinde kalıcı bildirimi yeniler.
+func (c *Client) runStatusReporter(ctx context.Context) {
+	ticker := time.NewTicker(statusUpdateInterval)
+	defer ticker.Stop()
+
+	var shown string
+	for {
+		shown = c.reportStatus(shown)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportStatus durum dosyasını yazar ve özet shown'dan farklıysa bildirimi
+// yeniler. Gösterilen özeti döner; bildirim gösterilemediyse bir sonraki
+// turda tekrar denenir.
+func (c *Client) reportStatus(shown string) string {
+	st := c.currentStatus()
+	c.writeStatus(st)
+
+	summary := st.summary()
+	if summary == shown || !c.config.Status.Notification {
+		return shown
+	}
+	err := c.notify(Notification{
+		Key:        "recording_status",
+		Title:      "Screen Recorder",
+		Body:       summary,
+		Urgency:    notifyUrgencyLow,
+		Persistent: true,
+	})
+	if err != nil {
+		return shown
+	}
+	return summary
+}
+
+func (c *Client) writeStatus(st ClientStatus) {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomic(c.config.Status.File, data, 0644); err != nil {
+		log.Printf("⚠️ Durum dosyası yazılamadı: %v", err)
+	}
+}
+
+// clearStatus kapanışta durum dosyasını siler ve kullanıcıya kaydın bittiğini bildirir.
+func (c *Client) clearStatus() {
+	if err := os.Remove(c.config.Status.File); err != nil && !os.IsNotExist(err) {
+		log.Printf("⚠️ Durum dosyası silinemedi: %v", err)
+	}
+	if c.config.Status.Notification {
+		c.notify(Notification{
+			Key:     "recording_status",
+			Title:   "Screen Recorder",
+			Body:    "⏹️ Ekran kaydı durduruldu",
+			Urgency: notifyUrgencyLow,
+		})
+	}
+}
+
+// runStatus çalışan client'ın durumunu yazdırır; client çalışmıyorsa 1 döner.
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	file := fs.String("file", loadClientConfig().Status.File, "durum dosyası")
+	asJSON := fs.Bool("json", false, "JSON olarak yazdır")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("⏹️ Client çalışmıyor")
+			return 1
+		}
+		fmt.Printf("❌ %s okunamadı: %v\n", *file, err)
+		return 1
+	}
+	var st ClientStatus
+	if err := json.Unmarshal(data, &st); err != nil {
+		fmt.Printf("❌ %s bozuk: %v\n", *file, err)
+		return 1
+	}
+
+	// Client çökmüş olabilir (dosya silinmeden kalmış)
+	if !processAlive(st.PID) || time.Since(st.UpdatedAt) > 3*statusUpdateInterval {
+		fmt.Printf("⏹️ Client çalışmıyor (son durum %s)\n", st.UpdatedAt.Format("2006-01-02 15:04:05"))
+		return 1
+	}
+
+	if *asJSON {
+		fmt.Println(string(data))
+		return 0
+	}
+	fmt.Println(st.summary())
+	fmt.Printf("Client ID: %s (pid %d, %s)\n", st.ClientID, st.PID, st.Transport)
+	fmt.Printf("Başlangıç: %s\n", st.StartedAt.Format("2006-01-02 15:04:05"))
+	if !st.LastCaptureAt.IsZero() {
+		fmt.Printf("Son kare: %s\n", st.LastCaptureAt.Format("15:04:05"))
+	}
+	return 0
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusSummary(t *testing.T) {
+	until := time.Date(2024, 3, 10, 14, 30, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		status ClientStatus
+		want   []string
+	}{
+		{
+			name:   "kayıt aktif",
+			status: ClientStatus{Server: "http://s", Connected: true, Recording: true, AppBlocker: true},
+			want:   []string{"🔴 Ekran kaydı aktif", "Sunucu: http://s (bağlı)", "Uygulama engelleme: açık", "Website engelleme: kapalı"},
+		},
+		{
+			name:   "gizlilik molası",
+			status: ClientStatus{Server: "http://s", PrivacyPause: true, Paused: true, PauseUntil: until, WebsiteBlocker: true},
+			want:   []string{"⏸️ Gizlilik molası (14:30'e kadar)", "Sunucu: http://s (bağlantı yok)", "Uygulama engelleme: kapalı", "Website engelleme: açık"},
+		},
+		{
+			name:   "sunucu duraklattı",
+			status: ClientStatus{Server: "http://s", Paused: true, ConsentPending: true},
+			want:   []string{"⏸️ Ekran kaydı duraklatıldı"},
+		},
+		{
+			name:   "onay bekliyor",
+			status: ClientStatus{Server: "http://s", ConsentPending: true},
+			want:   []string{"🔒 Ekran kaydı onay bekliyor (client consent)"},
+		},
+		{
+			name:   "kapalı",
+			status: ClientStatus{Server: "http://s"},
+			want:   []string{"⏹️ Ekran kaydı kapalı"},
+		},
+	}
+
+	for _, tt := range tests {
+		lines := strings.Split(tt.status.summary(), "\n")
+		if len(lines) != 4 {
+			t.Errorf("%s: %d satır, beklenen 4: %q", tt.name, len(lines), lines)
+			continue
+		}
+		for i, want := range tt.want {
+			if lines[i] != want {
+				t.Errorf("%s: satır %d = %q, beklenen %q", tt.name, i, lines[i], want)
+			}
+		}
+	}
+}
+
+// captureStdout f'in standart çıktıya yazdıklarını döner.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	f()
+	w.Close()
+	return <-done
+}
+
+func TestRunStatus(t *testing.T) {
+	chdirTemp(t)
+	file := filepath.Join(t.TempDir(), "status.json")
+	dead := exitedProcess(t).PID
+
+	tests := []struct {
+		name   string
+		status *ClientStatus
+		age    time.Duration // durumun yazılmasından bu yana geçen süre
+		raw    string
+		args   []string
+		code   int
+		output string
+	}{
+		{name: "dosya yok", code: 1, output: "Client çalışmıyor"},
+		{name: "bozuk dosya", raw: "{", code: 1, output: "bozuk"},
+		{
+			name:   "çalışan client",
+			status: &ClientStatus{PID: os.Getpid(), ClientID: "c1", Recording: true},
+			output: "🔴 Ekran kaydı aktif",
+		},
+		{
+			name:   "json",
+			status: &ClientStatus{PID: os.Getpid(), ClientID: "c1"},
+			args:   []string{"-json"},
+			output: `"client_id": "c1"`,
+		},
+		{
+			// Dosya silinmeden kalmış, process artık yok
+			name:   "ölü PID",
+			status: &ClientStatus{PID: dead},
+			code:   1,
+			output: "Client çalışmıyor (son durum",
+		},
+		{
+			// Process var ama durum güncellenmiyor (askıda veya PID başka process'e geçmiş)
+			name:   "eski durum",
+			status: &ClientStatus{PID: os.Getpid()},
+			age:    3*statusUpdateInterval + time.Second,
+			code:   1,
+			output: "Client çalışmıyor (son durum",
+		},
+		{
+			name:   "sınırda güncel durum",
+			status: &ClientStatus{PID: os.Getpid()},
+			age:    3*statusUpdateInterval - 2*time.Second,
+			output: "Ekran kaydı kapalı",
+		},
+	}
+
+	for _, tt := range tests {
+		os.Remove(file)
+		if tt.status != nil {
+			tt.status.UpdatedAt = time.Now().Add(-tt.age)
+			data, _ := json.MarshalIndent(tt.status, "", "  ")
+			tt.raw = string(data)
+		}
+		if tt.raw != "" {
+			if err := os.WriteFile(file, []byte(tt.raw), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var code int
+		output := captureStdout(t, func() {
+			code = runStatus(append([]string{"-file", file}, tt.args...))
+		})
+		if code != tt.code || !strings.Contains(output, tt.output) {
+			t.Errorf("%s: kod %d, çıktı %q; beklenen kod %d, %q", tt.name, code, output, tt.code, tt.output)
+		}
+	}
+}
+
+func TestReportStatusRetriesNotification(t *testing.T) {
+	notifier := &recordingNotifier{fails: 1}
+	c := &Client{
+		config:   defaultClientConfig(),
+		super:    newSupervisor(),
+		notifier: notifier,
+	}
+	c.config.Status.File = filepath.Join(t.TempDir(), "status.json")
+	c.consentGranted.Store(true)
+
+	// Bildirim gösterilemezse özet gösterilmiş sayılmaz
+	shown := c.reportStatus("")
+	if shown != "" || len(notifier.take()) != 0 {
+		t.Fatalf("başarısız bildirimden sonra gösterilen özet %q", shown)
+	}
+	if _, err := os.Stat(c.config.Status.File); err != nil {
+		t.Fatalf("durum dosyası yazılmadı: %v", err)
+	}
+
+	// Sonraki turda tekrar denenir
+	shown = c.reportStatus(shown)
+	sent := notifier.take()
+	if len(sent) != 1 || sent[0].Body != shown || !sent[0].Persistent {
+		t.Fatalf("tekrar denenen bildirim %+v, gösterilen %q", sent, shown)
+	}
+
+	// Durum değişmedikçe tekrar gösterilmez
+	if c.reportStatus(shown) != shown || len(notifier.take()) != 0 {
+		t.Fatal("değişmeyen durum için bildirim tekrarlandı")
+	}
+
+	c.capturePaused.Store(true)
+	if next := c.reportStatus(shown); next == shown || !strings.Contains(next, "duraklatıldı") {
+		t.Fatalf("durum değişince özet güncellenmedi: %q", next)
+	}
+	if sent := notifier.take(); len(sent) != 1 {
+		t.Fatalf("durum değişince %d bildirim, beklenen 1", len(sent))
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// supervisor client'ın arka plan görevlerini (ekran yakalama, engelleyiciler,
+// komut kanalı, ...) isimle başlatır ve durdurur. Panic olan görev loglanır
+// ve kısa bir beklemeden sonra yeniden başlatılır. Tüm görevler supervisor'ın
+// context'inden türetilir; StopAll hepsini iptal edip bitmelerini bekler.
+type supervisor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	tasks map[string]*supervisedTask
+	wg    sync.WaitGroup
+}
+
+type supervisedTask struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+const taskRestartDelay = 2 * time.Second
+
+func newSupervisor() *supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &supervisor{
+		ctx:    ctx,
+		cancel: cancel,
+		tasks:  make(map[string]*supervisedTask),
+	}
+}
+
+// Start görevi başlatır. Aynı isimde çalışan görev varsa veya supervisor
+// durdurulmuşsa false döner.
+func (s *supervisor) Start(name string, fn func(ctx context.Context)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return false
+	}
+	if _, running := s.tasks[name]; running {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	task := &supervisedTask{cancel: cancel, done: make(chan struct{})}
+	s.tasks[name] = task
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		defer close(task.done)
+		defer func() {
+			s.mu.Lock()
+			if s.tasks[name] == task {
+				delete(s.tasks, name)
+			}
+			s.mu.Unlock()
+		}()
+
+		for {
+			if !runTask(name, ctx, fn) {
+				return // Görev normal şekilde bitti
+			}
+			if !sleepCtx(ctx, taskRestartDelay) {
+				return
+			}
+			log.Printf("🔁 %s yeniden başlatılıyor", name)
+		}
+	}()
+	return true
+}
+
+// runTask görevi çalıştırır; panic olduysa true döner.
+func runTask(name string, ctx context.Context, fn func(ctx context.Context)) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("❌ %s görevi çöktü: %v\n%s", name, r, debug.Stack())
+			panicked = true
+		}
+	}()
+	fn(ctx)
+	return false
+}
+
+// Stop görevi iptal eder ve bitmesini bekler. Görev çalışmıyorsa false döner.
+func (s *supervisor) Stop(name string) bool {
+	s.mu.Lock()
+	task, running := s.tasks[name]
+	s.mu.Unlock()
+
+	if !running {
+		return false
+	}
+	task.cancel()
+	<-task.done
+	return true
+}
+
+func (s *supervisor) Running(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, running := s.tasks[name]
+	return running
+}
+
+// StopAll tüm görevleri iptal eder ve bitmelerini bekler. Sonrasında yeni
+// görev başlatılamaz.
+func (s *supervisor) StopAll() {
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// sleepCtx d kadar bekler; context iptal edilirse false döner.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
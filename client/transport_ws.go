@@ -0,0 +1,455 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	// Sunucunun (app.py) client'lar için ham WebSocket endpoint'i
+	defaultWebSocketPath = "/ws"
+)
+
+// wsTransport kalıcı bir WebSocket bağlantısı yönetir: bağlantı koptuğunda
+// jitter'lı üstel bekleme ile yeniden bağlanır, ping/pong ile bağlantının
+// canlı olduğunu kontrol eder ve sunucudan gelen mesajları okur. Kareler
+// sınırlı bir kuyruğa yazılır, böylece ekran yakalama ağ yüzünden bloklanmaz.
+type wsTransport struct {
+	url      string
+	settings TransportSettings
+	clientID string
+
+	// Sunucudan gelen (register_ack dışındaki) mesajlar
+	onMessage func(msgType string, data []byte)
+	// Kare atıldığında veya bağlantı koptuğunda çağrılır (keyframe istemek için)
+	onFrameLost func()
+	// Bağlantı açılınca (sunucu yetenekleri bilinmeden) ve register_ack
+	// geldiğinde sunucunun bildirdiği yeteneklerle çağrılır
+	onRegistered func(caps serverCapabilities)
+	// Bağlantı koparken kuyrukta kalan veya yazılamayan kareler (diske
+	// biriktirmek için)
+	onFramesDropped func(frames []*ScreenData)
+
+	frames  chan *ScreenData
+	control chan interface{}
+	flush   chan chan struct{}
+	confirm chan wsFrameRequest
+
+	connected   atomic.Bool
+	frameFormat atomic.Value // string
+}
+
+func newWSTransport(wsURL string, settings TransportSettings, clientID string) *wsTransport {
+	t := &wsTransport{
+		url:      wsURL,
+		settings: settings,
+		clientID: clientID,
+		frames:   make(chan *ScreenData, settings.QueueSize),
+		control:  make(chan interface{}, 64),
+		flush:    make(chan chan struct{}),
+		confirm:  make(chan wsFrameRequest),
+	}
+	t.frameFormat.Store(frameFormatJSON)
+	return t
+}
+
+// webSocketURL ayarlardaki URL'i veya sunucu adresinden türetilen URL'i döner.
+// Türetilen URL sunucu ile aynı host ve portu kullanır (http → ws, https → wss).
+func webSocketURL(serverURL string, settings TransportSettings) (string, error) {
+	if settings.WebSocketURL != "" {
+		return settings.WebSocketURL, nil
+	}
+
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("sunucu adresinde host yok: %q", serverURL)
+	}
+
+	scheme := "ws"
+	if u.Scheme == "https" {
+		scheme = "wss"
+	}
+	path := settings.WebSocketPath
+	if path == "" {
+		path = defaultWebSocketPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	// Sunucu bir alt yolda yayınlanıyorsa (reverse proxy) yol korunur
+	ws := url.URL{Scheme: scheme, Host: u.Host, Path: strings.TrimSuffix(u.Path, "/") + path}
+	return ws.String(), nil
+}
+
+func (t *wsTransport) Connected() bool {
+	return t.connected.Load()
+}
+
+func (t *wsTransport) FrameFormat() string {
+	return t.frameFormat.Load().(string)
+}
+
+// SendFrame kareyi kuyruğa ekler, asla bloklamaz. Kuyruk doluysa en eski
+// kare atılır.
+func (t *wsTransport) SendFrame(screenData *ScreenData) {
+	for {
+		select {
+		case t.frames <- screenData:
+			return
+		default:
+		}
+
+		select {
+		case <-t.frames:
+			t.frameLost()
+		default:
+		}
+	}
+}
+
+// wsFrameRequest yazılıp yazılamadığı bildirilen tek bir kare.
+type wsFrameRequest struct {
+	frame *ScreenData
+	done  chan error
+}
+
+// SendFrameWait kareyi canlı kare kuyruğunu atlayarak gönderir ve bağlantıya
+// yazılana kadar bekler (biriktirilmiş kareleri kaybetmeden göndermek için).
+func (t *wsTransport) SendFrameWait(ctx context.Context, screenData *ScreenData) error {
+	if !t.Connected() {
+		return errors.New("WebSocket bağlı değil")
+	}
+
+	timer := time.NewTimer(wsWriteTimeout)
+	defer timer.Stop()
+
+	req := wsFrameRequest{frame: screenData, done: make(chan error, 1)}
+	select {
+	case t.confirm <- req:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return errors.New("WebSocket gönderim zaman aşımı")
+	}
+	// writeLoop isteği aldıysa yazım sonucunu (en fazla yazım süresi kadar) bildirir
+	return <-req.done
+}
+
+// SendJSON kontrol mesajını kuyruğa ekler. Kuyruk doluysa false döner.
+func (t *wsTransport) SendJSON(v interface{}) bool {
+	select {
+	case t.control <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+// Flush kuyruktaki mesajların ve karelerin gönderilmesini bekler (kapatma
+// sırasında kullanılır). Bağlantı yoksa veya süre dolarsa false döner.
+func (t *wsTransport) Flush(timeout time.Duration) bool {
+	if !t.Connected() {
+		return len(t.frames) == 0 && len(t.control) == 0
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	done := make(chan struct{})
+	select {
+	case t.flush <- done:
+	case <-timer.C:
+		return false
+	}
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (t *wsTransport) frameLost() {
+	if t.onFrameLost != nil {
+		t.onFrameLost()
+	}
+}
+
+// framesDropped gönderilemeyen kareleri onFramesDropped'a iletir.
+func (t *wsTransport) framesDropped(frames []*ScreenData) {
+	if len(frames) > 0 && t.onFramesDropped != nil {
+		t.onFramesDropped(frames)
+	}
+}
+
+func (t *wsTransport) registered(caps serverCapabilities) {
+	t.frameFormat.Store(caps.frameFormat())
+	if t.onRegistered != nil {
+		t.onRegistered(caps)
+	}
+}
+
+// Run context iptal edilene kadar bağlantıyı açık tutar.
+func (t *wsTransport) Run(ctx context.Context) {
+	attempt := 0
+	for {
+		started := time.Now()
+		err := t.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Bir süre ayakta kalmış bağlantılardan sonra beklemeyi sıfırla
+		if time.Since(started) > time.Duration(t.settings.ReconnectMaxSeconds)*time.Second {
+			attempt = 0
+		}
+		delay := t.backoff(attempt)
+		attempt++
+
+		log.Printf("⚠️ WebSocket bağlantısı kesildi: %v (%s sonra tekrar denenecek)", err, delay.Round(100*time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff üstel bekleme süresini "equal jitter" ile hesaplar.
+func (t *wsTransport) backoff(attempt int) time.Duration {
+	minDelay := time.Duration(t.settings.ReconnectMinSeconds) * time.Second
+	maxDelay := time.Duration(t.settings.ReconnectMaxSeconds) * time.Second
+
+	delay := minDelay
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func (t *wsTransport) session(ctx context.Context) error {
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+	conn, _, err := dialer.DialContext(ctx, t.url, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Client kaydını gönder, desteklenen kare formatlarını bildir. Sunucu
+	// register_ack ile seçtiği formatı bildirene kadar JSON tam kare kullanılır.
+	t.registered(serverCapabilities{})
+	registerMsg := map[string]interface{}{
+		"type":          "client_register",
+		"client_id":     t.clientID,
+		"frame_formats": []string{frameFormatBinary, frameFormatJSON},
+	}
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err := conn.WriteJSON(registerMsg); err != nil {
+		return fmt.Errorf("client kayıt hatası: %v", err)
+	}
+
+	log.Printf("✅ WebSocket sunucuya başarıyla bağlandı: %s", t.url)
+	t.connected.Store(true)
+	defer func() {
+		t.connected.Store(false)
+		// Kuyrukta kalan kareler yeni bağlantıda geçersiz (delta'lar eski
+		// keyframe'e göre), canlı gönderilmez ama diske biriktirilebilir
+		t.framesDropped(t.drainFrames())
+		t.frameLost()
+	}()
+
+	// Önceki bağlantıdan sonra kuyruğa girmiş kareler
+	t.framesDropped(t.drainFrames())
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errc := make(chan error, 2)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		errc <- t.readLoop(conn)
+	}()
+	go func() {
+		defer wg.Done()
+		errc <- t.writeLoop(sessionCtx, conn)
+	}()
+
+	err = <-errc
+	cancel()
+	conn.Close() // readLoop'u uyandır
+	wg.Wait()
+	return err
+}
+
+func (t *wsTransport) drainFrames() []*ScreenData {
+	var frames []*ScreenData
+	for {
+		select {
+		case screenData := <-t.frames:
+			frames = append(frames, screenData)
+		default:
+			return frames
+		}
+	}
+}
+
+func (t *wsTransport) readLoop(conn *websocket.Conn) error {
+	pongWait := time.Duration(t.settings.PongTimeoutSeconds) * time.Second
+	conn.SetReadLimit(1 << 20)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		msgType, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		// Her mesaj da bağlantının canlı olduğunu gösterir
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		if msgType != websocket.TextMessage {
+			continue
+		}
+
+		var envelope struct {
+			Type        string `json:"type"`
+			FrameFormat string `json:"frame_format"`
+			DeltaFrames bool   `json:"delta_frames"`
+		}
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			log.Printf("⚠️ Geçersiz sunucu mesajı: %v", err)
+			continue
+		}
+
+		if envelope.Type == "register_ack" {
+			caps := serverCapabilities{DeltaFrames: envelope.DeltaFrames}
+			if envelope.FrameFormat != "" {
+				caps.FrameFormats = []string{envelope.FrameFormat}
+			}
+			t.registered(caps)
+			log.Printf("📡 WebSocket kare formatı: %s, delta: %v", caps.frameFormat(), caps.DeltaFrames)
+			continue
+		}
+
+		if t.onMessage != nil {
+			t.onMessage(envelope.Type, data)
+		}
+	}
+}
+
+func (t *wsTransport) writeLoop(ctx context.Context, conn *websocket.Conn) error {
+	ping := time.NewTicker(time.Duration(t.settings.PingIntervalSeconds) * time.Second)
+	defer ping.Stop()
+
+	for {
+		// Kontrol mesajları karelerden önce gönderilir
+		select {
+		case msg := <-t.control:
+			if err := t.writeJSON(conn, msg); err != nil {
+				return err
+			}
+			continue
+		default:
+		}
+
+		select {
+		case <-ctx.Done():
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(time.Second))
+			return ctx.Err()
+
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return err
+			}
+
+		case msg := <-t.control:
+			if err := t.writeJSON(conn, msg); err != nil {
+				return err
+			}
+
+		case screenData := <-t.frames:
+			if err := t.writeFrame(conn, screenData); err != nil {
+				t.framesDropped([]*ScreenData{screenData})
+				t.frameLost()
+				return err
+			}
+
+		case req := <-t.confirm:
+			err := t.writeFrame(conn, req.frame)
+			req.done <- err
+			if err != nil {
+				return err
+			}
+
+		case done := <-t.flush:
+			if err := t.writePending(conn); err != nil {
+				return err
+			}
+			close(done)
+		}
+	}
+}
+
+// writePending kuyrukta bekleyen her şeyi bloklamadan gönderir.
+func (t *wsTransport) writePending(conn *websocket.Conn) error {
+	for {
+		select {
+		case msg := <-t.control:
+			if err := t.writeJSON(conn, msg); err != nil {
+				return err
+			}
+		case screenData := <-t.frames:
+			if err := t.writeFrame(conn, screenData); err != nil {
+				t.framesDropped([]*ScreenData{screenData})
+				t.frameLost()
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (t *wsTransport) writeJSON(conn *websocket.Conn, v interface{}) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(v)
+}
+
+func (t *wsTransport) writeFrame(conn *websocket.Conn, screenData *ScreenData) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if t.FrameFormat() == frameFormatBinary {
+		payload, err := marshalBinaryFrame(screenData)
+		if err != nil {
+			// Bozuk kare bağlantıyı koparmamalı
+			log.Printf("⚠️ Kare hazırlanamadı: %v", err)
+			return nil
+		}
+		return conn.WriteMessage(websocket.BinaryMessage, payload)
+	}
+	return conn.WriteJSON(jsonScreenData(screenData))
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestWebSocketURL(t *testing.T) {
+	for _, tc := range []struct {
+		server   string
+		settings TransportSettings
+		want     string
+	}{
+		{"http://127.0.0.1:5000", TransportSettings{}, "ws://127.0.0.1:5000/ws"},
+		{"https://screen.example.com", TransportSettings{}, "wss://screen.example.com/ws"},
+		{"http://localhost:8080", TransportSettings{WebSocketPath: "stream"}, "ws://localhost:8080/stream"},
+		{"https://example.com/recorder/", TransportSettings{}, "wss://example.com/recorder/ws"},
+		{"http://[::1]:5000", TransportSettings{}, "ws://[::1]:5000/ws"},
+		{"http://ignored", TransportSettings{WebSocketURL: "wss://other:8765/ws"}, "wss://other:8765/ws"},
+	} {
+		got, err := webSocketURL(tc.server, tc.settings)
+		if err != nil {
+			t.Errorf("%s: %v", tc.server, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: %s, beklenen %s", tc.server, got, tc.want)
+		}
+	}
+
+	if _, err := webSocketURL("127.0.0.1:5000", TransportSettings{}); err == nil {
+		t.Error("şemasız adres için hata bekleniyordu")
+	}
+}
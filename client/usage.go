@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Günlük kotası olan uygulamaların çalışma süresi checkAndBlockApps
+// turlarında biriktirilir ve yeniden başlatmalarda kaybolmaması için diske
+// yazılır. Sayaçlar her gün quota_reset_time'da (yerel saat) sıfırlanır.
+// Disk her turda değil en fazla usageSaveInterval'da bir (ve engelleyici
+// dururken) yazılır; çökmede en fazla bu kadar kullanım kaybolur.
+
+const (
+	appUsageFile      = "app_usage.json"
+	usageSaveInterval = time.Minute
+)
+
+type appUsage struct {
+	mu   sync.Mutex
+	path string
+
+	PeriodStart time.Time          `json:"period_start"`
+	Seconds     map[string]float64 `json:"seconds"` // uygulama adı -> saniye
+
+	lastSeen  map[string]time.Time // son turda çalışırken görüldüğü an
+	dirty     bool
+	lastSaved time.Time
+}
+
+func loadAppUsage(path string) *appUsage {
+	u := &appUsage{
+		path:     path,
+		Seconds:  make(map[string]float64),
+		lastSeen: make(map[string]time.Time),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Kullanım kaydı okunamadı: %v", err)
+		}
+		return u
+	}
+	if err := json.Unmarshal(data, u); err != nil {
+		log.Printf("⚠️ Kullanım kaydı bozuk, sıfırdan başlanıyor: %v", err)
+		u.PeriodStart = time.Time{}
+		u.Seconds = make(map[string]float64)
+	}
+	if u.Seconds == nil {
+		u.Seconds = make(map[string]float64)
+	}
+	return u
+}
+
+// rollover yeni bir kota dönemine girildiyse sayaçları sıfırlar.
+func (u *appUsage) rollover(now time.Time, resetMinute int) bool {
+	start := quotaPeriodStart(now, resetMinute)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.PeriodStart.Equal(start) {
+		return false
+	}
+	hadUsage := len(u.Seconds) > 0
+	u.PeriodStart = start
+	u.Seconds = make(map[string]float64)
+	u.dirty = true
+	return hadUsage
+}
+
+// observe uygulamanın son turdan beri çalıştığı süreyi ekler ve eklemeden
+// önceki ve sonraki toplamı döner. Turlar arasında maxGap'ten uzun boşluk
+// varsa (client kapalıydı, sistem uykudaydı) o aralık sayılmaz.
+func (u *appUsage) observe(app string, now time.Time, maxGap time.Duration) (before, after time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	before = time.Duration(u.Seconds[app] * float64(time.Second))
+	if last, ok := u.lastSeen[app]; ok {
+		if elapsed := now.Sub(last); elapsed > 0 && elapsed <= maxGap {
+			u.Seconds[app] += elapsed.Seconds()
+			u.dirty = true
+		}
+	}
+	u.lastSeen[app] = now
+	return before, time.Duration(u.Seconds[app] * float64(time.Second))
+}
+
+// forget uygulama çalışmıyorsa bir sonraki görülüşte aradaki süre sayılmasın diye çağrılır.
+func (u *appUsage) forget(app string) {
+	u.mu.Lock()
+	delete(u.lastSeen, app)
+	u.mu.Unlock()
+}
+
+// saveIfDue son yazımdan beri usageSaveInterval geçtiyse değişiklikleri yazar.
+func (u *appUsage) saveIfDue(now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	// Saat geri alındıysa beklemeden yazılır
+	if elapsed := now.Sub(u.lastSaved); elapsed >= 0 && elapsed < usageSaveInterval {
+		return
+	}
+	if u.saveLocked() {
+		u.lastSaved = now
+	}
+}
+
+// save değişiklikleri hemen yazar (engelleyici dururken).
+func (u *appUsage) save() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.saveLocked()
+}
+
+// saveLocked bekleyen değişiklik yazıldıysa true döner.
+func (u *appUsage) saveLocked() bool {
+	if !u.dirty {
+		return false
+	}
+
+	data, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return false
+	}
+	if err := writeFileAtomic(u.path, data, 0600); err != nil {
+		log.Printf("⚠️ Kullanım kaydı yazılamadı: %v", err)
+		return false
+	}
+	u.dirty = false
+	return true
+}
+
+// quotaPeriodStart now'dan önceki en son sıfırlama anını döner.
+func quotaPeriodStart(now time.Time, resetMinute int) time.Time {
+	year, month, day := now.Date()
+	start := time.Date(year, month, day, resetMinute/60, resetMinute%60, 0, 0, now.Location())
+	if now.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// quotaResetMinute quota_reset_time ayarını gece yarısından itibaren dakikaya çevirir.
+func (cfg *AppBlockerConfig) quotaResetMinute() int {
+	if cfg.Settings.QuotaResetTime == "" {
+		return 0
+	}
+	minute, err := parseClock(cfg.Settings.QuotaResetTime)
+	if err != nil || minute >= 24*60 {
+		return 0
+	}
+	return minute
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuotaPeriodStart(t *testing.T) {
+	istanbul := time.FixedZone("TRT", 3*60*60)
+	tests := []struct {
+		now         string
+		resetMinute int
+		want        string
+	}{
+		{"2024-03-10 15:00", 0, "2024-03-10 00:00"},
+		{"2024-03-10 00:00", 0, "2024-03-10 00:00"},
+		{"2024-03-10 04:29", 4*60 + 30, "2024-03-09 04:30"},
+		{"2024-03-10 04:30", 4*60 + 30, "2024-03-10 04:30"},
+		{"2024-03-01 02:00", 23*60 + 59, "2024-02-29 23:59"},
+		{"2024-01-01 01:00", 6 * 60, "2023-12-31 06:00"},
+	}
+	for _, tt := range tests {
+		now := scheduleTime(t, istanbul, tt.now)
+		want := scheduleTime(t, istanbul, tt.want)
+		if got := quotaPeriodStart(now, tt.resetMinute); !got.Equal(want) || got.Location() != istanbul {
+			t.Errorf("quotaPeriodStart(%s, %d) = %s, beklenen %s", tt.now, tt.resetMinute, got, want)
+		}
+	}
+}
+
+func TestQuotaResetMinute(t *testing.T) {
+	for value, want := range map[string]int{"": 0, "04:30": 270, "23:59": 1439, "24:00": 0, "bogus": 0} {
+		cfg := &AppBlockerConfig{}
+		cfg.Settings.QuotaResetTime = value
+		if got := cfg.quotaResetMinute(); got != want {
+			t.Errorf("quota_reset_time %q = %d, beklenen %d", value, got, want)
+		}
+	}
+}
+
+func TestAppUsageObserve(t *testing.T) {
+	u := loadAppUsage(filepath.Join(t.TempDir(), "usage.json"))
+	start := scheduleTime(t, time.UTC, "2024-03-10 10:00")
+	maxGap := 10 * time.Second
+
+	steps := []struct {
+		name   string
+		at     time.Duration // start'tan itibaren
+		forget bool          // bu turdan önce uygulama kapalıydı
+		before time.Duration
+		after  time.Duration
+	}{
+		{"ilk görülüş sayılmaz", 0, false, 0, 0},
+		{"turlar arası süre eklenir", 5 * time.Second, false, 0, 5 * time.Second},
+		{"maxGap sınırda sayılır", 15 * time.Second, false, 5 * time.Second, 15 * time.Second},
+		{"maxGap'ten uzun boşluk sayılmaz", 40 * time.Second, false, 15 * time.Second, 15 * time.Second},
+		{"boşluktan sonra sayım sürer", 45 * time.Second, false, 15 * time.Second, 20 * time.Second},
+		{"geri giden saat sayılmaz", 44 * time.Second, false, 20 * time.Second, 20 * time.Second},
+		{"kapalıyken geçen süre sayılmaz", 49 * time.Second, true, 20 * time.Second, 20 * time.Second},
+		{"tekrar açıldıktan sonra sayılır", 52 * time.Second, false, 20 * time.Second, 23 * time.Second},
+	}
+	for _, step := range steps {
+		if step.forget {
+			u.forget("game")
+		}
+		before, after := u.observe("game", start.Add(step.at), maxGap)
+		if before != step.before || after != step.after {
+			t.Errorf("%s: observe = (%s, %s), beklenen (%s, %s)", step.name, before, after, step.before, step.after)
+		}
+	}
+
+	// Uygulamalar ayrı sayılır
+	if _, after := u.observe("other", start.Add(time.Minute), maxGap); after != 0 {
+		t.Errorf("başka uygulamanın süresi %s, beklenen 0", after)
+	}
+}
+
+func TestAppUsageRollover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	u := loadAppUsage(path)
+	resetMinute := 6 * 60
+
+	day1 := scheduleTime(t, time.UTC, "2024-03-10 07:00")
+	if u.rollover(day1, resetMinute) {
+		t.Error("ilk dönem açılırken sıfırlanacak kullanım yoktu")
+	}
+	u.observe("game", day1, time.Minute)
+	u.observe("game", day1.Add(30*time.Second), time.Minute)
+
+	// Aynı dönem: sayaçlar korunur
+	if u.rollover(scheduleTime(t, time.UTC, "2024-03-11 05:59"), resetMinute) {
+		t.Error("sıfırlama saatinden önce dönem değişmemeli")
+	}
+	if _, after := u.observe("game", day1.Add(time.Minute), time.Minute); after != time.Minute {
+		t.Errorf("kullanım %s, beklenen 1m", after)
+	}
+
+	// Kayıt diske yazılıp tekrar okunduğunda dönem ve sayaçlar korunur
+	u.save()
+	reloaded := loadAppUsage(path)
+	if !reloaded.PeriodStart.Equal(quotaPeriodStart(day1, resetMinute)) || reloaded.Seconds["game"] != 60 {
+		t.Errorf("okunan kayıt: dönem %s, sayaç %v", reloaded.PeriodStart, reloaded.Seconds)
+	}
+
+	if !reloaded.rollover(scheduleTime(t, time.UTC, "2024-03-11 06:00"), resetMinute) {
+		t.Error("sıfırlama saatinde kullanım sıfırlanmalı")
+	}
+	if len(reloaded.Seconds) != 0 {
+		t.Errorf("sıfırlamadan sonra sayaçlar %v", reloaded.Seconds)
+	}
+	if reloaded.rollover(scheduleTime(t, time.UTC, "2024-03-11 12:00"), resetMinute) {
+		t.Error("aynı dönemde tekrar sıfırlanmamalı")
+	}
+}
+
+func TestAppUsageSaveThrottled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	u := loadAppUsage(path)
+	start := scheduleTime(t, time.UTC, "2024-03-10 10:00")
+
+	tests := []struct {
+		name  string
+		at    time.Duration // start'tan itibaren
+		saved bool
+	}{
+		{"ilk değişiklik yazılır", 0, true},
+		{"aralık dolmadan yazılmaz", 5 * time.Second, false},
+		{"aralık dolmadan yazılmaz", 59 * time.Second, false},
+		{"aralık dolunca yazılır", time.Minute, true},
+		{"aralık dolmadan yazılmaz", time.Minute + 5*time.Second, false},
+		{"saat geri alınınca yazılır", -time.Hour, true},
+	}
+	for _, tt := range tests {
+		os.Remove(path)
+		u.mu.Lock()
+		u.dirty = true
+		u.mu.Unlock()
+
+		u.saveIfDue(start.Add(tt.at))
+		_, err := os.Stat(path)
+		if saved := err == nil; saved != tt.saved {
+			t.Errorf("%s (%s): yazıldı = %v, beklenen %v", tt.name, tt.at, saved, tt.saved)
+		}
+	}
+
+	// Engelleyici dururken bekleyen değişiklik hemen yazılır
+	os.Remove(path)
+	u.mu.Lock()
+	u.dirty = true
+	u.mu.Unlock()
+	u.save()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("save bekleyen değişikliği yazmadı: %v", err)
+	}
+}
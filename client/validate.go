@@ -0,0 +1,488 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Engelleme config'lerinin doğrulanması. Her hata JSON yolu ile raporlanır
+// (örn. "$.blocked_applications[2].processes"). Bilinmeyen alanlar yeni
+// sürümlerle uyumluluk için sadece uyarıdır; diğer hatalar config'i geçersiz kılar.
+
+var blockingMethods = []string{"hosts", "browser_check", "dns_proxy"}
+
+type ValidationError struct {
+	Path    string
+	Message string
+	Warning bool
+}
+
+func (e ValidationError) String() string {
+	return e.Path + ": " + e.Message
+}
+
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	var parts []string
+	for _, e := range errs {
+		if !e.Warning {
+			parts = append(parts, e.String())
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (errs ValidationErrors) HasErrors() bool {
+	for _, e := range errs {
+		if !e.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// Err hata varsa listeyi error olarak, yoksa nil döner.
+func (errs ValidationErrors) Err() error {
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (errs *ValidationErrors) add(path, format string, args ...interface{}) {
+	*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (errs *ValidationErrors) warn(path, format string, args ...interface{}) {
+	*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// parseAppBlockerConfig config'i çözer ve doğrular. Hata varsa config nil döner.
+func parseAppBlockerConfig(data []byte) (*AppBlockerConfig, ValidationErrors) {
+	var cfg *AppBlockerConfig
+	errs := decodeConfig(data, &cfg)
+	if cfg == nil {
+		if !errs.HasErrors() {
+			errs.add("$", "config boş")
+		}
+		return nil, errs
+	}
+
+	errs = mergeValidation(errs, cfg.Validate())
+	if errs.HasErrors() {
+		return nil, errs
+	}
+	if cfg.Settings.KillGraceSeconds == 0 {
+		cfg.Settings.KillGraceSeconds = defaultKillGraceSeconds
+	}
+	cfg.compileMatchers()
+	cfg.compileSchedules()
+	return cfg, errs
+}
+
+func parseWebsiteBlockerConfig(data []byte) (*WebsiteBlockerConfig, ValidationErrors) {
+	var cfg *WebsiteBlockerConfig
+	errs := decodeConfig(data, &cfg)
+	if cfg == nil {
+		if !errs.HasErrors() {
+			errs.add("$", "config boş")
+		}
+		return nil, errs
+	}
+
+	errs = mergeValidation(errs, cfg.Validate())
+	if errs.HasErrors() {
+		return nil, errs
+	}
+	return cfg, errs
+}
+
+func (cfg *AppBlockerConfig) Validate() ValidationErrors {
+	var errs ValidationErrors
+
+	if cfg.Settings.CheckIntervalSeconds <= 0 {
+		errs.add("$.settings.check_interval_seconds", "pozitif olmalı (şu an %d)", cfg.Settings.CheckIntervalSeconds)
+	}
+	if cfg.Settings.KillGraceSeconds < 0 {
+		errs.add("$.settings.kill_grace_seconds", "negatif olamaz (şu an %d)", cfg.Settings.KillGraceSeconds)
+	}
+	if cfg.Settings.QuotaResetTime != "" {
+		if minute, err := parseClock(cfg.Settings.QuotaResetTime); err != nil {
+			errs.add("$.settings.quota_reset_time", "%v", err)
+		} else if minute >= 24*60 {
+			errs.add("$.settings.quota_reset_time", "24:00 olamaz")
+		}
+	}
+	if !cfg.Settings.AutoKill && cfg.Settings.MaxWarnings <= 0 {
+		errs.add("$.settings.max_warnings", "auto_kill kapalıyken pozitif olmalı (şu an %d)", cfg.Settings.MaxWarnings)
+	}
+
+	names := make(map[string]int)
+	for i, app := range cfg.BlockedApplications {
+		path := fmt.Sprintf("$.blocked_applications[%d]", i)
+
+		if strings.TrimSpace(app.Name) == "" {
+			errs.add(path+".name", "zorunlu alan")
+		} else if prev, dup := names[app.Name]; dup {
+			errs.warn(path+".name", "%q adı blocked_applications[%d] ile aynı", app.Name, prev)
+		} else {
+			names[app.Name] = i
+		}
+
+		if len(app.Processes) == 0 && len(app.Match) == 0 {
+			errs.add(path+".processes", "en az bir process (veya match kuralı) gerekli")
+		}
+		for j, process := range app.Processes {
+			if strings.TrimSpace(process) == "" {
+				errs.add(fmt.Sprintf("%s.processes[%d]", path, j), "boş process adı")
+			} else if msg := processEntryWarning(process); msg != "" {
+				errs.warn(fmt.Sprintf("%s.processes[%d]", path, j), "%s", msg)
+			}
+		}
+		for j, rule := range app.Match {
+			if err := rule.validate(); err != nil {
+				errs.add(fmt.Sprintf("%s.match[%d]", path, j), "%v", err)
+			}
+		}
+		if app.DailyQuotaMinutes < 0 {
+			errs.add(path+".daily_quota_minutes", "negatif olamaz (şu an %d)", app.DailyQuotaMinutes)
+		} else if app.DailyQuotaMinutes >= 24*60 {
+			errs.warn(path+".daily_quota_minutes", "%d dakika bir günden uzun; kota hiç dolmaz", app.DailyQuotaMinutes)
+		}
+		if app.Schedule != nil {
+			app.Schedule.validate(path+".schedule", &errs)
+		}
+	}
+
+	return errs
+}
+
+// processEntryWarning process adı olarak hiç (veya sadece bazı platformlarda)
+// eşleşmeyecek girdiler için açıklama döner.
+func processEntryWarning(process string) string {
+	trimmed := strings.TrimRight(process, `/\`)
+	if strings.HasSuffix(strings.ToLower(trimmed), ".app") {
+		name := trimmed[strings.LastIndexAny(trimmed, `/\`)+1 : len(trimmed)-len(".app")]
+		return fmt.Sprintf("macOS uygulama paketi hiçbir process ile eşleşmez; process adını kullanın (ör. %q)", name)
+	}
+	if strings.ContainsAny(process, `/\`) {
+		return "yol girdisi sadece exe yolu birebir aynıysa eşleşir (Windows'ta exe yolu okunmaz); process adını kullanın"
+	}
+	return ""
+}
+
+func (cfg *WebsiteBlockerConfig) Validate() ValidationErrors {
+	var errs ValidationErrors
+	settings := cfg.Settings
+
+	if settings.CheckIntervalSeconds <= 0 {
+		errs.add("$.settings.check_interval_seconds", "pozitif olmalı (şu an %d)", settings.CheckIntervalSeconds)
+	}
+
+	validMethod := false
+	for _, m := range blockingMethods {
+		if settings.BlockingMethod == m {
+			validMethod = true
+		}
+	}
+	if !validMethod {
+		errs.add("$.settings.blocking_method", "%q geçersiz, beklenen: %s",
+			settings.BlockingMethod, strings.Join(blockingMethods, ", "))
+	}
+
+	if settings.BackupHosts {
+		errs.warn("$.settings.backup_hosts", "artık kullanılmıyor; kapanışta hosts dosyasından sadece client'ın bölümü kaldırılır")
+	}
+
+	if settings.RedirectTo == "" {
+		if settings.BlockingMethod == "hosts" {
+			errs.add("$.settings.redirect_to", "hosts yöntemi için zorunlu")
+		}
+	} else if net.ParseIP(settings.RedirectTo) == nil {
+		errs.add("$.settings.redirect_to", "%q geçerli bir IP adresi değil", settings.RedirectTo)
+	}
+
+	if settings.RedirectToIPv6 != "" {
+		if ip := net.ParseIP(settings.RedirectToIPv6); ip == nil || ip.To4() != nil {
+			errs.add("$.settings.redirect_to_ipv6", "%q geçerli bir IPv6 adresi değil", settings.RedirectToIPv6)
+		}
+	}
+	if settings.BlockingMethod == "dns_proxy" {
+		listen := settings.DNSListen
+		if listen == "" {
+			listen = defaultDNSListen
+		} else if _, _, err := net.SplitHostPort(listen); err != nil {
+			errs.add("$.settings.dns_listen", "%q geçerli bir adres değil (ör. 127.0.0.1:53)", listen)
+		}
+		for j, u := range settings.DNSUpstreams {
+			path := fmt.Sprintf("$.settings.dns_upstreams[%d]", j)
+			if addr, err := normalizeUpstream(u); err != nil {
+				errs.add(path, "%q geçerli bir DNS sunucusu değil", u)
+			} else if addr == listen {
+				errs.add(path, "%q dns_listen ile aynı, sorgular döngüye girer", u)
+			}
+		}
+	}
+	if settings.BlockPagePort < 0 || settings.BlockPagePort > 65535 {
+		errs.add("$.settings.block_page_port", "%d geçerli bir port değil", settings.BlockPagePort)
+	}
+	if settings.BlockPage {
+		switch {
+		case settings.BlockingMethod == "browser_check":
+			errs.warn("$.settings.block_page", "browser_check yönteminde siteler yönlendirilmez, sayfa gösterilmez")
+		case settings.RedirectTo == "":
+			errs.warn("$.settings.block_page", "redirect_to olmadan sayfa gösterilmez")
+		default:
+			if ip := net.ParseIP(settings.RedirectTo); ip != nil && !ip.IsLoopback() && !ip.IsUnspecified() {
+				errs.warn("$.settings.block_page", "redirect_to (%s) yerel bir adres değil, sayfa client tarafından sunulmaz", settings.RedirectTo)
+			}
+		}
+	}
+	for j, label := range settings.SubdomainPrefixes {
+		if err := validLabel(label); err != nil {
+			errs.add(fmt.Sprintf("$.settings.subdomain_prefixes[%d]", j), "%q geçerli bir alt alan adı değil", label)
+		}
+	}
+	for j, label := range settings.WildcardSubdomains {
+		if err := validLabel(label); err != nil {
+			errs.add(fmt.Sprintf("$.settings.wildcard_subdomains[%d]", j), "%q geçerli bir alt alan adı değil", label)
+		}
+	}
+
+	for i, site := range cfg.BlockedWebsites {
+		path := fmt.Sprintf("$.blocked_websites[%d]", i)
+
+		if strings.TrimSpace(site.Name) == "" {
+			errs.add(path+".name", "zorunlu alan")
+		}
+		if len(site.URLs) == 0 {
+			errs.add(path+".urls", "en az bir URL gerekli")
+		}
+		for j, u := range site.URLs {
+			if strings.ContainsAny(strings.TrimSpace(u), " \t\r\n") {
+				errs.add(fmt.Sprintf("%s.urls[%d]", path, j), "%q geçerli bir adres değil", u)
+			} else if _, wildcard, err := normalizeHost(u); err != nil {
+				errs.add(fmt.Sprintf("%s.urls[%d]", path, j), "%v", err)
+			} else if wildcard && settings.BlockingMethod == "hosts" {
+				errs.warn(fmt.Sprintf("%s.urls[%d]", path, j),
+					"hosts yönteminde joker desen çözülmez; sadece alan adı ve wildcard_subdomains engellenir (tüm alt alan adları için dns_proxy)")
+			}
+		}
+		for j, sub := range site.Subdomains {
+			if err := validLabel(sub); err != nil {
+				errs.add(fmt.Sprintf("%s.subdomains[%d]", path, j), "%q geçerli bir alt alan adı değil", sub)
+			}
+		}
+	}
+
+	return errs
+}
+
+// decodeConfig JSON'u v'ye çözer. Sözdizimi hatası konumuyla, tüm tip
+// uyumsuzlukları JSON yollarıyla, bilinmeyen alanlar uyarı olarak raporlanır.
+func decodeConfig(data []byte, v interface{}) ValidationErrors {
+	var errs ValidationErrors
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := offsetToLineCol(data, syntaxErr.Offset)
+			errs.add("$", "JSON sözdizimi hatası (satır %d, sütun %d): %v", line, col, syntaxErr)
+		} else {
+			errs.add("$", "%v", err)
+		}
+		return errs
+	}
+
+	// json.Unmarshal sadece ilk tip hatasını döndürdüğü için tüm alanlar
+	// burada kontrol edilir
+	checkJSONFields(raw, reflect.TypeOf(v), "$", &errs)
+
+	if err := json.Unmarshal(data, v); err != nil && !errs.HasErrors() {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			errs.add("$."+typeErr.Field, "%s bekleniyordu, %s verildi", typeErr.Type, typeErr.Value)
+		} else {
+			errs.add("$", "%v", err)
+		}
+	}
+	return errs
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// checkJSONFields JSON değerini hedef tiple karşılaştırır: karşılığı olmayan
+// alanları ve tip uyumsuzluklarını bulur.
+func checkJSONFields(raw interface{}, t reflect.Type, path string, errs *ValidationErrors) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// null her tipe atanabilir; kendi çözücüsü olan tipler (örn. time.Time) kontrol edilmez
+	if raw == nil || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	mismatch := func(expected string) {
+		errs.add(path, "%s bekleniyordu, %s verildi", expected, jsonKind(raw))
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if _, ok := raw.(bool); !ok {
+			mismatch("boolean")
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := raw.(float64)
+		if !ok || n != float64(int64(n)) {
+			mismatch("tam sayı")
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if _, ok := raw.(float64); !ok {
+			mismatch("sayı")
+		}
+
+	case reflect.String:
+		if _, ok := raw.(string); !ok {
+			mismatch("string")
+		}
+
+	case reflect.Struct:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			mismatch("nesne")
+			return
+		}
+		// encoding/json alan adlarını büyük/küçük harf duyarsız eşleştirir
+		fields := make(map[string]reflect.StructField)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fields[strings.ToLower(name)] = field
+		}
+
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			field, known := fields[strings.ToLower(key)]
+			if !known {
+				errs.warn(path+"."+key, "bilinmeyen alan (yazım hatası olabilir)")
+				continue
+			}
+			checkJSONFields(obj[key], field.Type, path+"."+key, errs)
+		}
+
+	case reflect.Slice, reflect.Array:
+		arr, ok := raw.([]interface{})
+		if !ok {
+			mismatch("dizi")
+			return
+		}
+		for i, elem := range arr {
+			checkJSONFields(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	case reflect.Map:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			mismatch("nesne")
+			return
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			checkJSONFields(obj[key], t.Elem(), path+"."+key, errs)
+		}
+	}
+}
+
+func jsonKind(raw interface{}) string {
+	switch v := raw.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "tam sayı"
+		}
+		return "sayı"
+	case string:
+		return "string"
+	case []interface{}:
+		return "dizi"
+	case map[string]interface{}:
+		return "nesne"
+	default:
+		return "null"
+	}
+}
+
+// mergeValidation anlamsal hataları ekler; tip hatası raporlanmış yollar ve
+// altları için (sıfır değerden kaynaklanan) tekrar hataları atlanır.
+func mergeValidation(errs, semantic ValidationErrors) ValidationErrors {
+	var reported []string
+	for _, e := range errs {
+		if !e.Warning {
+			reported = append(reported, e.Path)
+		}
+	}
+
+	covered := func(path string) bool {
+		for _, p := range reported {
+			if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, e := range semantic {
+		if !covered(e.Path) {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+func offsetToLineCol(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+func logValidation(source string, errs ValidationErrors) {
+	for _, e := range errs {
+		if e.Warning {
+			log.Printf("⚠️ %s: %s", source, e)
+		} else {
+			log.Printf("❌ %s: %s", source, e)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// validationPaths hata ve uyarıların JSON yollarını sıralı olarak ayırır.
+func validationPaths(errs ValidationErrors) (errors, warnings []string) {
+	for _, e := range errs {
+		if e.Warning {
+			warnings = append(warnings, e.Path)
+		} else {
+			errors = append(errors, e.Path)
+		}
+	}
+	sort.Strings(errors)
+	sort.Strings(warnings)
+	return errors, warnings
+}
+
+func assertValidation(t *testing.T, name string, errs ValidationErrors, wantErrors, wantWarnings []string) {
+	t.Helper()
+	gotErrors, gotWarnings := validationPaths(errs)
+	sort.Strings(wantErrors)
+	sort.Strings(wantWarnings)
+	if !reflect.DeepEqual(gotErrors, wantErrors) {
+		t.Errorf("%s: hatalar %v, beklenen %v (%v)", name, gotErrors, wantErrors, errs)
+	}
+	if !reflect.DeepEqual(gotWarnings, wantWarnings) {
+		t.Errorf("%s: uyarılar %v, beklenen %v (%v)", name, gotWarnings, wantWarnings, errs)
+	}
+}
+
+const testAppSettings = `"settings": {"check_interval_seconds": 5, "max_warnings": 3, "app_blocker_enabled": true}`
+
+func TestParseAppBlockerConfigPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name: "geçerli",
+			json: `{"blocked_applications": [{"name": "Steam", "processes": ["steam"]}], ` + testAppSettings + `}`,
+		},
+		{
+			name:   "sözdizimi hatası",
+			json:   `{"blocked_applications": [}`,
+			errors: []string{"$"},
+		},
+		{
+			name:   "boş config",
+			json:   `null`,
+			errors: []string{"$"},
+		},
+		{
+			name: "tip hataları yollarıyla",
+			json: `{"blocked_applications": [
+				{"name": "A", "processes": "a"},
+				{"name": 5, "processes": ["b", 7]}
+			], "settings": {"check_interval_seconds": "5", "max_warnings": 3}}`,
+			errors: []string{
+				"$.blocked_applications[0].processes",
+				"$.blocked_applications[1].name",
+				"$.blocked_applications[1].processes[1]",
+				"$.settings.check_interval_seconds",
+			},
+		},
+		{
+			name: "anlamsal hatalar",
+			json: `{"blocked_applications": [
+				{"name": "", "processes": []},
+				{"name": "B", "processes": ["  "], "daily_quota_minutes": -1},
+				{"name": "C", "match": [{"glob": "["}, {"name": "c", "exe": "/c"}]}
+			], "settings": {"check_interval_seconds": 0, "kill_grace_seconds": -1,
+				"quota_reset_time": "25:00", "auto_kill": false, "max_warnings": 0}}`,
+			errors: []string{
+				"$.blocked_applications[0].name",
+				"$.blocked_applications[0].processes",
+				"$.blocked_applications[1].processes[0]",
+				"$.blocked_applications[1].daily_quota_minutes",
+				"$.blocked_applications[2].match[0]",
+				"$.blocked_applications[2].match[1]",
+				"$.settings.check_interval_seconds",
+				"$.settings.kill_grace_seconds",
+				"$.settings.max_warnings",
+				"$.settings.quota_reset_time",
+			},
+		},
+		{
+			name: "zamanlama",
+			json: `{"blocked_applications": [
+				{"name": "A", "processes": ["a"], "schedule": {"timezone": "Mars/Olympus",
+					"windows": [{"start": "09:00", "end": "17:00"}, {"start": "9", "end": "17:00"}],
+					"exempt": [{"days": ["funday"], "start": "12:00", "end": "13:00"}]}},
+				{"name": "B", "processes": ["b"], "schedule": {}}
+			], ` + testAppSettings + `}`,
+			errors: []string{
+				"$.blocked_applications[0].schedule.exempt[0]",
+				"$.blocked_applications[0].schedule.timezone",
+				"$.blocked_applications[0].schedule.windows[1]",
+			},
+			warnings: []string{"$.blocked_applications[1].schedule"},
+		},
+		{
+			// Uyarılar config'i geçersiz kılmaz
+			name: "sadece uyarılar",
+			json: `{"blocked_applications": [
+				{"name": "A", "processes": ["Firefox.app", "/usr/bin/a"], "daily_quota_minutes": 1440, "colour": "red"},
+				{"name": "A", "processes": ["a"]}
+			], "settings": {"check_interval_seconds": 5, "max_warnings": 3, "kill_grace": 5}}`,
+			warnings: []string{
+				"$.blocked_applications[0].colour",
+				"$.blocked_applications[0].daily_quota_minutes",
+				"$.blocked_applications[0].processes[0]",
+				"$.blocked_applications[0].processes[1]",
+				"$.blocked_applications[1].name",
+				"$.settings.kill_grace",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		cfg, errs := parseAppBlockerConfig([]byte(tt.json))
+		assertValidation(t, tt.name, errs, tt.errors, tt.warnings)
+		if (cfg == nil) != (len(tt.errors) > 0) {
+			t.Errorf("%s: config %v, hata varken nil olmalı", tt.name, cfg)
+		}
+	}
+}
+
+const testWebsiteSettings = `"check_interval_seconds": 5, "website_blocker_enabled": true`
+
+func TestParseWebsiteBlockerConfigPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name: "geçerli",
+			json: `{"blocked_websites": [{"name": "YouTube", "urls": ["youtube.com", "*.ytimg.com"]}],
+				"settings": {` + testWebsiteSettings + `, "blocking_method": "dns_proxy"}}`,
+		},
+		{
+			// hosts dosyası joker desteklemez, desen sabit listeyle genişletilir
+			name: "hosts yönteminde joker",
+			json: `{"blocked_websites": [{"name": "YouTube", "urls": ["youtube.com", "*.ytimg.com"]}],
+				"settings": {` + testWebsiteSettings + `, "blocking_method": "hosts", "redirect_to": "0.0.0.0"}}`,
+			warnings: []string{"$.blocked_websites[0].urls[1]"},
+		},
+		{
+			name: "site hataları",
+			json: `{"blocked_websites": [
+				{"name": "", "urls": []},
+				{"name": "B", "urls": ["exa mple.com", "localhost", "b.com"], "subdomains": ["ok", "-bad"]},
+				{"name": "C", "urls": [5]}
+			], "settings": {` + testWebsiteSettings + `, "blocking_method": "hosts", "redirect_to": "0.0.0.0"}}`,
+			errors: []string{
+				"$.blocked_websites[0].name",
+				"$.blocked_websites[0].urls",
+				"$.blocked_websites[1].subdomains[1]",
+				"$.blocked_websites[1].urls[0]",
+				"$.blocked_websites[1].urls[1]",
+				"$.blocked_websites[2].urls[0]",
+			},
+		},
+		{
+			name: "ayar hataları",
+			json: `{"blocked_websites": [{"name": "A", "urls": ["a.com"]}],
+				"settings": {"check_interval_seconds": -1, "blocking_method": "firewall",
+				"redirect_to": "not-an-ip", "redirect_to_ipv6": "127.0.0.1", "block_page_port": 70000,
+				"subdomain_prefixes": ["www", "a_b"], "wildcard_subdomains": [""]}}`,
+			errors: []string{
+				"$.settings.block_page_port",
+				"$.settings.blocking_method",
+				"$.settings.check_interval_seconds",
+				"$.settings.redirect_to",
+				"$.settings.redirect_to_ipv6",
+				"$.settings.subdomain_prefixes[1]",
+				"$.settings.wildcard_subdomains[0]",
+			},
+		},
+		{
+			name: "hosts için redirect_to zorunlu",
+			json: `{"blocked_websites": [{"name": "A", "urls": ["a.com"]}],
+				"settings": {` + testWebsiteSettings + `, "blocking_method": "hosts"}}`,
+			errors: []string{"$.settings.redirect_to"},
+		},
+		{
+			name: "dns proxy",
+			json: `{"blocked_websites": [{"name": "A", "urls": ["a.com"]}],
+				"settings": {` + testWebsiteSettings + `, "blocking_method": "dns_proxy",
+				"dns_listen": "127.0.0.1:5353", "dns_upstreams": ["1.1.1.1", "bogus:dns:x", "127.0.0.1:5353"]}}`,
+			errors: []string{
+				"$.settings.dns_upstreams[1]",
+				"$.settings.dns_upstreams[2]",
+			},
+		},
+		{
+			name: "uyarılar",
+			json: `{"blocked_websites": [{"name": "A", "urls": ["a.com"], "note": "x"}],
+				"settings": {` + testWebsiteSettings + `, "blocking_method": "hosts", "redirect_to": "10.0.0.5",
+				"backup_hosts": true, "block_page": true}}`,
+			warnings: []string{
+				"$.blocked_websites[0].note",
+				"$.settings.backup_hosts",
+				"$.settings.block_page",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		cfg, errs := parseWebsiteBlockerConfig([]byte(tt.json))
+		assertValidation(t, tt.name, errs, tt.errors, tt.warnings)
+		if (cfg == nil) != (len(tt.errors) > 0) {
+			t.Errorf("%s: config %v, hata varken nil olmalı", tt.name, cfg)
+		}
+	}
+}
+
+func TestValidationSyntaxErrorPosition(t *testing.T) {
+	_, errs := parseAppBlockerConfig([]byte("{\n  \"settings\": {\n    \"check_interval_seconds\": 5,\n  }\n}"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "satır 4") {
+		t.Errorf("sözdizimi hatası konumu: %v", errs)
+	}
+	// Error() uyarıları içermez
+	errs = ValidationErrors{
+		{Path: "$.a", Message: "hata"},
+		{Path: "$.b", Message: "uyarı", Warning: true},
+	}
+	if got := errs.Error(); got != "$.a: hata" {
+		t.Errorf("Error() = %q", got)
+	}
+}
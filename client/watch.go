@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config dosyası izleme: Linux'ta inotify, diğer platformlarda (veya inotify
+// kullanılamazsa) değişiklik zamanı/boyut polling'i. Editörler dosyayı
+// genelde yeni dosya yazıp rename ederek kaydettiği için dosyanın kendisi
+// değil bulunduğu dizin izlenir.
+
+const (
+	configPollInterval   = 2 * time.Second
+	configReloadDebounce = 300 * time.Millisecond
+)
+
+// watchConfigFiles paths içindeki dosyalardan biri değiştiğinde onChange'i
+// çağırır. Art arda gelen olaylar birleştirilir. ctx iptal edilene kadar bloklar.
+func watchConfigFiles(ctx context.Context, paths []string, onChange func(path string)) {
+	abs := make(map[string]string, len(paths)) // mutlak yol -> verilen yol
+	for _, p := range paths {
+		if a, err := filepath.Abs(p); err == nil {
+			abs[a] = p
+		} else {
+			abs[p] = p
+		}
+	}
+
+	events, err := watchNative(ctx, abs)
+	if err != nil {
+		log.Printf("ℹ️ Dosya izleme kullanılamıyor (%v), polling ile kontrol edilecek", err)
+		events = pollFiles(ctx, abs)
+	}
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case path, ok := <-events:
+			if !ok {
+				return
+			}
+			pending[path] = true
+			timer.Reset(configReloadDebounce)
+
+		case <-timer.C:
+			for path := range pending {
+				onChange(abs[path])
+			}
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+// pollFiles dosyaların durumunu periyodik olarak karşılaştırır.
+func pollFiles(ctx context.Context, paths map[string]string) <-chan string {
+	events := make(chan string)
+
+	go func() {
+		defer close(events)
+
+		states := make(map[string]fileState, len(paths))
+		for path := range paths {
+			states[path] = statFile(path)
+		}
+
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for path, old := range states {
+				current := statFile(path)
+				if current == old {
+					continue
+				}
+				states[path] = current
+				select {
+				case events <- path:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM |
+	syscall.IN_CREATE | syscall.IN_DELETE
+
+// watchNative dosyaların bulunduğu dizinleri inotify ile izler ve değişen
+// dosyanın mutlak yolunu kanala yazar.
+func watchNative(ctx context.Context, paths map[string]string) (<-chan string, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// Non-blocking fd runtime poller'a kaydedilir, Close bekleyen Read'i uyandırır
+	file := os.NewFile(uintptr(fd), "inotify")
+
+	watches := make(map[int32]string) // watch descriptor -> dizin
+	for path := range paths {
+		dir := filepath.Dir(path)
+		wd, err := syscall.InotifyAddWatch(fd, dir, inotifyMask)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		watches[int32(wd)] = dir
+	}
+
+	events := make(chan string)
+	go func() {
+		<-ctx.Done()
+		file.Close()
+	}()
+
+	go func() {
+		defer close(events)
+
+		buf := make([]byte, 64*1024)
+		for {
+			n, err := file.Read(buf)
+			if err != nil {
+				return
+			}
+
+			for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+				event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				nameStart := offset + syscall.SizeofInotifyEvent
+				nameEnd := nameStart + int(event.Len)
+				if nameEnd > n {
+					break
+				}
+				offset = nameEnd
+
+				dir, ok := watches[event.Wd]
+				if !ok || event.Len == 0 {
+					continue
+				}
+				name := string(buf[nameStart:nameEnd])
+				for i := 0; i < len(name); i++ {
+					if name[i] == 0 {
+						name = name[:i]
+						break
+					}
+				}
+
+				path := filepath.Join(dir, name)
+				if _, watched := paths[path]; !watched {
+					continue
+				}
+				select {
+				case events <- path:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+func watchNative(ctx context.Context, paths map[string]string) (<-chan string, error) {
+	return nil, errors.New("bu platformda desteklenmiyor")
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Engellenen site adresleri kullanıcı girdisinden (tam URL, port, Unicode alan
+// adı) ASCII alan adına çevrilir ve genişletilir:
+//
+//	subdomain_prefixes:  her alan adına eklenen önekler (varsayılan www, m)
+//	subdomains:          sitenin kendi alt alan adı listesi
+//	"*.alan.com":        tüm alt alan adları; hosts dosyası joker desteklemediği
+//	                     için sabit wildcard_subdomains listesiyle genişletilir
+//	                     (DNS proxy yönteminde tüm alt alan adları eşleşir)
+//
+// Joker desenler uygulama anında DNS'ten çözülmez: DNS bir alan adının alt
+// alan adlarını listelemez ve hosts kaydı yazılan ad bir sonraki sorguda
+// zaten engellenmiş döner. Tüm alt alan adları sadece dns_proxy ile engellenir;
+// hosts yönteminde joker kullanılırsa config doğrulaması uyarı verir.
+
+var (
+	defaultSubdomainPrefixes  = []string{"www", "m"}
+	defaultWildcardSubdomains = []string{"www", "m", "mobile", "app", "web", "api", "login", "accounts", "static", "cdn", "media"}
+)
+
+const defaultRedirectIPv6 = "::"
+
+// blockedHost engellenecek bir alan adı. Wildcard ise tüm alt alan adları da engellenir.
+type blockedHost struct {
+	Host     string
+	Wildcard bool
+	Site     string
+}
+
+// normalizeHost "https://YouTube.com:443/watch?v=1" gibi bir girdiyi
+// "youtube.com" haline getirir; Unicode alan adları punycode'a çevrilir.
+// "*." ile başlayan desenlerde wildcard true döner.
+func normalizeHost(raw string) (host string, wildcard bool, err error) {
+	s := strings.TrimSpace(raw)
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", false, fmt.Errorf("%q geçerli bir URL değil", raw)
+		}
+		s = u.Host
+	} else if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.LastIndexByte(s, '@'); i >= 0 {
+		s = s[i+1:]
+	}
+	if h, _, err := net.SplitHostPort(s); err == nil {
+		s = h
+	}
+	s = strings.TrimSuffix(strings.ToLower(s), ".")
+
+	if strings.HasPrefix(s, "*.") {
+		wildcard = true
+		s = s[2:]
+	}
+	if s == "" {
+		return "", false, fmt.Errorf("%q içinde alan adı yok", raw)
+	}
+	if net.ParseIP(strings.Trim(s, "[]")) != nil {
+		return "", false, fmt.Errorf("%q bir IP adresi, sadece alan adları engellenebilir", raw)
+	}
+
+	ascii, err := idna.Lookup.ToASCII(s)
+	if err != nil {
+		return "", false, fmt.Errorf("%q geçerli bir alan adı değil: %v", raw, err)
+	}
+	if !strings.Contains(ascii, ".") {
+		return "", false, fmt.Errorf("%q tam bir alan adı değil (ör. ornek.com)", raw)
+	}
+	return ascii, wildcard, nil
+}
+
+// validLabel alt alan adı veya önek olarak kullanılabilecek bir ad mı
+// ("music", "studio.music" gibi).
+func validLabel(label string) error {
+	if label == "" || strings.HasPrefix(label, ".") || strings.HasSuffix(label, ".") {
+		return errors.New("boş etiket")
+	}
+	_, err := idna.Lookup.ToASCII(label + ".example.com")
+	return err
+}
+
+func (cfg *WebsiteBlockerConfig) subdomainPrefixes() []string {
+	if cfg.Settings.SubdomainPrefixes == nil {
+		return defaultSubdomainPrefixes
+	}
+	return cfg.Settings.SubdomainPrefixes
+}
+
+func (cfg *WebsiteBlockerConfig) wildcardSubdomains() []string {
+	if cfg.Settings.WildcardSubdomains == nil {
+		return defaultWildcardSubdomains
+	}
+	return cfg.Settings.WildcardSubdomains
+}
+
+// domains sitenin normalize edilmiş alan adlarını (genişletmeden) döner;
+// geçersiz adresler atlanır (validate.go bunları hata olarak raporlar).
+func (site BlockedWebsite) domains() []string {
+	var domains []string
+	for _, u := range site.URLs {
+		if host, _, err := normalizeHost(u); err == nil {
+			domains = append(domains, host)
+		}
+	}
+	return domains
+}
+
+// blockedHosts config'deki siteleri normalize edip öneklerle ve alt alan
+// adlarıyla genişletir. Her alan adı bir kez döner (ilk site adıyla).
+func (cfg *WebsiteBlockerConfig) blockedHosts() []blockedHost {
+	prefixes := cfg.subdomainPrefixes()
+	seen := make(map[string]bool)
+	var hosts []blockedHost
+	add := func(h blockedHost) {
+		key := h.Host
+		if h.Wildcard {
+			key = "*." + key
+		}
+		if !seen[key] {
+			seen[key] = true
+			hosts = append(hosts, h)
+		}
+	}
+
+	for _, site := range cfg.BlockedWebsites {
+		for _, u := range site.URLs {
+			host, wildcard, err := normalizeHost(u)
+			if err != nil {
+				continue
+			}
+			// "www.youtube.com" girildiyse youtube.com ve diğer önekler de engellenir.
+			// Sadece www atılır: "m.example.com" girildiğinde example.com engellenmemeli.
+			if rest := strings.TrimPrefix(host, "www."); rest != host && strings.Contains(rest, ".") {
+				host = rest
+			}
+
+			add(blockedHost{Host: host, Wildcard: wildcard, Site: site.Name})
+			if wildcard {
+				continue // önekler ve alt alan adları desenin kapsamında
+			}
+			for _, p := range prefixes {
+				add(blockedHost{Host: p + "." + host, Site: site.Name})
+			}
+			for _, sub := range site.Subdomains {
+				if validLabel(sub) == nil {
+					add(blockedHost{Host: strings.ToLower(sub) + "." + host, Site: site.Name})
+				}
+			}
+		}
+	}
+	return hosts
+}
+
+// hostsEntries hosts dosyasına yazılacak kayıtları oluşturur. Joker desenler
+// alan adının kendisi ve wildcard_subdomains ile genişletilir; her ad için
+// IPv4 ve IPv6 (varsayılan "::") kaydı yazılır.
+func (cfg *WebsiteBlockerConfig) hostsEntries() []hostsEntry {
+	ips := []string{cfg.Settings.RedirectTo}
+	if ip := net.ParseIP(cfg.Settings.RedirectTo); ip != nil && ip.To4() != nil {
+		v6 := cfg.Settings.RedirectToIPv6
+		if v6 == "" {
+			v6 = defaultRedirectIPv6
+		}
+		ips = append(ips, v6)
+	}
+
+	seen := make(map[string]bool)
+	var names []blockedHost
+	for _, h := range cfg.blockedHosts() {
+		expanded := []string{h.Host}
+		if h.Wildcard {
+			for _, sub := range cfg.wildcardSubdomains() {
+				expanded = append(expanded, strings.ToLower(sub)+"."+h.Host)
+			}
+		}
+		for _, name := range expanded {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, blockedHost{Host: name, Site: h.Site})
+			}
+		}
+	}
+
+	var entries []hostsEntry
+	for _, h := range names {
+		for _, ip := range ips {
+			entries = append(entries, hostsEntry{IP: ip, Host: h.Host, Site: h.Site})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,583 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimal X11 protokol istemcisi. Sadece ekran yakalama ve pencere
+// sorguları için gereken istekleri destekler; Xlib/cgo gerektirmez.
+
+const (
+	x11OpGetWindowAttributes  = 3
+	x11OpGetGeometry          = 14
+	x11OpInternAtom           = 16
+	x11OpGetProperty          = 20
+	x11OpTranslateCoordinates = 40
+	x11OpGetInputFocus        = 43
+	x11OpGetImage             = 73
+	x11OpQueryExtension       = 98
+
+	x11ImageFormatZPixmap = 2
+	x11AllPlanes          = 0xffffffff
+)
+
+var x11Order = binary.LittleEndian
+
+type x11Format struct {
+	depth        byte
+	bitsPerPixel byte
+	scanlinePad  byte
+}
+
+type x11Visual struct {
+	id        uint32
+	class     byte
+	redMask   uint32
+	greenMask uint32
+	blueMask  uint32
+}
+
+type x11Screen struct {
+	root       uint32
+	width      uint16
+	height     uint16
+	rootVisual uint32
+	rootDepth  byte
+	visuals    map[uint32]x11Visual
+}
+
+type x11Conn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+	mu   sync.Mutex
+	seq  uint16
+
+	imageByteOrder byte
+	formats        map[byte]x11Format
+	screens        []x11Screen
+	screen         int
+
+	ridBase uint32
+	ridMask uint32
+	ridNext uint32
+
+	// Cevapsız isteklerden gelen son hata
+	pendingErr error
+}
+
+type x11Error struct {
+	code     byte
+	seq      uint16
+	opcode   byte
+	badValue uint32
+}
+
+func (e *x11Error) Error() string {
+	names := map[byte]string{
+		1: "BadRequest", 2: "BadValue", 3: "BadWindow", 4: "BadPixmap",
+		5: "BadAtom", 8: "BadMatch", 9: "BadDrawable", 10: "BadAccess",
+		11: "BadAlloc", 14: "BadIDChoice", 16: "BadLength", 17: "BadImplementation",
+	}
+	name, ok := names[e.code]
+	if !ok {
+		name = fmt.Sprintf("hata %d", e.code)
+	}
+	return fmt.Sprintf("X11 %s (opcode %d, değer 0x%x)", name, e.opcode, e.badValue)
+}
+
+type x11Geometry struct {
+	depth  byte
+	root   uint32
+	x      int16
+	y      int16
+	width  uint16
+	height uint16
+}
+
+// parseDisplay "[host]:display[.screen]" formatındaki DISPLAY değerini çözer.
+func parseDisplay(display string) (network, address, host string, displayNum, screenNum int, err error) {
+	idx := strings.LastIndex(display, ":")
+	if idx < 0 {
+		return "", "", "", 0, 0, fmt.Errorf("geçersiz DISPLAY: %q", display)
+	}
+	host = display[:idx]
+	rest := display[idx+1:]
+
+	screenStr := "0"
+	if dot := strings.Index(rest, "."); dot >= 0 {
+		screenStr = rest[dot+1:]
+		rest = rest[:dot]
+	}
+	if displayNum, err = strconv.Atoi(rest); err != nil {
+		return "", "", "", 0, 0, fmt.Errorf("geçersiz DISPLAY numarası: %q", display)
+	}
+	if screenNum, err = strconv.Atoi(screenStr); err != nil {
+		return "", "", "", 0, 0, fmt.Errorf("geçersiz DISPLAY ekranı: %q", display)
+	}
+
+	if host == "" || host == "unix" || strings.HasPrefix(host, "/") {
+		socket := fmt.Sprintf("/tmp/.X11-unix/X%d", displayNum)
+		if strings.HasPrefix(host, "/") {
+			// launchd tarzı tam soket yolu
+			socket = display[:idx]
+			host = ""
+		}
+		return "unix", socket, "", displayNum, screenNum, nil
+	}
+	return "tcp", net.JoinHostPort(host, strconv.Itoa(6000+displayNum)), host, displayNum, screenNum, nil
+}
+
+func dialX11(display string) (*x11Conn, error) {
+	if display == "" {
+		display = os.Getenv("DISPLAY")
+	}
+	if display == "" {
+		return nil, errors.New("DISPLAY tanımlı değil")
+	}
+
+	network, address, host, displayNum, screenNum, err := parseDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout(network, address, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("X sunucusuna bağlanılamadı (%s): %v", display, err)
+	}
+
+	x := &x11Conn{
+		conn:   conn,
+		rd:     bufio.NewReaderSize(conn, 64*1024),
+		screen: screenNum,
+	}
+
+	authName, authData := readXauthority(network, host, displayNum)
+	if err := x.handshake(authName, authData); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if screenNum >= len(x.screens) {
+		conn.Close()
+		return nil, fmt.Errorf("X ekranı bulunamadı: %d", screenNum)
+	}
+
+	return x, nil
+}
+
+// readXauthority MIT-MAGIC-COOKIE-1 bilgisini Xauthority dosyasından okur.
+func readXauthority(network, host string, displayNum int) (string, []byte) {
+	path := os.Getenv("XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		path = filepath.Join(home, ".Xauthority")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil
+	}
+	defer f.Close()
+
+	hostname, _ := os.Hostname()
+	if network == "tcp" && host != "localhost" && host != "127.0.0.1" {
+		hostname = host
+	}
+	number := strconv.Itoa(displayNum)
+	rd := bufio.NewReader(f)
+
+	readField := func() ([]byte, error) {
+		var n uint16
+		if err := binary.Read(rd, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, n)
+		_, err := io.ReadFull(rd, buf)
+		return buf, err
+	}
+
+	for {
+		var family uint16
+		if err := binary.Read(rd, binary.BigEndian, &family); err != nil {
+			return "", nil
+		}
+		addr, err := readField()
+		if err != nil {
+			return "", nil
+		}
+		num, err := readField()
+		if err != nil {
+			return "", nil
+		}
+		name, err := readField()
+		if err != nil {
+			return "", nil
+		}
+		data, err := readField()
+		if err != nil {
+			return "", nil
+		}
+
+		const familyLocal, familyWild = 256, 65535
+		addrMatch := family == familyWild || string(addr) == hostname ||
+			(family == familyLocal && network == "unix")
+		numMatch := len(num) == 0 || string(num) == number
+		if addrMatch && numMatch && string(name) == "MIT-MAGIC-COOKIE-1" {
+			return string(name), data
+		}
+	}
+}
+
+func x11Pad(n int) int {
+	return (4 - n%4) % 4
+}
+
+func (x *x11Conn) handshake(authName string, authData []byte) error {
+	req := make([]byte, 12, 12+len(authName)+len(authData)+8)
+	req[0] = 'l' // little endian
+	x11Order.PutUint16(req[2:], 11)
+	x11Order.PutUint16(req[4:], 0)
+	x11Order.PutUint16(req[6:], uint16(len(authName)))
+	x11Order.PutUint16(req[8:], uint16(len(authData)))
+	req = append(req, authName...)
+	req = append(req, make([]byte, x11Pad(len(authName)))...)
+	req = append(req, authData...)
+	req = append(req, make([]byte, x11Pad(len(authData)))...)
+
+	if _, err := x.conn.Write(req); err != nil {
+		return err
+	}
+
+	head := make([]byte, 8)
+	if _, err := io.ReadFull(x.rd, head); err != nil {
+		return fmt.Errorf("X11 setup cevabı okunamadı: %v", err)
+	}
+	body := make([]byte, int(x11Order.Uint16(head[6:]))*4)
+	if _, err := io.ReadFull(x.rd, body); err != nil {
+		return fmt.Errorf("X11 setup cevabı okunamadı: %v", err)
+	}
+
+	switch head[0] {
+	case 0:
+		reasonLen := int(head[1])
+		if reasonLen > len(body) {
+			reasonLen = len(body)
+		}
+		return fmt.Errorf("X sunucusu bağlantıyı reddetti: %s", string(body[:reasonLen]))
+	case 2:
+		return errors.New("X sunucusu ek kimlik doğrulaması istiyor")
+	case 1:
+		return x.parseSetup(body)
+	default:
+		return fmt.Errorf("beklenmeyen X11 setup durumu: %d", head[0])
+	}
+}
+
+func (x *x11Conn) parseSetup(b []byte) error {
+	if len(b) < 32 {
+		return errors.New("X11 setup cevabı çok kısa")
+	}
+	x.ridBase = x11Order.Uint32(b[4:])
+	x.ridMask = x11Order.Uint32(b[8:])
+	vendorLen := int(x11Order.Uint16(b[16:]))
+	numScreens := int(b[20])
+	numFormats := int(b[21])
+	x.imageByteOrder = b[22]
+
+	off := 32 + vendorLen + x11Pad(vendorLen)
+	x.formats = make(map[byte]x11Format, numFormats)
+	for i := 0; i < numFormats; i++ {
+		if off+8 > len(b) {
+			return errors.New("X11 setup formatları okunamadı")
+		}
+		f := x11Format{depth: b[off], bitsPerPixel: b[off+1], scanlinePad: b[off+2]}
+		x.formats[f.depth] = f
+		off += 8
+	}
+
+	for i := 0; i < numScreens; i++ {
+		if off+40 > len(b) {
+			return errors.New("X11 setup ekranları okunamadı")
+		}
+		s := x11Screen{
+			root:       x11Order.Uint32(b[off:]),
+			width:      x11Order.Uint16(b[off+20:]),
+			height:     x11Order.Uint16(b[off+22:]),
+			rootVisual: x11Order.Uint32(b[off+32:]),
+			rootDepth:  b[off+38],
+			visuals:    make(map[uint32]x11Visual),
+		}
+		numDepths := int(b[off+39])
+		off += 40
+
+		for d := 0; d < numDepths; d++ {
+			if off+8 > len(b) {
+				return errors.New("X11 setup derinlikleri okunamadı")
+			}
+			numVisuals := int(x11Order.Uint16(b[off+2:]))
+			off += 8
+			for v := 0; v < numVisuals; v++ {
+				if off+24 > len(b) {
+					return errors.New("X11 setup visual'ları okunamadı")
+				}
+				vis := x11Visual{
+					id:        x11Order.Uint32(b[off:]),
+					class:     b[off+4],
+					redMask:   x11Order.Uint32(b[off+8:]),
+					greenMask: x11Order.Uint32(b[off+12:]),
+					blueMask:  x11Order.Uint32(b[off+16:]),
+				}
+				s.visuals[vis.id] = vis
+				off += 24
+			}
+		}
+		x.screens = append(x.screens, s)
+	}
+	return nil
+}
+
+func (x *x11Conn) Close() error {
+	return x.conn.Close()
+}
+
+func (x *x11Conn) defaultScreen() x11Screen {
+	return x.screens[x.screen]
+}
+
+func (x *x11Conn) newID() uint32 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.ridNext++
+	return x.ridBase | (x.ridNext & x.ridMask)
+}
+
+// readPacket sunucudan bir reply, error veya event paketi okur.
+func (x *x11Conn) readPacket() ([]byte, error) {
+	head := make([]byte, 32)
+	if _, err := io.ReadFull(x.rd, head); err != nil {
+		return nil, err
+	}
+	if head[0] != 1 {
+		return head, nil
+	}
+	extra := int(x11Order.Uint32(head[4:])) * 4
+	if extra == 0 {
+		return head, nil
+	}
+	pkt := make([]byte, 32+extra)
+	copy(pkt, head)
+	if _, err := io.ReadFull(x.rd, pkt[32:]); err != nil {
+		return nil, err
+	}
+	return pkt, nil
+}
+
+func (x *x11Conn) writeLocked(req []byte, oob []byte) error {
+	if len(oob) > 0 {
+		uc, ok := x.conn.(*net.UnixConn)
+		if !ok {
+			return errors.New("dosya tanımlayıcısı sadece Unix soket üzerinden gönderilebilir")
+		}
+		if _, _, err := uc.WriteMsgUnix(req, oob, nil); err != nil {
+			return err
+		}
+	} else if _, err := x.conn.Write(req); err != nil {
+		return err
+	}
+	x.seq++
+	return nil
+}
+
+// waitLocked verilen sequence numarasının cevabını bekler.
+func (x *x11Conn) waitLocked(seq uint16) ([]byte, error) {
+	for {
+		pkt, err := x.readPacket()
+		if err != nil {
+			return nil, err
+		}
+		pktSeq := x11Order.Uint16(pkt[2:])
+		switch pkt[0] {
+		case 0:
+			xerr := &x11Error{
+				code:     pkt[1],
+				seq:      pktSeq,
+				badValue: x11Order.Uint32(pkt[4:]),
+				opcode:   pkt[10],
+			}
+			if pktSeq == seq {
+				return nil, xerr
+			}
+			x.pendingErr = xerr
+		case 1:
+			if pktSeq == seq {
+				return pkt, nil
+			}
+		default:
+			// Event'leri dinlemiyoruz, yok say
+		}
+	}
+}
+
+func (x *x11Conn) roundTrip(req []byte) ([]byte, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.writeLocked(req, nil); err != nil {
+		return nil, err
+	}
+	return x.waitLocked(x.seq)
+}
+
+// sendVoid cevabı olmayan bir isteği gönderir ve sunucuyla senkronize olup
+// isteğin hata üretip üretmediğini kontrol eder.
+func (x *x11Conn) sendVoid(req []byte, oob []byte) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.pendingErr = nil
+	if err := x.writeLocked(req, oob); err != nil {
+		return err
+	}
+	if err := x.writeLocked(x11Request(x11OpGetInputFocus, 0, nil), nil); err != nil {
+		return err
+	}
+	if _, err := x.waitLocked(x.seq); err != nil {
+		return err
+	}
+	return x.pendingErr
+}
+
+// x11Request 4 byte'lık başlık ile bir istek paketi oluşturur.
+func x11Request(opcode, data byte, body []byte) []byte {
+	n := 4 + len(body)
+	n += x11Pad(n)
+	req := make([]byte, n)
+	req[0] = opcode
+	req[1] = data
+	x11Order.PutUint16(req[2:], uint16(n/4))
+	copy(req[4:], body)
+	return req
+}
+
+func (x *x11Conn) queryExtension(name string) (present bool, majorOpcode byte, err error) {
+	body := make([]byte, 4+len(name))
+	x11Order.PutUint16(body[0:], uint16(len(name)))
+	copy(body[4:], name)
+
+	reply, err := x.roundTrip(x11Request(x11OpQueryExtension, 0, body))
+	if err != nil {
+		return false, 0, err
+	}
+	return reply[8] == 1, reply[9], nil
+}
+
+func (x *x11Conn) getGeometry(drawable uint32) (x11Geometry, error) {
+	body := make([]byte, 4)
+	x11Order.PutUint32(body, drawable)
+
+	reply, err := x.roundTrip(x11Request(x11OpGetGeometry, 0, body))
+	if err != nil {
+		return x11Geometry{}, err
+	}
+	return x11Geometry{
+		depth:  reply[1],
+		root:   x11Order.Uint32(reply[8:]),
+		x:      int16(x11Order.Uint16(reply[12:])),
+		y:      int16(x11Order.Uint16(reply[14:])),
+		width:  x11Order.Uint16(reply[16:]),
+		height: x11Order.Uint16(reply[18:]),
+	}, nil
+}
+
+// getImage ZPixmap formatında bir drawable alanını okur.
+func (x *x11Conn) getImage(drawable uint32, px, py int16, width, height uint16) (depth byte, visual uint32, data []byte, err error) {
+	body := make([]byte, 16)
+	x11Order.PutUint32(body[0:], drawable)
+	x11Order.PutUint16(body[4:], uint16(px))
+	x11Order.PutUint16(body[6:], uint16(py))
+	x11Order.PutUint16(body[8:], width)
+	x11Order.PutUint16(body[10:], height)
+	x11Order.PutUint32(body[12:], x11AllPlanes)
+
+	reply, err := x.roundTrip(x11Request(x11OpGetImage, x11ImageFormatZPixmap, body))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return reply[1], x11Order.Uint32(reply[8:]), reply[32:], nil
+}
+
+// internAtom atom adının numarasını döner; atom yoksa 0 döner (oluşturmaz).
+func (x *x11Conn) internAtom(name string) (uint32, error) {
+	body := make([]byte, 4+len(name))
+	x11Order.PutUint16(body[0:], uint16(len(name)))
+	copy(body[4:], name)
+
+	reply, err := x.roundTrip(x11Request(x11OpInternAtom, 1, body))
+	if err != nil {
+		return 0, err
+	}
+	return x11Order.Uint32(reply[8:]), nil
+}
+
+// getProperty pencere özelliğini (en fazla maxLen*4 byte) okur. Özellik
+// yoksa typ 0 ve value boş döner.
+func (x *x11Conn) getProperty(window, property, propType uint32, maxLen uint32) (typ uint32, format byte, value []byte, err error) {
+	body := make([]byte, 20)
+	x11Order.PutUint32(body[0:], window)
+	x11Order.PutUint32(body[4:], property)
+	x11Order.PutUint32(body[8:], propType)
+	x11Order.PutUint32(body[12:], 0)
+	x11Order.PutUint32(body[16:], maxLen)
+
+	reply, err := x.roundTrip(x11Request(x11OpGetProperty, 0, body))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	format = reply[1]
+	typ = x11Order.Uint32(reply[8:])
+	n := int(x11Order.Uint32(reply[16:])) * int(format) / 8
+	if 32+n > len(reply) {
+		return 0, 0, nil, errors.New("X11 özellik cevabı eksik")
+	}
+	return typ, format, reply[32 : 32+n], nil
+}
+
+// translateCoordinates pencere içindeki bir noktanın dst penceresindeki karşılığını döner.
+func (x *x11Conn) translateCoordinates(src, dst uint32, px, py int16) (int16, int16, error) {
+	body := make([]byte, 12)
+	x11Order.PutUint32(body[0:], src)
+	x11Order.PutUint32(body[4:], dst)
+	x11Order.PutUint16(body[8:], uint16(px))
+	x11Order.PutUint16(body[10:], uint16(py))
+
+	reply, err := x.roundTrip(x11Request(x11OpTranslateCoordinates, 0, body))
+	if err != nil {
+		return 0, 0, err
+	}
+	return int16(x11Order.Uint16(reply[12:])), int16(x11Order.Uint16(reply[14:])), nil
+}
+
+// windowViewable pencerenin şu an ekranda görünür (map edilmiş) olup olmadığını döner.
+func (x *x11Conn) windowViewable(window uint32) (bool, error) {
+	body := make([]byte, 4)
+	x11Order.PutUint32(body, window)
+
+	reply, err := x.roundTrip(x11Request(x11OpGetWindowAttributes, 0, body))
+	if err != nil {
+		return false, err
+	}
+	return reply[26] == 2, nil
+}
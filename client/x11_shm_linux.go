@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// MIT-SHM eklentisi ile ekran görüntüsü paylaşımlı bellek üzerinden alınır,
+// böylece her karede piksel verisi sokete yazılıp okunmaz. Segment,
+// ShmAttachFd (MIT-SHM 1.2) ile bir dosya tanımlayıcısı gönderilerek bağlanır.
+
+const (
+	shmQueryVersion = 0
+	shmDetach       = 2
+	shmGetImage     = 4
+	shmAttachFd     = 6
+)
+
+type x11Shm struct {
+	x     *x11Conn
+	major byte
+	seg   uint32
+	file  *os.File
+	buf   []byte
+}
+
+func (x *x11Conn) newShm(size int) (*x11Shm, error) {
+	if _, ok := x.conn.(*net.UnixConn); !ok {
+		return nil, errors.New("MIT-SHM sadece yerel bağlantılarda kullanılabilir")
+	}
+
+	present, major, err := x.queryExtension("MIT-SHM")
+	if err != nil {
+		return nil, err
+	}
+	if !present {
+		return nil, errors.New("X sunucusu MIT-SHM desteklemiyor")
+	}
+
+	reply, err := x.roundTrip(x11Request(major, shmQueryVersion, nil))
+	if err != nil {
+		return nil, err
+	}
+	majorVer, minorVer := x11Order.Uint16(reply[8:]), x11Order.Uint16(reply[10:])
+	if majorVer < 1 || (majorVer == 1 && minorVer < 2) {
+		return nil, fmt.Errorf("MIT-SHM %d.%d çok eski (1.2 gerekli)", majorVer, minorVer)
+	}
+
+	file, buf, err := createShmBuffer(size)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &x11Shm{x: x, major: major, seg: x.newID(), file: file, buf: buf}
+
+	body := make([]byte, 8)
+	x11Order.PutUint32(body[0:], s.seg)
+	body[4] = 0 // read-only değil, sunucu yazacak
+	if err := x.sendVoid(x11Request(major, shmAttachFd, body), syscall.UnixRights(int(file.Fd()))); err != nil {
+		s.release()
+		return nil, fmt.Errorf("MIT-SHM segmenti bağlanamadı: %v", err)
+	}
+
+	return s, nil
+}
+
+// createShmBuffer silinmiş bir geçici dosyayı bellek eşlemesi ile açar.
+func createShmBuffer(size int) (*os.File, []byte, error) {
+	dir := "/dev/shm"
+	if _, err := os.Stat(dir); err != nil {
+		dir = os.TempDir()
+	}
+
+	file, err := os.CreateTemp(dir, "screenrecord-shm-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	os.Remove(file.Name())
+
+	if err := file.Truncate(int64(size)); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	buf, err := syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, buf, nil
+}
+
+func (s *x11Shm) size() int {
+	return len(s.buf)
+}
+
+// getImage görüntüyü paylaşımlı belleğe alır. Dönen dilim bir sonraki
+// çağrıya kadar geçerlidir.
+func (s *x11Shm) getImage(drawable uint32, px, py int16, width, height uint16) (depth byte, visual uint32, data []byte, err error) {
+	body := make([]byte, 28)
+	x11Order.PutUint32(body[0:], drawable)
+	x11Order.PutUint16(body[4:], uint16(px))
+	x11Order.PutUint16(body[6:], uint16(py))
+	x11Order.PutUint16(body[8:], width)
+	x11Order.PutUint16(body[10:], height)
+	x11Order.PutUint32(body[12:], x11AllPlanes)
+	body[16] = x11ImageFormatZPixmap
+	x11Order.PutUint32(body[20:], s.seg)
+	x11Order.PutUint32(body[24:], 0)
+
+	reply, err := s.x.roundTrip(x11Request(s.major, shmGetImage, body))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	n := int(x11Order.Uint32(reply[12:]))
+	if n > len(s.buf) {
+		return 0, 0, nil, fmt.Errorf("MIT-SHM görüntüsü tampondan büyük: %d > %d", n, len(s.buf))
+	}
+	return reply[1], x11Order.Uint32(reply[8:]), s.buf[:n], nil
+}
+
+func (s *x11Shm) Close() error {
+	body := make([]byte, 4)
+	x11Order.PutUint32(body, s.seg)
+	err := s.x.sendVoid(x11Request(s.major, shmDetach, body), nil)
+	s.release()
+	return err
+}
+
+func (s *x11Shm) release() {
+	if s.buf != nil {
+		syscall.Munmap(s.buf)
+		s.buf = nil
+	}
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+}